@@ -0,0 +1,124 @@
+package app
+
+import (
+	"context"
+	"housy/config"
+	"housy/pkg/database"
+	"housy/pkg/logging"
+	"housy/pkg/mail"
+	"housy/pkg/payment"
+	"housy/pkg/search"
+	"housy/pkg/storage"
+	"log/slog"
+	"os"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// App holds everything the HTTP API depends on. New builds the production
+// dependencies from the config, tests can fill the struct with fakes. Tokens
+// are signed with Config.SecretKey.
+type App struct {
+	Config  config.Config
+	DB      *gorm.DB
+	Mailer  mail.Mailer
+	Payment payment.Gateway
+	Storage storage.Storage
+	Search  search.Index
+	Logger  *slog.Logger
+
+	mu      sync.Mutex
+	ctx     context.Context
+	cancel  context.CancelFunc
+	workers sync.WaitGroup
+}
+
+func New(cfg config.Config) (*App, error) {
+	db, err := database.Open(cfg.DatabaseConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	return &App{
+		Config:  cfg,
+		DB:      db,
+		Mailer:  mail.NewSMTPMailer(cfg.MailConfig()),
+		Payment: payment.NewMidtrans(cfg.PaymentConfig()),
+		Storage: storage.NewLocal(cfg.Storage.Dir, cfg.Storage.BaseURL),
+		Logger:  logging.New(os.Stdout, cfg.LoggingConfig()),
+	}, nil
+}
+
+// Go runs a background worker. Its context is cancelled by StopWorkers, the
+// worker should then finish what it is doing and return. Workers aren't
+// started anymore once StopWorkers was called.
+func (a *App) Go(worker func(ctx context.Context)) {
+	ctx := a.context()
+	if ctx.Err() != nil {
+		return
+	}
+
+	a.workers.Add(1)
+	go func() {
+		defer a.workers.Done()
+		worker(ctx)
+	}()
+}
+
+// StopWorkers cancels the background workers and waits for them to return,
+// or for ctx to be done.
+func (a *App) StopWorkers(ctx context.Context) error {
+	a.context()
+	a.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		a.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (a *App) context() context.Context {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.ctx == nil {
+		a.ctx, a.cancel = context.WithCancel(context.Background())
+	}
+	return a.ctx
+}
+
+// OpenSearch opens the search index. Only the server needs it, and the
+// index can't be opened by two processes at once, so New leaves it closed.
+func (a *App) OpenSearch() error {
+	index, err := search.Open(a.Config.Search.Dir)
+	if err != nil {
+		return err
+	}
+
+	a.Search = index
+	return nil
+}
+
+// Close releases the database connections and the search index.
+func (a *App) Close() error {
+	if a.Search != nil {
+		if err := a.Search.Close(); err != nil {
+			return err
+		}
+	}
+
+	sqlDB, err := a.DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	amenitydto "housy/dto/amenity"
+)
+
+// FindAmenities returns the amenities catalog, the codes houses and house
+// filters use.
+func (c *Client) FindAmenities(ctx context.Context) ([]amenitydto.AmenityResponse, error) {
+	var amenities []amenitydto.AmenityResponse
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/amenities"}, &amenities)
+	return amenities, err
+}
+
+func (c *Client) CreateAmenity(ctx context.Context, amenity amenitydto.AmenityRequest) (amenitydto.AmenityResponse, error) {
+	return c.sendAmenity(ctx, http.MethodPost, "/admin/amenity", amenity)
+}
+
+// UpdateAmenity changes the non empty fields of amenity.
+func (c *Client) UpdateAmenity(ctx context.Context, id int, amenity amenitydto.UpdateAmenityRequest) (amenitydto.AmenityResponse, error) {
+	return c.sendAmenity(ctx, http.MethodPatch, fmt.Sprintf("/admin/amenity/%d", id), amenity)
+}
+
+// DeleteAmenity removes an amenity no house has from the catalog.
+func (c *Client) DeleteAmenity(ctx context.Context, id int) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: fmt.Sprintf("/admin/amenity/%d", id), auth: true}, nil)
+	return err
+}
+
+func (c *Client) sendAmenity(ctx context.Context, method, path string, body interface{}) (amenitydto.AmenityResponse, error) {
+	var amenity amenitydto.AmenityResponse
+
+	req, err := jsonRequest(method, path, body)
+	if err != nil {
+		return amenity, err
+	}
+	req.auth = true
+	_, err = c.do(ctx, req, &amenity)
+	return amenity, err
+}
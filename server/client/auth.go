@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	authdto "housy/dto/auth"
+)
+
+func (c *Client) SignUp(ctx context.Context, request authdto.SignUpRequest) (authdto.SignUpResponse, error) {
+	var user authdto.SignUpResponse
+
+	req, err := jsonRequest(http.MethodPost, "/sign-up", request)
+	if err != nil {
+		return user, err
+	}
+	_, err = c.do(ctx, req, &user)
+	return user, err
+}
+
+// SignIn gets a token and uses it for the following requests.
+func (c *Client) SignIn(ctx context.Context, request authdto.SignInRequest) (authdto.SignInResponse, error) {
+	var user authdto.SignInResponse
+
+	req, err := jsonRequest(http.MethodPost, "/sign-in", request)
+	if err != nil {
+		return user, err
+	}
+	if _, err := c.do(ctx, req, &user); err != nil {
+		return user, err
+	}
+
+	c.setToken(user.Token)
+	return user, nil
+}
+
+// CheckAuth describes the user the token belongs to.
+func (c *Client) CheckAuth(ctx context.Context) (authdto.CheckAuthResponse, error) {
+	var user authdto.CheckAuthResponse
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/check-auth", auth: true}, &user)
+	return user, err
+}
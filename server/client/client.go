@@ -0,0 +1,270 @@
+// Package client is a typed Go client of the Housy API. It reuses the DTOs
+// and models of the server, signs in and refreshes its token on its own when
+// given credentials, and turns error responses into *Error values.
+//
+//	c := client.New("https://housy.example.com", client.WithCredentials("admin", "secret", "admin"))
+//	houses := c.FindHouses(ctx, client.HouseFilter{CityName: "Jakarta"})
+//	for houses.Next() {
+//		fmt.Println(houses.Value().Name)
+//	}
+//	if err := houses.Err(); err != nil {
+//		...
+//	}
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	authdto "housy/dto/auth"
+	dto "housy/dto/result"
+)
+
+// BasePath is where the API is mounted on the server.
+const BasePath = "/api/v1"
+
+// refreshMargin is how long before its expiry a token is replaced, so it
+// doesn't expire while a request is on the way.
+const refreshMargin = time.Minute
+
+type Client struct {
+	baseURL     string
+	http        *http.Client
+	credentials *authdto.SignInRequest
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+type Option func(c *Client)
+
+// WithHTTPClient replaces http.DefaultClient, e.g. to set a timeout.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.http = httpClient
+	}
+}
+
+// WithToken authenticates requests with a token obtained elsewhere.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.setToken(token)
+	}
+}
+
+// WithCredentials signs in before the first authenticated request, and
+// again whenever the token is about to expire or is rejected.
+func WithCredentials(username, password, listAsRole string) Option {
+	return func(c *Client) {
+		c.credentials = &authdto.SignInRequest{Username: username, Password: password, ListAsRole: listAsRole}
+	}
+}
+
+// New returns a client of the server at baseURL, e.g. "http://localhost:5000".
+func New(baseURL string, options ...Option) *Client {
+	c := &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/") + BasePath,
+		http:    http.DefaultClient,
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// Token returns the current token, "" before signing in.
+func (c *Client) Token() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.token
+}
+
+func (c *Client) setToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.token = token
+	c.expiresAt = tokenExpiry(token)
+}
+
+// Error is a response with an error status, decoded from the ErrorResult
+// envelope. Use errors.As to inspect it.
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+	Fields     []dto.FieldError
+	RequestID  string
+}
+
+func (e *Error) Error() string {
+	message := fmt.Sprintf("housy: %d %s: %s", e.StatusCode, e.Code, e.Message)
+	for _, field := range e.Fields {
+		message += fmt.Sprintf("; %s: %s", field.Field, field.Message)
+	}
+	return message
+}
+
+// IsNotFound reports whether err is a 404 response.
+func IsNotFound(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// request describes a call. The body is kept as bytes so the request can be
+// sent again after refreshing the token.
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	body        []byte
+	contentType string
+	auth        bool
+}
+
+func jsonRequest(method, path string, body interface{}) (request, error) {
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return request{}, err
+	}
+	return request{method: method, path: path, body: encoded, contentType: "application/json"}, nil
+}
+
+// do sends the request and decodes the data of the success envelope into
+// out, unless out is nil. It returns the response headers.
+func (c *Client) do(ctx context.Context, req request, out interface{}) (http.Header, error) {
+	if req.auth {
+		if err := c.ensureToken(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// the token was revoked or expired early, sign in again once
+	if resp.StatusCode == http.StatusUnauthorized && req.auth && c.credentials != nil {
+		resp.Body.Close()
+		if err := c.refresh(ctx); err != nil {
+			return nil, err
+		}
+		if resp, err = c.send(ctx, req); err != nil {
+			return nil, err
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return resp.Header, decodeError(resp)
+	}
+
+	if out == nil {
+		return resp.Header, nil
+	}
+	if raw, ok := out.(*[]byte); ok {
+		*raw, err = io.ReadAll(resp.Body)
+		return resp.Header, err
+	}
+
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return resp.Header, fmt.Errorf("housy: decoding %s %s: %w", req.method, req.path, err)
+	}
+	if err := json.Unmarshal(envelope.Data, out); err != nil {
+		return resp.Header, fmt.Errorf("housy: decoding %s %s: %w", req.method, req.path, err)
+	}
+
+	return resp.Header, nil
+}
+
+func (c *Client) send(ctx context.Context, req request) (*http.Response, error) {
+	target := c.baseURL + req.path
+	if len(req.query) > 0 {
+		target += "?" + req.query.Encode()
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, target, bytes.NewReader(req.body))
+	if err != nil {
+		return nil, err
+	}
+	if req.contentType != "" {
+		httpReq.Header.Set("Content-Type", req.contentType)
+	}
+	if token := c.Token(); token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return c.http.Do(httpReq)
+}
+
+// ensureToken signs in when there is no token yet or it is about to expire.
+func (c *Client) ensureToken(ctx context.Context) error {
+	c.mu.Lock()
+	valid := c.token != "" && (c.expiresAt.IsZero() || time.Until(c.expiresAt) > refreshMargin)
+	c.mu.Unlock()
+
+	if valid || c.credentials == nil {
+		return nil
+	}
+	return c.refresh(ctx)
+}
+
+func (c *Client) refresh(ctx context.Context) error {
+	_, err := c.SignIn(ctx, *c.credentials)
+	return err
+}
+
+func decodeError(resp *http.Response) error {
+	apiErr := &Error{
+		StatusCode: resp.StatusCode,
+		Message:    http.StatusText(resp.StatusCode),
+		RequestID:  resp.Header.Get("X-Request-ID"),
+	}
+
+	var result dto.ErrorResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err == nil && result.Error != "" {
+		apiErr.Code = result.Error
+		apiErr.Message = result.Message
+		apiErr.Fields = result.Fields
+	}
+
+	return apiErr
+}
+
+// tokenExpiry reads the exp claim of a JWT without verifying it, the server
+// does that. It returns the zero time when there is none.
+func tokenExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}
+	}
+
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if json.Unmarshal(payload, &claims) != nil || claims.Exp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(claims.Exp, 0)
+}
@@ -0,0 +1,140 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	dto "housy/dto/result"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestErrorDecoding(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   Error
+	}{
+		{
+			name:   "envelope",
+			status: http.StatusConflict,
+			body:   `{"code":409,"error":"conflict","message":"the stay was already reviewed"}`,
+			want:   Error{StatusCode: http.StatusConflict, Code: dto.ErrConflict, Message: "the stay was already reviewed", RequestID: "req-1"},
+		},
+		{
+			name:   "fields",
+			status: http.StatusUnprocessableEntity,
+			body:   `{"code":422,"error":"validation_failed","message":"the request has invalid fields","fields":[{"field":"check_in","rule":"required","message":"is required"}]}`,
+			want: Error{StatusCode: http.StatusUnprocessableEntity, Code: dto.ErrValidation, Message: "the request has invalid fields", RequestID: "req-1",
+				Fields: []dto.FieldError{{Field: "check_in", Rule: "required", Message: "is required"}}},
+		},
+		{
+			// a proxy in front of the API answers with its own page
+			name:   "not json",
+			status: http.StatusBadGateway,
+			body:   "<html>502 Bad Gateway</html>",
+			want:   Error{StatusCode: http.StatusBadGateway, Message: "Bad Gateway", RequestID: "req-1"},
+		},
+		{
+			name:   "empty body",
+			status: http.StatusServiceUnavailable,
+			want:   Error{StatusCode: http.StatusServiceUnavailable, Message: "Service Unavailable", RequestID: "req-1"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Request-ID", "req-1")
+				w.WriteHeader(test.status)
+				fmt.Fprint(w, test.body)
+			}))
+			defer server.Close()
+
+			_, err := New(server.URL).GetHouse(context.Background(), 1)
+			var apiErr *Error
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("got %v, want an *Error", err)
+			}
+			if apiErr.StatusCode != test.want.StatusCode || apiErr.Code != test.want.Code || apiErr.Message != test.want.Message || apiErr.RequestID != test.want.RequestID {
+				t.Errorf("got %+v, want %+v", *apiErr, test.want)
+			}
+			if len(apiErr.Fields) != len(test.want.Fields) || (len(apiErr.Fields) > 0 && apiErr.Fields[0] != test.want.Fields[0]) {
+				t.Errorf("fields %+v, want %+v", apiErr.Fields, test.want.Fields)
+			}
+		})
+	}
+}
+
+func TestErrorMessageAndIsNotFound(t *testing.T) {
+	err := &Error{StatusCode: http.StatusNotFound, Code: dto.ErrNotFound, Message: "house not found",
+		Fields: []dto.FieldError{{Field: "id", Message: "is unknown"}}}
+
+	if got, want := err.Error(), "housy: 404 not_found: house not found; id: is unknown"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if !IsNotFound(fmt.Errorf("loading: %w", err)) {
+		t.Error("IsNotFound is false for a wrapped 404")
+	}
+	if IsNotFound(&Error{StatusCode: http.StatusForbidden}) || IsNotFound(errors.New("404")) {
+		t.Error("IsNotFound is true for other errors")
+	}
+}
+
+// token returns an unsigned JWT expiring at exp, the client doesn't verify
+// the signature.
+func token(exp time.Time) string {
+	encode := base64.RawURLEncoding.EncodeToString
+	return encode([]byte(`{"alg":"HS256"}`)) + "." + encode([]byte(fmt.Sprintf(`{"exp":%d}`, exp.Unix()))) + ".sig"
+}
+
+func TestTokenExpiry(t *testing.T) {
+	exp := time.Unix(1893456000, 0)
+
+	if got := tokenExpiry(token(exp)); !got.Equal(exp) {
+		t.Errorf("tokenExpiry() = %v, want %v", got, exp)
+	}
+	for _, malformed := range []string{"", "a.b", "a.!!!.c", "a." + base64.RawURLEncoding.EncodeToString([]byte(`{}`)) + ".c"} {
+		if got := tokenExpiry(malformed); !got.IsZero() {
+			t.Errorf("tokenExpiry(%q) = %v, want the zero time", malformed, got)
+		}
+	}
+}
+
+func TestSignsInAgainWhenTheTokenIsRejected(t *testing.T) {
+	var signIns atomic.Int32
+	current := token(time.Now().Add(time.Hour))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == BasePath+"/sign-in":
+			signIns.Add(1)
+			current = token(time.Now().Add(time.Hour)) + fmt.Sprint(signIns.Load())
+			json.NewEncoder(w).Encode(dto.SuccessResult{Code: http.StatusOK, Data: map[string]string{"token": current}})
+		case r.Header.Get("Authorization") != "Bearer "+current:
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(dto.NewError(http.StatusUnauthorized, "unauthorized"))
+		default:
+			json.NewEncoder(w).Encode(dto.SuccessResult{Code: http.StatusOK, Data: map[string]int{"id": 7}})
+		}
+	}))
+	defer server.Close()
+
+	// the token obtained elsewhere was revoked
+	c := New(server.URL, WithToken(token(time.Now().Add(2*time.Hour))), WithCredentials("budi", "password", "tenant"))
+	user, err := c.CheckAuth(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != 7 || signIns.Load() != 1 || !strings.HasSuffix(c.Token(), "1") {
+		t.Errorf("user %d after %d sign ins with token %q, want 7 after 1 with the new token", user.ID, signIns.Load(), c.Token())
+	}
+}
@@ -0,0 +1,207 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	housesdto "housy/dto/house"
+	"housy/models"
+)
+
+// HouseFilter holds the optional filters of FindHouses, zero values are
+// ignored. Query searches the text of the houses, best matches first.
+// Houses must have every amenity code in Amenities. Near sets the point the
+// distance of the houses is measured from, RadiusKm and Sort "distance"
+// need it.
+type HouseFilter struct {
+	Query     string
+	CityName  string
+	TypeRent  string
+	MinPrice  int
+	MaxPrice  int
+	Bedroom   int
+	Bathroom  int
+	Amenities []string
+	Near      *GeoPoint
+	RadiusKm  float64
+	Bounds    *GeoBounds
+	Sort      string
+}
+
+// GeoPoint is a position in decimal degrees.
+type GeoPoint struct {
+	Lat float64
+	Lng float64
+}
+
+// GeoBounds is the area shown by a map, in decimal degrees.
+type GeoBounds struct {
+	West  float64
+	South float64
+	East  float64
+	North float64
+}
+
+func (f HouseFilter) query() url.Values {
+	query := url.Values{}
+	setString(query, "q", f.Query)
+	setString(query, "cityname", f.CityName)
+	setString(query, "type_rent", f.TypeRent)
+	setInt(query, "min_price", f.MinPrice)
+	setInt(query, "max_price", f.MaxPrice)
+	setInt(query, "bedroom", f.Bedroom)
+	setInt(query, "bathroom", f.Bathroom)
+	setString(query, "amenities", strings.Join(f.Amenities, ","))
+	if f.Near != nil {
+		query.Set("lat", formatFloat(f.Near.Lat))
+		query.Set("lng", formatFloat(f.Near.Lng))
+	}
+	if f.RadiusKm != 0 {
+		query.Set("radius_km", formatFloat(f.RadiusKm))
+	}
+	if f.Bounds != nil {
+		bounds := []string{formatFloat(f.Bounds.West), formatFloat(f.Bounds.South), formatFloat(f.Bounds.East), formatFloat(f.Bounds.North)}
+		query.Set("bbox", strings.Join(bounds, ","))
+	}
+	setString(query, "sort", f.Sort)
+	return query
+}
+
+// Image is the image file uploaded with a house.
+type Image struct {
+	Name    string
+	Content io.Reader
+}
+
+func (c *Client) FindHouses(ctx context.Context, filter HouseFilter) *Iterator[models.House] {
+	return newIterator(ctx, func(ctx context.Context, page int) ([]models.House, http.Header, error) {
+		var houses []models.House
+		header, err := c.do(ctx, request{method: http.MethodGet, path: "/houses", query: pageQuery(filter.query(), page)}, &houses)
+		return houses, header, err
+	})
+}
+
+func (c *Client) GetHouse(ctx context.Context, id int) (housesdto.ResponseHouse, error) {
+	var house housesdto.ResponseHouse
+	_, err := c.do(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/house/%d", id)}, &house)
+	return house, err
+}
+
+// CreateHouse lists a house owned by the signed in user.
+func (c *Client) CreateHouse(ctx context.Context, house housesdto.HouseRequest, image Image) (models.House, error) {
+	var created models.House
+
+	req, err := houseRequest(http.MethodPost, "/house", house, image)
+	if err != nil {
+		return created, err
+	}
+	_, err = c.do(ctx, req, &created)
+	return created, err
+}
+
+// UpdateHouse changes the non zero fields of house. The server requires a
+// new image with every update.
+func (c *Client) UpdateHouse(ctx context.Context, id int, house housesdto.HouseRequest, image Image) (models.House, error) {
+	var updated models.House
+
+	req, err := houseRequest(http.MethodPatch, fmt.Sprintf("/house/%d", id), house, image)
+	if err != nil {
+		return updated, err
+	}
+	_, err = c.do(ctx, req, &updated)
+	return updated, err
+}
+
+func (c *Client) DeleteHouse(ctx context.Context, id int) (models.House, error) {
+	var house models.House
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: fmt.Sprintf("/house/%d", id), auth: true}, &house)
+	return house, err
+}
+
+// houseRequest builds the multipart form the house handlers read. Nil
+// amenities are left out, so an update keeps them, an empty slice removes
+// them all.
+func houseRequest(method, path string, house housesdto.HouseRequest, image Image) (request, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+
+	fields := map[string]string{
+		"name":        house.Name,
+		"cityname":    house.CityName,
+		"address":     house.Address,
+		"type_rent":   house.TypeRent,
+		"description": house.Description,
+		"area":        house.Area,
+	}
+	if house.Amenities != nil {
+		amenities, err := json.Marshal(house.Amenities)
+		if err != nil {
+			return request{}, err
+		}
+		fields["amenities"] = string(amenities)
+	}
+	if house.Latitude != nil && house.Longitude != nil {
+		fields["latitude"] = formatFloat(*house.Latitude)
+		fields["longitude"] = formatFloat(*house.Longitude)
+	}
+	if house.Price != 0 {
+		fields["price"] = strconv.Itoa(house.Price)
+	}
+	if house.CleaningFee != nil {
+		fields["cleaning_fee"] = strconv.Itoa(*house.CleaningFee)
+	}
+	if house.Deposit != nil {
+		fields["deposit"] = strconv.Itoa(*house.Deposit)
+	}
+	if house.Bedroom != 0 {
+		fields["Bedroom"] = strconv.Itoa(house.Bedroom)
+	}
+	if house.Bathroom != 0 {
+		fields["Bathroom"] = strconv.Itoa(house.Bathroom)
+	}
+	for name, value := range fields {
+		if value == "" {
+			continue
+		}
+		if err := form.WriteField(name, value); err != nil {
+			return request{}, err
+		}
+	}
+
+	file, err := form.CreateFormFile("image", image.Name)
+	if err != nil {
+		return request{}, err
+	}
+	if _, err := io.Copy(file, image.Content); err != nil {
+		return request{}, err
+	}
+	if err := form.Close(); err != nil {
+		return request{}, err
+	}
+
+	return request{method: method, path: path, body: body.Bytes(), contentType: form.FormDataContentType(), auth: true}, nil
+}
+
+func setString(query url.Values, key, value string) {
+	if value != "" {
+		query.Set(key, value)
+	}
+}
+
+func setInt(query url.Values, key string, value int) {
+	if value != 0 {
+		query.Set(key, strconv.Itoa(value))
+	}
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
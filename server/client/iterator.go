@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// PerPage is the page size iterators request.
+const PerPage = 100
+
+// Iterator walks a paginated list, fetching the next page when the current
+// one is used up:
+//
+//	for it.Next() {
+//		item := it.Value()
+//	}
+//	err := it.Err()
+type Iterator[T any] struct {
+	ctx   context.Context
+	fetch func(ctx context.Context, page int) ([]T, http.Header, error)
+
+	page    int
+	items   []T
+	current T
+	total   int64
+	done    bool
+	err     error
+}
+
+func newIterator[T any](ctx context.Context, fetch func(ctx context.Context, page int) ([]T, http.Header, error)) *Iterator[T] {
+	return &Iterator[T]{ctx: ctx, fetch: fetch, total: -1}
+}
+
+// Next moves to the next item, it returns false at the end of the list or
+// on error.
+func (it *Iterator[T]) Next() bool {
+	for len(it.items) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+
+		it.page++
+		items, header, err := it.fetch(it.ctx, it.page)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		if total, err := strconv.ParseInt(header.Get("X-Total-Count"), 10, 64); err == nil {
+			it.total = total
+		}
+
+		it.items = items
+		if len(items) < PerPage || (it.total >= 0 && int64(it.page*PerPage) >= it.total) {
+			it.done = true
+		}
+	}
+
+	it.current, it.items = it.items[0], it.items[1:]
+	return true
+}
+
+// Value is the current item.
+func (it *Iterator[T]) Value() T {
+	return it.current
+}
+
+// Err is the error that stopped the iteration, if any.
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// Total is the size of the whole list, known once the first page is
+// fetched, -1 before.
+func (it *Iterator[T]) Total() int64 {
+	return it.total
+}
+
+// All collects the remaining items.
+func (it *Iterator[T]) All() ([]T, error) {
+	var all []T
+	for it.Next() {
+		all = append(all, it.Value())
+	}
+	return all, it.Err()
+}
+
+func pageQuery(query url.Values, page int) url.Values {
+	paged := url.Values{}
+	for key, values := range query {
+		paged[key] = values
+	}
+	paged.Set("page", strconv.Itoa(page))
+	paged.Set("per_page", strconv.Itoa(PerPage))
+	return paged
+}
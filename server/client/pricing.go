@@ -0,0 +1,52 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	pricingdto "housy/dto/pricing"
+)
+
+func (c *Client) GetPricing(ctx context.Context, houseId int) (pricingdto.PricingResponse, error) {
+	var pricing pricingdto.PricingResponse
+	_, err := c.do(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/house/%d/pricing", houseId)}, &pricing)
+	return pricing, err
+}
+
+// UpdatePricing replaces the pricing rules and stay discounts of a house
+// owned by the signed in user.
+func (c *Client) UpdatePricing(ctx context.Context, houseId int, pricing pricingdto.PricingRequest) (pricingdto.PricingResponse, error) {
+	var updated pricingdto.PricingResponse
+
+	req, err := jsonRequest(http.MethodPut, fmt.Sprintf("/house/%d/pricing", houseId), pricing)
+	if err != nil {
+		return updated, err
+	}
+	req.auth = true
+	_, err = c.do(ctx, req, &updated)
+	return updated, err
+}
+
+// Quote prices a stay, checkIn and checkOut are dates formatted as
+// YYYY-MM-DD. An empty promoCode quotes the stay without promo code.
+func (c *Client) Quote(ctx context.Context, houseId int, checkIn, checkOut, promoCode string) (pricingdto.QuoteResponse, error) {
+	var quote pricingdto.QuoteResponse
+	query := url.Values{"check_in": {checkIn}, "check_out": {checkOut}}
+	setString(query, "promo_code", promoCode)
+	_, err := c.do(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/house/%d/quote", houseId), query: query, auth: true}, &quote)
+	return quote, err
+}
+
+// PriceCalendar lists the nightly prices of a house from from to to
+// included, YYYY-MM-DD. Empty dates leave the server defaults, the next 30
+// days.
+func (c *Client) PriceCalendar(ctx context.Context, houseId int, from, to string) ([]pricingdto.NightResponse, error) {
+	var nights []pricingdto.NightResponse
+	query := url.Values{}
+	setString(query, "from", from)
+	setString(query, "to", to)
+	_, err := c.do(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/house/%d/calendar", houseId), query: query}, &nights)
+	return nights, err
+}
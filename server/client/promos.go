@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	promodto "housy/dto/promo"
+)
+
+func (c *Client) FindPromoCodes(ctx context.Context) ([]promodto.PromoCodeResponse, error) {
+	var promos []promodto.PromoCodeResponse
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/admin/promos", auth: true}, &promos)
+	return promos, err
+}
+
+func (c *Client) CreatePromoCode(ctx context.Context, promo promodto.PromoCodeRequest) (promodto.PromoCodeResponse, error) {
+	return c.sendPromoCode(ctx, http.MethodPost, "/admin/promo", promo)
+}
+
+// UpdatePromoCode changes the non empty fields of promo.
+func (c *Client) UpdatePromoCode(ctx context.Context, id int, promo promodto.UpdatePromoCodeRequest) (promodto.PromoCodeResponse, error) {
+	return c.sendPromoCode(ctx, http.MethodPatch, fmt.Sprintf("/admin/promo/%d", id), promo)
+}
+
+// DeletePromoCode ends a promo code, it can't be redeemed anymore.
+func (c *Client) DeletePromoCode(ctx context.Context, id int) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: fmt.Sprintf("/admin/promo/%d", id), auth: true}, nil)
+	return err
+}
+
+func (c *Client) sendPromoCode(ctx context.Context, method, path string, body interface{}) (promodto.PromoCodeResponse, error) {
+	var promo promodto.PromoCodeResponse
+
+	req, err := jsonRequest(method, path, body)
+	if err != nil {
+		return promo, err
+	}
+	req.auth = true
+	_, err = c.do(ctx, req, &promo)
+	return promo, err
+}
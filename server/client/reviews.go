@@ -0,0 +1,60 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	reviewdto "housy/dto/review"
+)
+
+// FindHouseReviews lists the visible reviews of a house, newest first.
+func (c *Client) FindHouseReviews(ctx context.Context, houseId int) *Iterator[reviewdto.ReviewResponse] {
+	return newIterator(ctx, func(ctx context.Context, page int) ([]reviewdto.ReviewResponse, http.Header, error) {
+		var reviews []reviewdto.ReviewResponse
+		header, err := c.do(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/house/%d/reviews", houseId), query: pageQuery(url.Values{}, page)}, &reviews)
+		return reviews, header, err
+	})
+}
+
+// CreateReview reviews the stay of a paid transaction after check-out.
+func (c *Client) CreateReview(ctx context.Context, transactionId int, review reviewdto.ReviewRequest) (reviewdto.ReviewResponse, error) {
+	return c.sendReview(ctx, fmt.Sprintf("/transaction/%d/review", transactionId), review)
+}
+
+// ReplyReview answers a review as the owner of the house.
+func (c *Client) ReplyReview(ctx context.Context, id int, reply string) (reviewdto.ReviewResponse, error) {
+	return c.sendReview(ctx, fmt.Sprintf("/review/%d/reply", id), reviewdto.ReplyRequest{Reply: reply})
+}
+
+// FindHiddenReviews lists the reviews hidden by moderators, for admins.
+func (c *Client) FindHiddenReviews(ctx context.Context) *Iterator[reviewdto.ReviewResponse] {
+	return newIterator(ctx, func(ctx context.Context, page int) ([]reviewdto.ReviewResponse, http.Header, error) {
+		var reviews []reviewdto.ReviewResponse
+		header, err := c.do(ctx, request{method: http.MethodGet, path: "/admin/reviews/hidden", query: pageQuery(url.Values{}, page), auth: true}, &reviews)
+		return reviews, header, err
+	})
+}
+
+func (c *Client) HideReview(ctx context.Context, id int, reason string) (reviewdto.ReviewResponse, error) {
+	return c.sendReview(ctx, fmt.Sprintf("/admin/review/%d/hide", id), reviewdto.HideRequest{Reason: reason})
+}
+
+func (c *Client) UnhideReview(ctx context.Context, id int) (reviewdto.ReviewResponse, error) {
+	var review reviewdto.ReviewResponse
+	_, err := c.do(ctx, request{method: http.MethodPost, path: fmt.Sprintf("/admin/review/%d/unhide", id), auth: true}, &review)
+	return review, err
+}
+
+func (c *Client) sendReview(ctx context.Context, path string, body interface{}) (reviewdto.ReviewResponse, error) {
+	var review reviewdto.ReviewResponse
+
+	req, err := jsonRequest(http.MethodPost, path, body)
+	if err != nil {
+		return review, err
+	}
+	req.auth = true
+	_, err = c.do(ctx, req, &review)
+	return review, err
+}
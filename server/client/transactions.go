@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	transactiondto "housy/dto/transaction"
+	"housy/models"
+)
+
+// TransactionFilter holds the optional filters of FindTransactions. From and
+// To are check in dates, YYYY-MM-DD.
+type TransactionFilter struct {
+	StatusPayment string
+	HouseId       int
+	UserId        int
+	From          string
+	To            string
+}
+
+func (f TransactionFilter) query() url.Values {
+	query := url.Values{}
+	setString(query, "status_payment", f.StatusPayment)
+	setInt(query, "house_id", f.HouseId)
+	setInt(query, "user_id", f.UserId)
+	setString(query, "from", f.From)
+	setString(query, "to", f.To)
+	return query
+}
+
+// Payment is the Midtrans Snap payment of a new booking, the tenant pays on
+// the RedirectURL page or in Snap with the Token.
+type Payment struct {
+	Token       string `json:"token"`
+	RedirectURL string `json:"redirect_url"`
+}
+
+func (c *Client) FindTransactions(ctx context.Context, filter TransactionFilter) *Iterator[models.Transaction] {
+	return newIterator(ctx, func(ctx context.Context, page int) ([]models.Transaction, http.Header, error) {
+		var transactions []models.Transaction
+		header, err := c.do(ctx, request{method: http.MethodGet, path: "/transactions", query: pageQuery(filter.query(), page), auth: true}, &transactions)
+		return transactions, header, err
+	})
+}
+
+func (c *Client) GetTransaction(ctx context.Context, id int) (transactiondto.ResponseTransaction, error) {
+	var transaction transactiondto.ResponseTransaction
+	_, err := c.do(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/transaction/%d", id), auth: true}, &transaction)
+	return transaction, err
+}
+
+// CreateTransaction books a house for the signed in user and starts its
+// payment.
+func (c *Client) CreateTransaction(ctx context.Context, transaction transactiondto.RequestTransaction) (Payment, error) {
+	var payment Payment
+
+	req, err := jsonRequest(http.MethodPost, "/transaction", transaction)
+	if err != nil {
+		return payment, err
+	}
+	req.auth = true
+	_, err = c.do(ctx, req, &payment)
+	return payment, err
+}
+
+func (c *Client) DeleteTransaction(ctx context.Context, id int) (models.Transaction, error) {
+	var transaction models.Transaction
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: fmt.Sprintf("/transaction/%d", id), auth: true}, &transaction)
+	return transaction, err
+}
+
+// GetInvoice returns the invoice PDF of a paid transaction.
+func (c *Client) GetInvoice(ctx context.Context, id int) ([]byte, error) {
+	var pdf []byte
+	_, err := c.do(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/transaction/%d/invoice.pdf", id), auth: true}, &pdf)
+	return pdf, err
+}
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	usersdto "housy/dto/users"
+	"housy/models"
+)
+
+func (c *Client) FindUsers(ctx context.Context) ([]models.User, error) {
+	var users []models.User
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/users"}, &users)
+	return users, err
+}
+
+func (c *Client) GetUser(ctx context.Context, id int) (usersdto.UserResponse, error) {
+	var user usersdto.UserResponse
+	_, err := c.do(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/user/%d", id)}, &user)
+	return user, err
+}
+
+// UpdateUser changes the non empty fields of user, the signed in user or
+// any user for admins.
+func (c *Client) UpdateUser(ctx context.Context, id int, user usersdto.RequestUser) (usersdto.UserResponse, error) {
+	var updated usersdto.UserResponse
+
+	req, err := jsonRequest(http.MethodPatch, fmt.Sprintf("/user/%d", id), user)
+	if err != nil {
+		return updated, err
+	}
+	req.auth = true
+	_, err = c.do(ctx, req, &updated)
+	return updated, err
+}
+
+func (c *Client) DeleteUser(ctx context.Context, id int) (usersdto.UserResponse, error) {
+	var user usersdto.UserResponse
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: fmt.Sprintf("/user/%d", id), auth: true}, &user)
+	return user, err
+}
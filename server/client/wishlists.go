@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	wishlistdto "housy/dto/wishlist"
+)
+
+// FindMyWishlists returns the wishlists of the signed in user.
+func (c *Client) FindMyWishlists(ctx context.Context) ([]wishlistdto.WishlistResponse, error) {
+	var wishlists []wishlistdto.WishlistResponse
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/me/wishlists", auth: true}, &wishlists)
+	return wishlists, err
+}
+
+func (c *Client) CreateWishlist(ctx context.Context, name string) (wishlistdto.WishlistResponse, error) {
+	return c.sendWishlist(ctx, http.MethodPost, "/wishlist", wishlistdto.WishlistRequest{Name: name})
+}
+
+func (c *Client) RenameWishlist(ctx context.Context, id int, name string) (wishlistdto.WishlistResponse, error) {
+	return c.sendWishlist(ctx, http.MethodPatch, fmt.Sprintf("/wishlist/%d", id), wishlistdto.WishlistRequest{Name: name})
+}
+
+func (c *Client) DeleteWishlist(ctx context.Context, id int) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: fmt.Sprintf("/wishlist/%d", id), auth: true}, nil)
+	return err
+}
+
+// AddWishlistHouse adds a house to a wishlist, adding it again is a no-op.
+func (c *Client) AddWishlistHouse(ctx context.Context, id, houseId int) (wishlistdto.WishlistResponse, error) {
+	var wishlist wishlistdto.WishlistResponse
+	_, err := c.do(ctx, request{method: http.MethodPut, path: fmt.Sprintf("/wishlist/%d/house/%d", id, houseId), auth: true}, &wishlist)
+	return wishlist, err
+}
+
+func (c *Client) RemoveWishlistHouse(ctx context.Context, id, houseId int) (wishlistdto.WishlistResponse, error) {
+	var wishlist wishlistdto.WishlistResponse
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: fmt.Sprintf("/wishlist/%d/house/%d", id, houseId), auth: true}, &wishlist)
+	return wishlist, err
+}
+
+func (c *Client) sendWishlist(ctx context.Context, method, path string, body interface{}) (wishlistdto.WishlistResponse, error) {
+	var wishlist wishlistdto.WishlistResponse
+
+	req, err := jsonRequest(method, path, body)
+	if err != nil {
+		return wishlist, err
+	}
+	req.auth = true
+	_, err = c.do(ctx, req, &wishlist)
+	return wishlist, err
+}
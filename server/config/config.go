@@ -0,0 +1,566 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"housy/pkg/database"
+	"housy/pkg/logging"
+	"housy/pkg/mail"
+	"housy/pkg/payment"
+	"housy/pkg/pricing"
+	"housy/pkg/tracing"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is loaded, lowest precedence first, from the defaults, an optional
+// YAML or TOML file, the environment and the command line flags.
+type Config struct {
+	Port        int            `yaml:"port" toml:"port"`
+	BindAddress string         `yaml:"bind_address" toml:"bind_address"`
+	BaseURL     string         `yaml:"base_url" toml:"base_url"`
+	CORSOrigins []string       `yaml:"cors_origins" toml:"cors_origins"`
+	SecretKey   string         `yaml:"secret_key" toml:"secret_key"`
+	Server      ServerConfig   `yaml:"server" toml:"server"`
+	Log         LogConfig      `yaml:"log" toml:"log"`
+	Tracing     TracingConfig  `yaml:"tracing" toml:"tracing"`
+	Database    DatabaseConfig `yaml:"database" toml:"database"`
+	SMTP        SMTPConfig     `yaml:"smtp" toml:"smtp"`
+	Payment     PaymentConfig  `yaml:"payment" toml:"payment"`
+	Storage     StorageConfig  `yaml:"storage" toml:"storage"`
+	Search      SearchConfig   `yaml:"search" toml:"search"`
+	Fees        FeesConfig     `yaml:"fees" toml:"fees"`
+}
+
+// ServerConfig tunes the HTTP server. TLS is served when both the
+// certificate and key files are set, they are reloaded when they change.
+type ServerConfig struct {
+	ReadTimeout       time.Duration `yaml:"read_timeout" toml:"read_timeout"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" toml:"read_header_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" toml:"write_timeout"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" toml:"idle_timeout"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+	MaxHeaderBytes    int           `yaml:"max_header_bytes" toml:"max_header_bytes"`
+	TLSCertFile       string        `yaml:"tls_cert_file" toml:"tls_cert_file"`
+	TLSKeyFile        string        `yaml:"tls_key_file" toml:"tls_key_file"`
+}
+
+// LogConfig chooses the log level (debug, info, warn or error) and the
+// format (json or text).
+type LogConfig struct {
+	Level  string `yaml:"level" toml:"level"`
+	Format string `yaml:"format" toml:"format"`
+}
+
+// TracingConfig chooses where OpenTelemetry spans are exported: none,
+// stdout or otlp, the latter to an OTLP/HTTP collector at Endpoint.
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" toml:"exporter"`
+	Endpoint    string  `yaml:"endpoint" toml:"endpoint"`
+	Insecure    bool    `yaml:"insecure" toml:"insecure"`
+	ServiceName string  `yaml:"service_name" toml:"service_name"`
+	SampleRatio float64 `yaml:"sample_ratio" toml:"sample_ratio"`
+}
+
+type DatabaseConfig struct {
+	Driver          string        `yaml:"driver" toml:"driver"`
+	Host            string        `yaml:"host" toml:"host"`
+	Port            string        `yaml:"port" toml:"port"`
+	User            string        `yaml:"user" toml:"user"`
+	Password        string        `yaml:"password" toml:"password"`
+	Name            string        `yaml:"name" toml:"name"`
+	Charset         string        `yaml:"charset" toml:"charset"`
+	SSLMode         string        `yaml:"sslmode" toml:"sslmode"`
+	MaxOpenConns    int           `yaml:"max_open_conns" toml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" toml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" toml:"conn_max_idle_time"`
+}
+
+type SMTPConfig struct {
+	Host     string `yaml:"host" toml:"host"`
+	Port     int    `yaml:"port" toml:"port"`
+	Username string `yaml:"username" toml:"username"`
+	Password string `yaml:"password" toml:"password"`
+	From     string `yaml:"from" toml:"from"`
+}
+
+type PaymentConfig struct {
+	ServerKey  string `yaml:"server_key" toml:"server_key"`
+	ClientKey  string `yaml:"client_key" toml:"client_key"`
+	Production bool   `yaml:"production" toml:"production"`
+}
+
+type StorageConfig struct {
+	Dir     string `yaml:"dir" toml:"dir"`
+	BaseURL string `yaml:"base_url" toml:"base_url"`
+}
+
+// SearchConfig locates the full-text index of the houses, rebuilt from the
+// database when it is missing.
+type SearchConfig struct {
+	Dir string `yaml:"dir" toml:"dir"`
+}
+
+// FeesConfig sets what the platform adds to and withholds from bookings, in
+// percent. The service fee is charged to the tenant on the rent and the
+// cleaning fee, the host fee is withheld from the payout of the owner, and
+// the tax, such as PPN, is charged on everything the tenant pays but the
+// refundable deposit.
+type FeesConfig struct {
+	ServicePercent float64 `yaml:"service_percent" toml:"service_percent"`
+	HostPercent    float64 `yaml:"host_percent" toml:"host_percent"`
+	TaxPercent     float64 `yaml:"tax_percent" toml:"tax_percent"`
+	TaxName        string  `yaml:"tax_name" toml:"tax_name"`
+}
+
+// Default returns the configuration used for anything left unset.
+func Default() Config {
+	return Config{
+		Port:        8000,
+		BindAddress: "0.0.0.0",
+		CORSOrigins: []string{"*"},
+		Server: ServerConfig{
+			ReadTimeout:       30 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      60 * time.Second,
+			IdleTimeout:       120 * time.Second,
+			ShutdownTimeout:   30 * time.Second,
+			MaxHeaderBytes:    1 << 20,
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		Tracing: TracingConfig{
+			Exporter:    tracing.ExporterNone,
+			ServiceName: "housy",
+			SampleRatio: 1,
+		},
+		Database: DatabaseConfig{
+			Driver:  database.MySQL,
+			Charset: "utf8mb4",
+			SSLMode: "disable",
+		},
+		SMTP: SMTPConfig{
+			Host: "smtp.gmail.com",
+			Port: 587,
+		},
+		Storage: StorageConfig{
+			Dir: "uploads",
+		},
+		Search: SearchConfig{
+			Dir: "search",
+		},
+		Fees: FeesConfig{
+			TaxName: "PPN",
+		},
+	}
+}
+
+// Load reads the configuration for the given command line arguments and
+// validates it. It returns the arguments left after the flags, such as the
+// migrate subcommand.
+func Load(args []string) (Config, []string, error) {
+	config := Default()
+
+	flags := flag.NewFlagSet("housy", flag.ContinueOnError)
+	file := flags.String("config", os.Getenv("CONFIG_FILE"), "path of a YAML or TOML config file")
+	port := flags.Int("port", 0, "port to listen on")
+	bind := flags.String("bind", "", "address to bind to")
+	baseURL := flags.String("base-url", "", "public URL of the API")
+	if err := flags.Parse(args); err != nil {
+		return config, nil, err
+	}
+
+	if *file != "" {
+		if err := config.loadFile(*file); err != nil {
+			return config, nil, err
+		}
+	}
+
+	if err := config.loadEnv(); err != nil {
+		return config, nil, err
+	}
+
+	if *port != 0 {
+		config.Port = *port
+	}
+	if *bind != "" {
+		config.BindAddress = *bind
+	}
+	if *baseURL != "" {
+		config.BaseURL = *baseURL
+	}
+
+	config.fillDerived()
+
+	return config, flags.Args(), config.Validate()
+}
+
+func (c *Config) loadFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, c)
+	case ".toml":
+		err = toml.Unmarshal(content, c)
+	default:
+		return fmt.Errorf("config file %s must be .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// loadEnv overrides the configuration with the environment variables that
+// are set. The names are the ones the .env file has always used.
+func (c *Config) loadEnv() error {
+	var errs []string
+
+	str := func(name string, target *string) {
+		if value, ok := os.LookupEnv(name); ok {
+			*target = value
+		}
+	}
+	integer := func(name string, target *int) {
+		if value, ok := os.LookupEnv(name); ok {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				errs = append(errs, name+" must be a number")
+				return
+			}
+			*target = parsed
+		}
+	}
+	duration := func(name string, target *time.Duration) {
+		if value, ok := os.LookupEnv(name); ok {
+			parsed, err := time.ParseDuration(value)
+			if err != nil {
+				errs = append(errs, name+" must be a duration such as 30m")
+				return
+			}
+			*target = parsed
+		}
+	}
+	float := func(name string, target *float64) {
+		if value, ok := os.LookupEnv(name); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				errs = append(errs, name+" must be a number")
+				return
+			}
+			*target = parsed
+		}
+	}
+	boolean := func(name string, target *bool) {
+		if value, ok := os.LookupEnv(name); ok {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				errs = append(errs, name+" must be true or false")
+				return
+			}
+			*target = parsed
+		}
+	}
+
+	integer("PORT", &c.Port)
+	str("BIND_ADDRESS", &c.BindAddress)
+	str("BASE_URL", &c.BaseURL)
+	if value, ok := os.LookupEnv("CORS_ORIGINS"); ok {
+		c.CORSOrigins = nil
+		for _, origin := range strings.Split(value, ",") {
+			if origin = strings.TrimSpace(origin); origin != "" {
+				c.CORSOrigins = append(c.CORSOrigins, origin)
+			}
+		}
+	}
+	str("SECRET_KEY", &c.SecretKey)
+
+	duration("HTTP_READ_TIMEOUT", &c.Server.ReadTimeout)
+	duration("HTTP_READ_HEADER_TIMEOUT", &c.Server.ReadHeaderTimeout)
+	duration("HTTP_WRITE_TIMEOUT", &c.Server.WriteTimeout)
+	duration("HTTP_IDLE_TIMEOUT", &c.Server.IdleTimeout)
+	duration("HTTP_SHUTDOWN_TIMEOUT", &c.Server.ShutdownTimeout)
+	integer("HTTP_MAX_HEADER_BYTES", &c.Server.MaxHeaderBytes)
+	str("TLS_CERT_FILE", &c.Server.TLSCertFile)
+	str("TLS_KEY_FILE", &c.Server.TLSKeyFile)
+
+	str("LOG_LEVEL", &c.Log.Level)
+	str("LOG_FORMAT", &c.Log.Format)
+
+	str("TRACING_EXPORTER", &c.Tracing.Exporter)
+	str("OTEL_EXPORTER_OTLP_ENDPOINT", &c.Tracing.Endpoint)
+	boolean("OTEL_EXPORTER_OTLP_INSECURE", &c.Tracing.Insecure)
+	str("OTEL_SERVICE_NAME", &c.Tracing.ServiceName)
+	float("TRACING_SAMPLE_RATIO", &c.Tracing.SampleRatio)
+
+	str("DB_DRIVER", &c.Database.Driver)
+	str("DB_HOST", &c.Database.Host)
+	str("DB_PORT", &c.Database.Port)
+	str("DB_USER", &c.Database.User)
+	str("DB_PASSWORD", &c.Database.Password)
+	str("DB_NAME", &c.Database.Name)
+	str("DB_CHARSET", &c.Database.Charset)
+	str("DB_SSLMODE", &c.Database.SSLMode)
+	integer("DB_MAX_OPEN_CONNS", &c.Database.MaxOpenConns)
+	integer("DB_MAX_IDLE_CONNS", &c.Database.MaxIdleConns)
+	duration("DB_CONN_MAX_LIFETIME", &c.Database.ConnMaxLifetime)
+	duration("DB_CONN_MAX_IDLE_TIME", &c.Database.ConnMaxIdleTime)
+
+	str("SMTP_HOST", &c.SMTP.Host)
+	integer("SMTP_PORT", &c.SMTP.Port)
+	str("EMAIL_SYSTEM", &c.SMTP.Username)
+	str("PASSWORD_SYSTEM", &c.SMTP.Password)
+	str("SMTP_FROM", &c.SMTP.From)
+
+	str("SERVER_KEY", &c.Payment.ServerKey)
+	str("CLIENT_KEY", &c.Payment.ClientKey)
+	boolean("MIDTRANS_PRODUCTION", &c.Payment.Production)
+
+	str("UPLOAD_DIR", &c.Storage.Dir)
+	str("PATH_FILE", &c.Storage.BaseURL)
+
+	str("SEARCH_DIR", &c.Search.Dir)
+
+	float("SERVICE_FEE_PERCENT", &c.Fees.ServicePercent)
+	float("HOST_FEE_PERCENT", &c.Fees.HostPercent)
+	float("TAX_PERCENT", &c.Fees.TaxPercent)
+	str("TAX_NAME", &c.Fees.TaxName)
+
+	if len(errs) > 0 {
+		return validationError(errs)
+	}
+	return nil
+}
+
+// fillDerived sets the values that default to other settings.
+func (c *Config) fillDerived() {
+	if c.BaseURL == "" {
+		c.BaseURL = "http://localhost:" + strconv.Itoa(c.Port)
+	}
+	c.BaseURL = strings.TrimRight(c.BaseURL, "/")
+	if c.Storage.BaseURL == "" {
+		c.Storage.BaseURL = c.BaseURL + "/uploads/"
+	}
+	if c.SMTP.From == "" && c.SMTP.Username != "" {
+		c.SMTP.From = "Housy <" + c.SMTP.Username + ">"
+	}
+}
+
+// Validate reports every missing or malformed value at once.
+func (c Config) Validate() error {
+	var errs []string
+
+	if c.Port < 1 || c.Port > 65535 {
+		errs = append(errs, "PORT must be between 1 and 65535")
+	}
+	if c.BindAddress != "" && net.ParseIP(c.BindAddress) == nil && c.BindAddress != "localhost" {
+		errs = append(errs, "BIND_ADDRESS must be an IP address or localhost")
+	}
+	if u, err := url.Parse(c.BaseURL); err != nil || u.Scheme == "" || u.Host == "" {
+		errs = append(errs, "BASE_URL must be an absolute URL such as https://api.example.com")
+	}
+	if len(c.CORSOrigins) == 0 {
+		errs = append(errs, "CORS_ORIGINS must list at least one origin")
+	}
+	if c.SecretKey == "" {
+		errs = append(errs, "SECRET_KEY is required")
+	}
+
+	timeouts := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"HTTP_READ_TIMEOUT", c.Server.ReadTimeout},
+		{"HTTP_READ_HEADER_TIMEOUT", c.Server.ReadHeaderTimeout},
+		{"HTTP_WRITE_TIMEOUT", c.Server.WriteTimeout},
+		{"HTTP_IDLE_TIMEOUT", c.Server.IdleTimeout},
+		{"HTTP_SHUTDOWN_TIMEOUT", c.Server.ShutdownTimeout},
+	}
+	for _, timeout := range timeouts {
+		if timeout.value <= 0 {
+			errs = append(errs, timeout.name+" must be a positive duration")
+		}
+	}
+	if c.Server.MaxHeaderBytes < 1 {
+		errs = append(errs, "HTTP_MAX_HEADER_BYTES must be a positive number")
+	}
+	if (c.Server.TLSCertFile == "") != (c.Server.TLSKeyFile == "") {
+		errs = append(errs, "TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+
+	switch strings.ToLower(c.Log.Level) {
+	case "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, "LOG_LEVEL must be debug, info, warn or error")
+	}
+	if c.Log.Format != "json" && c.Log.Format != "text" {
+		errs = append(errs, "LOG_FORMAT must be json or text")
+	}
+
+	switch c.Tracing.Exporter {
+	case tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP:
+	default:
+		errs = append(errs, "TRACING_EXPORTER must be none, stdout or otlp")
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		errs = append(errs, "TRACING_SAMPLE_RATIO must be between 0 and 1")
+	}
+
+	switch c.Database.Driver {
+	case database.MySQL, database.Postgres:
+		if c.Database.Host == "" {
+			errs = append(errs, "DB_HOST is required")
+		}
+		if c.Database.User == "" {
+			errs = append(errs, "DB_USER is required")
+		}
+		if c.Database.Name == "" {
+			errs = append(errs, "DB_NAME is required")
+		}
+	case database.SQLite:
+		if c.Database.Name == "" {
+			errs = append(errs, "DB_NAME is required, the sqlite file path or :memory:")
+		}
+	default:
+		errs = append(errs, "DB_DRIVER must be mysql, postgres or sqlite")
+	}
+
+	if c.SMTP.Host == "" {
+		errs = append(errs, "SMTP_HOST is required")
+	}
+	if c.SMTP.Port < 1 || c.SMTP.Port > 65535 {
+		errs = append(errs, "SMTP_PORT must be between 1 and 65535")
+	}
+	if c.SMTP.Username == "" {
+		errs = append(errs, "EMAIL_SYSTEM is required")
+	}
+	if c.SMTP.Password == "" {
+		errs = append(errs, "PASSWORD_SYSTEM is required")
+	}
+
+	if c.Payment.ServerKey == "" {
+		errs = append(errs, "SERVER_KEY is required")
+	}
+
+	if c.Storage.Dir == "" {
+		errs = append(errs, "UPLOAD_DIR must not be empty")
+	}
+
+	if c.Search.Dir == "" {
+		errs = append(errs, "SEARCH_DIR must not be empty")
+	}
+
+	percents := []struct {
+		name  string
+		value float64
+	}{
+		{"SERVICE_FEE_PERCENT", c.Fees.ServicePercent},
+		{"HOST_FEE_PERCENT", c.Fees.HostPercent},
+		{"TAX_PERCENT", c.Fees.TaxPercent},
+	}
+	for _, percent := range percents {
+		if !(percent.value >= 0 && percent.value <= 100) {
+			errs = append(errs, percent.name+" must be between 0 and 100")
+		}
+	}
+	if c.Fees.TaxPercent > 0 && c.Fees.TaxName == "" {
+		errs = append(errs, "TAX_NAME is required with TAX_PERCENT")
+	}
+
+	if len(errs) > 0 {
+		return validationError(errs)
+	}
+	return nil
+}
+
+func validationError(errs []string) error {
+	return errors.New("invalid configuration:\n  - " + strings.Join(errs, "\n  - "))
+}
+
+// TLS reports whether the server is configured to serve HTTPS.
+func (c Config) TLS() bool {
+	return c.Server.TLSCertFile != "" && c.Server.TLSKeyFile != ""
+}
+
+// Address is the address the HTTP server listens on.
+func (c Config) Address() string {
+	return net.JoinHostPort(c.BindAddress, strconv.Itoa(c.Port))
+}
+
+func (c Config) DatabaseConfig() database.Config {
+	return database.Config{
+		Driver:          c.Database.Driver,
+		Host:            c.Database.Host,
+		Port:            c.Database.Port,
+		User:            c.Database.User,
+		Password:        c.Database.Password,
+		Name:            c.Database.Name,
+		Charset:         c.Database.Charset,
+		SSLMode:         c.Database.SSLMode,
+		MaxOpenConns:    c.Database.MaxOpenConns,
+		MaxIdleConns:    c.Database.MaxIdleConns,
+		ConnMaxLifetime: c.Database.ConnMaxLifetime,
+		ConnMaxIdleTime: c.Database.ConnMaxIdleTime,
+	}
+}
+
+func (c Config) LoggingConfig() logging.Config {
+	return logging.Config{
+		Level:  c.Log.Level,
+		Format: c.Log.Format,
+	}
+}
+
+func (c Config) TracingConfig() tracing.Config {
+	return tracing.Config{
+		Exporter:    c.Tracing.Exporter,
+		Endpoint:    c.Tracing.Endpoint,
+		Insecure:    c.Tracing.Insecure,
+		ServiceName: c.Tracing.ServiceName,
+		SampleRatio: c.Tracing.SampleRatio,
+	}
+}
+
+func (c Config) MailConfig() mail.Config {
+	return mail.Config{
+		Host:     c.SMTP.Host,
+		Port:     c.SMTP.Port,
+		Username: c.SMTP.Username,
+		Password: c.SMTP.Password,
+		From:     c.SMTP.From,
+	}
+}
+
+func (c Config) PaymentConfig() payment.Config {
+	return payment.Config{
+		ServerKey:  c.Payment.ServerKey,
+		ClientKey:  c.Payment.ClientKey,
+		Production: c.Payment.Production,
+	}
+}
+
+func (c Config) FeesConfig() pricing.Fees {
+	return pricing.Fees{
+		ServicePercent: c.Fees.ServicePercent,
+		HostPercent:    c.Fees.HostPercent,
+		TaxPercent:     c.Fees.TaxPercent,
+		TaxName:        c.Fees.TaxName,
+	}
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// valid returns a configuration that passes Validate.
+func valid() Config {
+	c := Default()
+	c.SecretKey = "secret"
+	c.Database.Driver = "sqlite"
+	c.Database.Name = ":memory:"
+	c.SMTP.Username = "housy@example.com"
+	c.SMTP.Password = "password"
+	c.Payment.ServerKey = "server-key"
+	c.fillDerived()
+	return c
+}
+
+func TestValidate(t *testing.T) {
+	if err := valid().Validate(); err != nil {
+		t.Fatalf("the valid configuration was refused: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		change func(c *Config)
+		want   string
+	}{
+		{"port", func(c *Config) { c.Port = 70000 }, "PORT must be between 1 and 65535"},
+		{"bind address", func(c *Config) { c.BindAddress = "example.com" }, "BIND_ADDRESS must be an IP address"},
+		{"relative base url", func(c *Config) { c.BaseURL = "/api" }, "BASE_URL must be an absolute URL"},
+		{"no cors origins", func(c *Config) { c.CORSOrigins = nil }, "CORS_ORIGINS must list at least one origin"},
+		{"secret key", func(c *Config) { c.SecretKey = "" }, "SECRET_KEY is required"},
+		{"timeout", func(c *Config) { c.Server.WriteTimeout = 0 }, "HTTP_WRITE_TIMEOUT must be a positive duration"},
+		{"tls key alone", func(c *Config) { c.Server.TLSKeyFile = "key.pem" }, "TLS_CERT_FILE and TLS_KEY_FILE must be set together"},
+		{"log level", func(c *Config) { c.Log.Level = "verbose" }, "LOG_LEVEL must be debug, info, warn or error"},
+		{"log format", func(c *Config) { c.Log.Format = "xml" }, "LOG_FORMAT must be json or text"},
+		{"tracing exporter", func(c *Config) { c.Tracing.Exporter = "jaeger" }, "TRACING_EXPORTER must be none, stdout or otlp"},
+		{"sample ratio", func(c *Config) { c.Tracing.SampleRatio = 1.5 }, "TRACING_SAMPLE_RATIO must be between 0 and 1"},
+		{"mysql host", func(c *Config) { c.Database.Driver = "mysql" }, "DB_HOST is required"},
+		{"sqlite file", func(c *Config) { c.Database.Name = "" }, "DB_NAME is required, the sqlite file path or :memory:"},
+		{"driver", func(c *Config) { c.Database.Driver = "oracle" }, "DB_DRIVER must be mysql, postgres or sqlite"},
+		{"smtp port", func(c *Config) { c.SMTP.Port = 0 }, "SMTP_PORT must be between 1 and 65535"},
+		{"server key", func(c *Config) { c.Payment.ServerKey = "" }, "SERVER_KEY is required"},
+		{"upload dir", func(c *Config) { c.Storage.Dir = "" }, "UPLOAD_DIR must not be empty"},
+		{"search dir", func(c *Config) { c.Search.Dir = "" }, "SEARCH_DIR must not be empty"},
+		{"negative fee", func(c *Config) { c.Fees.ServicePercent = -1 }, "SERVICE_FEE_PERCENT must be between 0 and 100"},
+		{"fee over 100", func(c *Config) { c.Fees.HostPercent = 101 }, "HOST_FEE_PERCENT must be between 0 and 100"},
+		{"tax without a name", func(c *Config) { c.Fees.TaxPercent = 11; c.Fees.TaxName = "" }, "TAX_NAME is required with TAX_PERCENT"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := valid()
+			test.change(&c)
+			err := c.Validate()
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("Validate() = %v, want %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestValidateReportsEveryError(t *testing.T) {
+	c := valid()
+	c.SecretKey = ""
+	c.Payment.ServerKey = ""
+	c.Log.Format = "xml"
+
+	err := c.Validate()
+	if err == nil {
+		t.Fatal("the invalid configuration was accepted")
+	}
+	for _, want := range []string{"SECRET_KEY", "SERVER_KEY", "LOG_FORMAT"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("the error doesn't mention %s: %v", want, err)
+		}
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "housy.yaml")
+	content := `
+port: 9000
+secret_key: from-file
+log:
+  level: debug
+server:
+  write_timeout: 90s
+database:
+  driver: sqlite
+  name: housy.db
+smtp:
+  username: housy@example.com
+  password: password
+payment:
+  server_key: server-key
+`
+	if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// the environment overrides the file, the flags override both
+	t.Setenv("SECRET_KEY", "from-env")
+	t.Setenv("PORT", "9100")
+	t.Setenv("CORS_ORIGINS", "https://a.example, https://b.example")
+
+	c, args, err := Load([]string{"-config", file, "-port", "9200", "migrate", "up"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Port != 9200 || c.SecretKey != "from-env" || c.Log.Level != "debug" || c.Server.WriteTimeout != 90*time.Second {
+		t.Errorf("port %d, secret key %q, log level %q, write timeout %v, want 9200, from-env, debug and 90s", c.Port, c.SecretKey, c.Log.Level, c.Server.WriteTimeout)
+	}
+	if len(c.CORSOrigins) != 2 || c.CORSOrigins[1] != "https://b.example" {
+		t.Errorf("CORSOrigins = %q, want the 2 trimmed origins", c.CORSOrigins)
+	}
+	if c.BaseURL != "http://localhost:9200" || c.Storage.BaseURL != "http://localhost:9200/uploads/" {
+		t.Errorf("BaseURL %q and Storage.BaseURL %q weren't derived from the port", c.BaseURL, c.Storage.BaseURL)
+	}
+	if len(args) != 2 || args[0] != "migrate" {
+		t.Errorf("args = %q, want migrate up", args)
+	}
+}
+
+func TestLoadMalformedEnvironment(t *testing.T) {
+	t.Setenv("PORT", "eighty")
+	t.Setenv("HTTP_READ_TIMEOUT", "30")
+
+	_, _, err := Load(nil)
+	if err == nil {
+		t.Fatal("the malformed environment was accepted")
+	}
+	for _, want := range []string{"PORT must be a number", "HTTP_READ_TIMEOUT must be a duration"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("the error doesn't mention %q: %v", want, err)
+		}
+	}
+}
@@ -1,21 +1,241 @@
 package database
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
-	"housy/models"
-	"housy/pkg/mysql"
+	"housy/database/migrations"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	migrationsDir = "database/migrations"
+	lockName      = "housy_schema_migrations"
+	lockTimeout   = 60
+	lockKey       = 4863795 // postgres advisory locks take a number instead of a name
 )
 
-func RunMigration() {
-	err := mysql.DB.AutoMigrate(
-		&models.User{},
-		&models.House{},
-		&models.Transaction{})
+// SchemaMigration records an applied migration.
+type SchemaMigration struct {
+	Version   string `gorm:"primaryKey;type: varchar(255)"`
+	Name      string `gorm:"type: varchar(255)"`
+	AppliedAt time.Time
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// RunMigration applies the pending migrations at startup.
+func RunMigration(db *gorm.DB) {
+	err := Up(db)
+	if err != nil {
+		slog.Error("migration failed", "error", err)
+		os.Exit(1)
+	}
+
+	slog.Info("migration success")
+}
+
+// Command runs the migrate subcommand: up, down [steps], status or new <name>.
+func Command(db *gorm.DB, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("usage: migrate up | down [steps] | status | new <name>")
+	}
+
+	switch args[0] {
+	case "up":
+		return Up(db)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			var err error
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return errors.New("steps must be a positive number")
+			}
+		}
+		return Down(db, steps)
+	case "status":
+		return Status(db, out)
+	case "new":
+		if len(args) < 2 {
+			return errors.New("usage: migrate new <name>")
+		}
+		path, err := New(migrationsDir, args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, "Created "+path)
+		return nil
+	}
+
+	return fmt.Errorf("unknown migrate command %q", args[0])
+}
+
+// Up applies every pending migration in version order.
+func Up(db *gorm.DB) error {
+	return withLock(db, func(conn *gorm.DB) error {
+		applied, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range migrations.All() {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := migration.Up(tx); err != nil {
+					return err
+				}
+				return tx.Create(&SchemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %s_%s failed: %w", migration.Version, migration.Name, err)
+			}
+			slog.Info("applied migration", "version", migration.Version, "name", migration.Name)
+		}
+
+		return nil
+	})
+}
+
+// Down rolls back the last applied migrations.
+func Down(db *gorm.DB, steps int) error {
+	return withLock(db, func(conn *gorm.DB) error {
+		applied, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+
+		all := migrations.All()
+		for i := len(all) - 1; i >= 0 && steps > 0; i-- {
+			migration := all[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := migration.Down(tx); err != nil {
+					return err
+				}
+				return tx.Delete(&SchemaMigration{}, "version = ?", migration.Version).Error
+			})
+			if err != nil {
+				return fmt.Errorf("rollback of %s_%s failed: %w", migration.Version, migration.Name, err)
+			}
+			slog.Info("rolled back migration", "version", migration.Version, "name", migration.Name)
+			steps--
+		}
 
+		return nil
+	})
+}
+
+// Status prints every migration and when it was applied.
+func Status(db *gorm.DB, out io.Writer) error {
+	applied, err := appliedVersions(db)
 	if err != nil {
-		fmt.Println(err)
-		panic("Migration Failed")
+		return err
+	}
+
+	for _, migration := range migrations.All() {
+		status := "pending"
+		if record, ok := applied[migration.Version]; ok {
+			status = "applied " + record.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(out, "%s_%s\t%s\n", migration.Version, migration.Name, status)
 	}
 
-	fmt.Println("Migration Success")
+	return nil
+}
+
+var migrationName = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// New writes an empty migration file named after the current time.
+func New(dir string, name string) (string, error) {
+	if !migrationName.MatchString(name) {
+		return "", errors.New("migration name may only contain lowercase letters, digits and underscores")
+	}
+
+	version := time.Now().UTC().Format("20060102150405")
+	path := filepath.Join(dir, version+"_"+name+".go")
+	content := fmt.Sprintf(migrationTemplate, version, name)
+
+	return path, os.WriteFile(path, []byte(content), 0644)
+}
+
+const migrationTemplate = `package migrations
+
+import "gorm.io/gorm"
+
+func init() {
+	register(Migration{
+		Version: "%s",
+		Name:    "%s",
+		Up: func(tx *gorm.DB) error {
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			return nil
+		},
+	})
+}
+`
+
+func appliedVersions(db *gorm.DB) (map[string]SchemaMigration, error) {
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, err
+	}
+
+	var records []SchemaMigration
+	if err := db.Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	applied := make(map[string]SchemaMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// withLock runs fn on a single connection holding an advisory lock, so
+// replicas booting at the same time don't apply migrations concurrently.
+// SQLite databases belong to a single process and aren't locked.
+func withLock(db *gorm.DB, fn func(conn *gorm.DB) error) error {
+	return db.Connection(func(conn *gorm.DB) error {
+		// a new session, so every statement on the connection starts clean
+		conn = conn.Session(&gorm.Session{})
+
+		switch conn.Dialector.Name() {
+		case "mysql":
+			var acquired sql.NullInt64
+			err := conn.Raw("SELECT GET_LOCK(?, ?)", lockName, lockTimeout).Scan(&acquired).Error
+			if err != nil {
+				return err
+			}
+			if acquired.Int64 != 1 {
+				return errors.New("timed out waiting for the migration lock")
+			}
+			defer conn.Exec("SELECT RELEASE_LOCK(?)", lockName)
+		case "postgres":
+			if err := conn.Exec("SELECT pg_advisory_lock(?)", lockKey).Error; err != nil {
+				return err
+			}
+			defer conn.Exec("SELECT pg_advisory_unlock(?)", lockKey)
+		}
+
+		return fn(conn)
+	})
 }
@@ -0,0 +1,77 @@
+package database
+
+import (
+	"housy/database/migrations"
+	"housy/pkg/database"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func openMemory(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := database.Open(database.Config{Driver: database.SQLite, Name: ":memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func countApplied(t *testing.T, db *gorm.DB) int {
+	t.Helper()
+
+	applied, err := appliedVersions(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(applied)
+}
+
+func TestUpAndDownOnSQLite(t *testing.T) {
+	db := openMemory(t)
+	all := migrations.All()
+
+	if err := Up(db); err != nil {
+		t.Fatal(err)
+	}
+	if got := countApplied(t, db); got != len(all) {
+		t.Fatalf("%d migrations applied, want %d", got, len(all))
+	}
+	for _, table := range []string{"users", "houses", "transactions", "transaction_items", "reviews", "promo_codes"} {
+		if !db.Migrator().HasTable(table) {
+			t.Errorf("table %s is missing after migrating up", table)
+		}
+	}
+
+	// applying again is a no-op
+	if err := Up(db); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Down(db, 1); err != nil {
+		t.Fatal(err)
+	}
+	if got := countApplied(t, db); got != len(all)-1 {
+		t.Fatalf("%d migrations applied after rolling one back, want %d", got, len(all)-1)
+	}
+
+	if err := Down(db, len(all)); err != nil {
+		t.Fatal(err)
+	}
+	if got := countApplied(t, db); got != 0 {
+		t.Fatalf("%d migrations still applied after rolling back all of them", got)
+	}
+
+	if err := Up(db); err != nil {
+		t.Fatal(err)
+	}
+	if got := countApplied(t, db); got != len(all) {
+		t.Fatalf("%d migrations applied after migrating up again, want %d", got, len(all))
+	}
+}
@@ -0,0 +1,132 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// The baseline is the schema AutoMigrate used to create at startup. The
+// structs are frozen copies of the models at that point, later model changes
+// belong in their own migration. AutoMigrate is used so databases created by
+// the old startup migration are brought up to date instead of failing.
+
+type baselineUser struct {
+	ID         int
+	Fullname   string `gorm:"type: varchar(255)"`
+	Email      string `gorm:"type: varchar(255)"`
+	Password   string `gorm:"type: varchar(255)"`
+	Username   string `gorm:"type: varchar(255)"`
+	ListAsRole string `gorm:"type: varchar(225)"`
+	Address    string `gorm:"type: varchar(225)"`
+	Gender     string `gorm:"type: varchar(225)"`
+	Phone      string `gorm:"type: varchar(225)"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	DeletedAt  gorm.DeletedAt `gorm:"index"`
+}
+
+func (baselineUser) TableName() string {
+	return "users"
+}
+
+type baselineHouse struct {
+	ID          int            `gorm:"primaryKey:autoIncrement"`
+	Name        string         `gorm:"type: varchar(255)"`
+	CityName    string         `gorm:"type: varchar(255)"`
+	Address     string         `gorm:"type: text"`
+	Price       int            `gorm:"type: int"`
+	TypeRent    string         `gorm:"type: varchar(255)"`
+	Amenities   datatypes.JSON `gorm:"type: json"`
+	Bedroom     int            `gorm:"type: int"`
+	Bathroom    int            `gorm:"type: int"`
+	Area        string
+	Description string
+	Image       string `gorm:"type: varchar(255)"`
+	OwnerId     int    `gorm:"type: int"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+}
+
+func (baselineHouse) TableName() string {
+	return "houses"
+}
+
+type baselineTransaction struct {
+	ID            int `gorm:"primary_key:auto_increment"`
+	CheckIn       string
+	CheckOut      string
+	HouseId       int
+	House         baselineHouse
+	UserId        int
+	User          baselineUser `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Total         int
+	StatusPayment string
+	Attachment    string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	DeletedAt     gorm.DeletedAt `gorm:"index"`
+}
+
+func (baselineTransaction) TableName() string {
+	return "transactions"
+}
+
+type baselineInvoice struct {
+	ID            int    `gorm:"primary_key:auto_increment"`
+	Number        string `gorm:"type: varchar(255);uniqueIndex"`
+	TransactionId int    `gorm:"uniqueIndex"`
+	Transaction   baselineTransaction
+	PaymentMethod string `gorm:"type: varchar(255)"`
+	IssuedAt      time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func (baselineInvoice) TableName() string {
+	return "invoices"
+}
+
+type baselineExportJob struct {
+	ID        int `gorm:"primary_key:auto_increment"`
+	UserId    int
+	Resource  string `gorm:"type: varchar(255)"`
+	Format    string `gorm:"type: varchar(255)"`
+	Query     string `gorm:"type: text"`
+	Status    string `gorm:"type: varchar(255)"`
+	File      string `gorm:"type: varchar(255)"`
+	Error     string `gorm:"type: text"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (baselineExportJob) TableName() string {
+	return "export_jobs"
+}
+
+func init() {
+	register(Migration{
+		Version: "20261019000000",
+		Name:    "baseline",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(
+				&baselineUser{},
+				&baselineHouse{},
+				&baselineTransaction{},
+				&baselineInvoice{},
+				&baselineExportJob{},
+			)
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(
+				&baselineExportJob{},
+				&baselineInvoice{},
+				&baselineTransaction{},
+				&baselineHouse{},
+				&baselineUser{},
+			)
+		},
+	})
+}
@@ -0,0 +1,43 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// houses.area and houses.description were declared with a misspelled
+// "grom" tag, so AutoMigrate created them with the default string type.
+
+type houseTextColumns struct {
+	Area        string `gorm:"type: text"`
+	Description string `gorm:"type: text"`
+}
+
+func (houseTextColumns) TableName() string {
+	return "houses"
+}
+
+type houseDefaultColumns struct {
+	Area        string
+	Description string
+}
+
+func (houseDefaultColumns) TableName() string {
+	return "houses"
+}
+
+func init() {
+	register(Migration{
+		Version: "20261019000001",
+		Name:    "house_text_columns",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().AlterColumn(&houseTextColumns{}, "Area"); err != nil {
+				return err
+			}
+			return tx.Migrator().AlterColumn(&houseTextColumns{}, "Description")
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().AlterColumn(&houseDefaultColumns{}, "Area"); err != nil {
+				return err
+			}
+			return tx.Migrator().AlterColumn(&houseDefaultColumns{}, "Description")
+		},
+	})
+}
@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type outboxEvent struct {
+	ID          int    `gorm:"primary_key:auto_increment"`
+	Topic       string `gorm:"type: varchar(255)"`
+	Payload     string `gorm:"type: text"`
+	Status      string `gorm:"type: varchar(255);index"`
+	Attempts    int
+	Error       string    `gorm:"type: text"`
+	AvailableAt time.Time `gorm:"index"`
+	ProcessedAt *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (outboxEvent) TableName() string {
+	return "outbox_events"
+}
+
+func init() {
+	register(Migration{
+		Version: "20261019000002",
+		Name:    "outbox_events",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&outboxEvent{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&outboxEvent{})
+		},
+	})
+}
@@ -0,0 +1,69 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type review struct {
+	ID            int `gorm:"primary_key:auto_increment"`
+	TransactionId int `gorm:"uniqueIndex"`
+	HouseId       int `gorm:"index"`
+	UserId        int
+	Cleanliness   int
+	Accuracy      int
+	Location      int
+	Value         int
+	Rating        float64
+	Comment       string `gorm:"type: text"`
+	Reply         string `gorm:"type: text"`
+	RepliedAt     *time.Time
+	Hidden        bool   `gorm:"index"`
+	HiddenReason  string `gorm:"type: varchar(255)"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func (review) TableName() string {
+	return "reviews"
+}
+
+// houseRating is the aggregate of the visible reviews, kept on the house so
+// the house list can show it without a join.
+type houseRating struct {
+	Rating      float64
+	ReviewCount int
+}
+
+func (houseRating) TableName() string {
+	return "houses"
+}
+
+func init() {
+	register(Migration{
+		Version: "20261019000003",
+		Name:    "reviews",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&review{}); err != nil {
+				return err
+			}
+			for _, field := range []string{"Rating", "ReviewCount"} {
+				if !tx.Migrator().HasColumn(&houseRating{}, field) {
+					if err := tx.Migrator().AddColumn(&houseRating{}, field); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, field := range []string{"Rating", "ReviewCount"} {
+				if err := tx.Migrator().DropColumn(&houseRating{}, field); err != nil {
+					return err
+				}
+			}
+			return tx.Migrator().DropTable(&review{})
+		},
+	})
+}
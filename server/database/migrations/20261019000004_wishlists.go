@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type wishlist struct {
+	ID        int    `gorm:"primary_key:auto_increment"`
+	UserId    int    `gorm:"index"`
+	Name      string `gorm:"type: varchar(255)"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (wishlist) TableName() string {
+	return "wishlists"
+}
+
+type wishlistHouse struct {
+	WishlistId int `gorm:"primaryKey"`
+	HouseId    int `gorm:"primaryKey;index"`
+	CreatedAt  time.Time
+}
+
+func (wishlistHouse) TableName() string {
+	return "wishlist_houses"
+}
+
+func init() {
+	register(Migration{
+		Version: "20261019000004",
+		Name:    "wishlists",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&wishlist{}, &wishlistHouse{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&wishlistHouse{}, &wishlist{})
+		},
+	})
+}
@@ -0,0 +1,209 @@
+package migrations
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// houses.amenities was free-form JSON taken from the house form. It is
+// replaced by a catalog and a join table. The migration seeds the catalog,
+// links every house to the amenities its JSON names, matching common
+// spellings, and adds the names it can't match to the catalog under
+// "other" so nothing is lost. The column is left in place, Down writes the
+// codes back to it.
+
+type amenity struct {
+	ID        int    `gorm:"primary_key:auto_increment"`
+	Code      string `gorm:"type: varchar(64);uniqueIndex"`
+	Label     string `gorm:"type: varchar(255)"`
+	Icon      string `gorm:"type: varchar(255)"`
+	Category  string `gorm:"type: varchar(64)"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (amenity) TableName() string {
+	return "amenities"
+}
+
+type houseAmenity struct {
+	HouseId   int `gorm:"primaryKey"`
+	AmenityId int `gorm:"primaryKey;index"`
+}
+
+func (houseAmenity) TableName() string {
+	return "house_amenities"
+}
+
+type houseAmenitiesColumn struct {
+	ID        int
+	Amenities datatypes.JSON `gorm:"type: json"`
+}
+
+func (houseAmenitiesColumn) TableName() string {
+	return "houses"
+}
+
+var amenityCatalog = []amenity{
+	{Code: "wifi", Label: "Wi-Fi", Icon: "wifi", Category: "basics"},
+	{Code: "air_conditioning", Label: "Air conditioning", Icon: "ac_unit", Category: "basics"},
+	{Code: "heating", Label: "Heating", Icon: "heat", Category: "basics"},
+	{Code: "hot_water", Label: "Hot water", Icon: "water_drop", Category: "basics"},
+	{Code: "kitchen", Label: "Kitchen", Icon: "kitchen", Category: "basics"},
+	{Code: "washer", Label: "Washing machine", Icon: "local_laundry_service", Category: "basics"},
+	{Code: "tv", Label: "TV", Icon: "tv", Category: "basics"},
+	{Code: "furnished", Label: "Furnished", Icon: "chair", Category: "features"},
+	{Code: "pet_allowed", Label: "Pet allowed", Icon: "pets", Category: "features"},
+	{Code: "shared_accommodation", Label: "Shared accommodation", Icon: "group", Category: "features"},
+	{Code: "balcony", Label: "Balcony", Icon: "balcony", Category: "features"},
+	{Code: "garden", Label: "Garden", Icon: "yard", Category: "features"},
+	{Code: "parking", Label: "Parking", Icon: "local_parking", Category: "facilities"},
+	{Code: "pool", Label: "Swimming pool", Icon: "pool", Category: "facilities"},
+	{Code: "gym", Label: "Gym", Icon: "fitness_center", Category: "facilities"},
+	{Code: "security", Label: "24-hour security", Icon: "security", Category: "safety"},
+}
+
+var amenityAliases = map[string]string{
+	"wi_fi":               "wifi",
+	"internet":            "wifi",
+	"ac":                  "air_conditioning",
+	"aircon":              "air_conditioning",
+	"air_conditioner":     "air_conditioning",
+	"television":          "tv",
+	"washing_machine":     "washer",
+	"laundry":             "washer",
+	"pets_allowed":        "pet_allowed",
+	"pet_friendly":        "pet_allowed",
+	"shared_accomodation": "shared_accommodation",
+	"car_park":            "parking",
+	"parking_lot":         "parking",
+	"swimming_pool":       "pool",
+}
+
+var nonCodeRunes = regexp.MustCompile(`[^a-z0-9]+`)
+
+// amenityCode turns a free-form name such as "Air Conditioner" or
+// "petAllowed" into a catalog code.
+func amenityCode(name string) string {
+	var b strings.Builder
+	var previous rune
+	for _, r := range name {
+		if unicode.IsUpper(r) && (unicode.IsLower(previous) || unicode.IsDigit(previous)) {
+			b.WriteRune('_')
+		}
+		b.WriteRune(unicode.ToLower(r))
+		previous = r
+	}
+	code := strings.Trim(nonCodeRunes.ReplaceAllString(b.String(), "_"), "_")
+	if alias, ok := amenityAliases[code]; ok {
+		return alias
+	}
+	return code
+}
+
+// amenityNames reads the names of the old column, which held an array of
+// names, an object of name to boolean, or a comma separated string.
+func amenityNames(value datatypes.JSON) []string {
+	var names []string
+	if json.Unmarshal(value, &names) == nil {
+		return names
+	}
+
+	var flags map[string]interface{}
+	if json.Unmarshal(value, &flags) == nil {
+		for name, flag := range flags {
+			if flag != nil && flag != false && flag != "" && flag != 0.0 {
+				names = append(names, name)
+			}
+		}
+		return names
+	}
+
+	var list string
+	if json.Unmarshal(value, &list) != nil {
+		list = string(value)
+	}
+	return strings.Split(list, ",")
+}
+
+func init() {
+	register(Migration{
+		Version: "20261019000005",
+		Name:    "amenities",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&amenity{}, &houseAmenity{}); err != nil {
+				return err
+			}
+
+			catalog := make(map[string]int)
+			for _, entry := range amenityCatalog {
+				if err := tx.Create(&entry).Error; err != nil {
+					return err
+				}
+				catalog[entry.Code] = entry.ID
+			}
+
+			var houses []houseAmenitiesColumn
+			if err := tx.Where("amenities IS NOT NULL").Find(&houses).Error; err != nil {
+				return err
+			}
+			for _, house := range houses {
+				linked := make(map[int]bool)
+				for _, name := range amenityNames(house.Amenities) {
+					code := amenityCode(name)
+					if code == "" || len(code) > 64 {
+						continue
+					}
+					id, ok := catalog[code]
+					if !ok {
+						entry := amenity{Code: code, Label: strings.TrimSpace(name), Category: "other"}
+						if err := tx.Create(&entry).Error; err != nil {
+							return err
+						}
+						id = entry.ID
+						catalog[code] = id
+					}
+					if linked[id] {
+						continue
+					}
+					linked[id] = true
+					if err := tx.Create(&houseAmenity{HouseId: house.ID, AmenityId: id}).Error; err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			var rows []struct {
+				HouseId int
+				Code    string
+			}
+			err := tx.Table("house_amenities").Select("house_amenities.house_id, amenities.code").
+				Joins("JOIN amenities ON amenities.id = house_amenities.amenity_id").
+				Order("house_amenities.house_id, amenities.code").Scan(&rows).Error
+			if err != nil {
+				return err
+			}
+
+			codes := make(map[int][]string)
+			for _, row := range rows {
+				codes[row.HouseId] = append(codes[row.HouseId], row.Code)
+			}
+			for houseId, houseCodes := range codes {
+				value, _ := json.Marshal(houseCodes)
+				if err := tx.Model(&houseAmenitiesColumn{}).Where("id = ?", houseId).Update("amenities", datatypes.JSON(value)).Error; err != nil {
+					return err
+				}
+			}
+
+			return tx.Migrator().DropTable(&houseAmenity{}, &amenity{})
+		},
+	})
+}
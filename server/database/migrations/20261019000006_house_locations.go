@@ -0,0 +1,49 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// houseLocation places the house on the map, the composite index serves the
+// bounding boxes of the radius and map searches.
+type houseLocation struct {
+	Latitude  *float64 `gorm:"index:idx_houses_location,priority:1"`
+	Longitude *float64 `gorm:"index:idx_houses_location,priority:2"`
+}
+
+func (houseLocation) TableName() string {
+	return "houses"
+}
+
+func init() {
+	register(Migration{
+		Version: "20261019000006",
+		Name:    "house_locations",
+		Up: func(tx *gorm.DB) error {
+			for _, field := range []string{"Latitude", "Longitude"} {
+				if !tx.Migrator().HasColumn(&houseLocation{}, field) {
+					if err := tx.Migrator().AddColumn(&houseLocation{}, field); err != nil {
+						return err
+					}
+				}
+			}
+			if tx.Migrator().HasIndex(&houseLocation{}, "idx_houses_location") {
+				return nil
+			}
+			return tx.Migrator().CreateIndex(&houseLocation{}, "idx_houses_location")
+		},
+		Down: func(tx *gorm.DB) error {
+			// sqlite loses the indexes of a table whenever a later migration
+			// drops one of its columns
+			if tx.Migrator().HasIndex(&houseLocation{}, "idx_houses_location") {
+				if err := tx.Migrator().DropIndex(&houseLocation{}, "idx_houses_location"); err != nil {
+					return err
+				}
+			}
+			for _, field := range []string{"Latitude", "Longitude"} {
+				if err := tx.Migrator().DropColumn(&houseLocation{}, field); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,50 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type pricingRule struct {
+	ID        int    `gorm:"primary_key:auto_increment"`
+	HouseId   int    `gorm:"index"`
+	Name      string `gorm:"type: varchar(255)"`
+	StartDate string `gorm:"type: varchar(10)"`
+	EndDate   string `gorm:"type: varchar(10)"`
+	Weekdays  string `gorm:"type: varchar(32)"`
+	Price     int
+	MinNights int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (pricingRule) TableName() string {
+	return "pricing_rules"
+}
+
+type stayDiscount struct {
+	ID        int `gorm:"primary_key:auto_increment"`
+	HouseId   int `gorm:"index"`
+	MinNights int
+	Percent   int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (stayDiscount) TableName() string {
+	return "stay_discounts"
+}
+
+func init() {
+	register(Migration{
+		Version: "20261019000007",
+		Name:    "pricing",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&pricingRule{}, &stayDiscount{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&stayDiscount{}, &pricingRule{})
+		},
+	})
+}
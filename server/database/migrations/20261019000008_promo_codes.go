@@ -0,0 +1,93 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type promoCode struct {
+	ID             int    `gorm:"primary_key:auto_increment"`
+	Code           string `gorm:"type: varchar(64);uniqueIndex"`
+	Description    string `gorm:"type: varchar(255)"`
+	Kind           string `gorm:"type: varchar(16)"`
+	Amount         int
+	MaxDiscount    int
+	MinSpend       int
+	ValidFrom      string `gorm:"type: varchar(10)"`
+	ValidUntil     string `gorm:"type: varchar(10)"`
+	MaxUses        int
+	MaxUsesPerUser int
+	Uses           int
+	HouseId        int
+	CityName       string `gorm:"type: varchar(255)"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	DeletedAt      gorm.DeletedAt `gorm:"index"`
+}
+
+func (promoCode) TableName() string {
+	return "promo_codes"
+}
+
+type promoUsage struct {
+	PromoCodeId int `gorm:"primaryKey"`
+	UserId      int `gorm:"primaryKey"`
+	Uses        int
+}
+
+func (promoUsage) TableName() string {
+	return "promo_usages"
+}
+
+type promoRedemption struct {
+	ID            int `gorm:"primary_key:auto_increment"`
+	PromoCodeId   int `gorm:"index"`
+	UserId        int
+	TransactionId int `gorm:"uniqueIndex"`
+	Discount      int
+	CreatedAt     time.Time
+}
+
+func (promoRedemption) TableName() string {
+	return "promo_redemptions"
+}
+
+// transactionPromo is the promo code redeemed by a booking and what it took
+// off the total.
+type transactionPromo struct {
+	PromoCode     string `gorm:"type: varchar(64)"`
+	PromoDiscount int
+}
+
+func (transactionPromo) TableName() string {
+	return "transactions"
+}
+
+func init() {
+	register(Migration{
+		Version: "20261019000008",
+		Name:    "promo_codes",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&promoCode{}, &promoUsage{}, &promoRedemption{}); err != nil {
+				return err
+			}
+			for _, field := range []string{"PromoCode", "PromoDiscount"} {
+				if !tx.Migrator().HasColumn(&transactionPromo{}, field) {
+					if err := tx.Migrator().AddColumn(&transactionPromo{}, field); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, field := range []string{"PromoCode", "PromoDiscount"} {
+				if err := tx.Migrator().DropColumn(&transactionPromo{}, field); err != nil {
+					return err
+				}
+			}
+			return tx.Migrator().DropTable(&promoRedemption{}, &promoUsage{}, &promoCode{})
+		},
+	})
+}
@@ -0,0 +1,76 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// houseFees are the fees the owner of a house charges with every stay.
+type houseFees struct {
+	CleaningFee int
+	Deposit     int
+}
+
+func (houseFees) TableName() string {
+	return "houses"
+}
+
+// transactionPayout is what the owner gets from a booking.
+type transactionPayout struct {
+	OwnerPayout int
+}
+
+func (transactionPayout) TableName() string {
+	return "transactions"
+}
+
+type transactionItem struct {
+	ID            int    `gorm:"primary_key:auto_increment"`
+	TransactionId int    `gorm:"index"`
+	Kind          string `gorm:"type: varchar(32)"`
+	Description   string `gorm:"type: varchar(255)"`
+	Quantity      int
+	UnitPrice     int
+	Amount        int
+}
+
+func (transactionItem) TableName() string {
+	return "transaction_items"
+}
+
+func init() {
+	register(Migration{
+		Version: "20261019000009",
+		Name:    "fees",
+		Up: func(tx *gorm.DB) error {
+			for _, field := range []string{"CleaningFee", "Deposit"} {
+				if !tx.Migrator().HasColumn(&houseFees{}, field) {
+					if err := tx.Migrator().AddColumn(&houseFees{}, field); err != nil {
+						return err
+					}
+				}
+			}
+			if !tx.Migrator().HasColumn(&transactionPayout{}, "OwnerPayout") {
+				if err := tx.Migrator().AddColumn(&transactionPayout{}, "OwnerPayout"); err != nil {
+					return err
+				}
+				// the bookings made before the fees paid the owner in full
+				if err := tx.Exec("UPDATE transactions SET owner_payout = total").Error; err != nil {
+					return err
+				}
+			}
+			return tx.AutoMigrate(&transactionItem{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&transactionItem{}); err != nil {
+				return err
+			}
+			if err := tx.Migrator().DropColumn(&transactionPayout{}, "OwnerPayout"); err != nil {
+				return err
+			}
+			for _, field := range []string{"CleaningFee", "Deposit"} {
+				if err := tx.Migrator().DropColumn(&houseFees{}, field); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
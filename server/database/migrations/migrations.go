@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+// Migration is one versioned schema change. Versions are timestamps so
+// migrations written on different branches still sort in creation order.
+type Migration struct {
+	Version string
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+var migrations []Migration
+
+func register(migration Migration) {
+	migrations = append(migrations, migration)
+}
+
+// All returns every migration ordered by version.
+func All() []Migration {
+	all := make([]Migration, len(migrations))
+	copy(all, migrations)
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Version < all[j].Version
+	})
+	return all
+}
@@ -0,0 +1,16 @@
+package amenitydto
+
+type AmenityRequest struct {
+	Code     string `json:"code" validate:"required,code,max=64"`
+	Label    string `json:"label" validate:"required,max=255"`
+	Icon     string `json:"icon" validate:"max=255"`
+	Category string `json:"category" validate:"required,code,max=64"`
+}
+
+// UpdateAmenityRequest leaves empty fields as they are. The code can't be
+// changed, houses are searched by it.
+type UpdateAmenityRequest struct {
+	Label    string `json:"label" validate:"max=255"`
+	Icon     string `json:"icon" validate:"max=255"`
+	Category string `json:"category" validate:"omitempty,code,max=64"`
+}
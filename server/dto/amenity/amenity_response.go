@@ -0,0 +1,9 @@
+package amenitydto
+
+type AmenityResponse struct {
+	ID       int    `json:"id"`
+	Code     string `json:"code"`
+	Label    string `json:"label"`
+	Icon     string `json:"icon"`
+	Category string `json:"category"`
+}
@@ -6,7 +6,7 @@ type SignUpRequest struct {
 	Username   string `json:"username" gorm:"type : varchar(255)" validate:"required"`
 	Email      string `json:"email" gorm:"type : varchar(255)" validate:"required"`
 	Password   string `json:"password" gorm:"type : varchar(255)" validate:"required"`
-	ListAsRole string `json:"listAsRole" gorm:"type : varchar(255)"`
+	ListAsRole string `json:"listAsRole" gorm:"type : varchar(255)" validate:"required,oneof=tenant owner"`
 	Gender     string `json:"gender" gorm:"type : varchar(255)"`
 	Phone      string `json:"phone" gorm:"type : varchar(255)" validate:"required"`
 	Address    string `json:"address" gorm:"type : text" validate:"required"`
@@ -35,5 +35,4 @@ type CheckAuthResponse struct {
 	Address    string `json:"address" gorm:"type : varchar(255)"`
 	Token      string `json:"token" gorm:"type : varchar(255)"`
 	ListAsRole string `gorm:"type: varchar(255)" json:"listAsRole"`
-
 }
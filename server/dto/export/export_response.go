@@ -0,0 +1,13 @@
+package exportdto
+
+import "time"
+
+type ExportJobResponse struct {
+	ID          int       `json:"id"`
+	Resource    string    `json:"resource"`
+	Format      string    `json:"format"`
+	Status      string    `json:"status"`
+	Error       string    `json:"error,omitempty"`
+	DownloadURL string    `json:"download_url,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -1,17 +1,19 @@
 package housesdto
 
-import "gorm.io/datatypes"
-
 type HouseRequest struct {
-	Name        string         `json:"name" gorm:"type: varchar(225)" validate:"required" form:"name"`
-	CityName    string         `json:"cityname" gorm:"type: varchar(255)" validate:"required" form:"cityname"`
-	Address     string         `json:"address" gorm:"type: text" validate:"required" form:"address"`
-	Price       int            `json:"price" gorm:"type: int" validate:"required" form:"price"`
-	TypeRent    string         `json:"type_rent" gorm:"type: varchar(225)" validate:"required" form:"Type_rent"`
-	Amenities   datatypes.JSON `json:"amenities" gorm:"type: JSON" validate:"required" form:"Amenities"`
-	Bedroom     int            `json:"Bedroom" gorm:"type: int" validate:"required" form:"Bedroom"`
-	Bathroom    int            `json:"Bathroom" gorm:"type: int" validate:"required" form:"Bathroom"`
-	Image       string         `json:"image" gorm:"type: varchar(255)" form:"Image"`
-	Description string         `json:"description" gorm:"type: varchar(255)" form:"description"`
-	Area        string         `json:"area" gorm:"type: varchar(255)" form:"area"`
+	Name        string   `json:"name" gorm:"type: varchar(225)" validate:"required" form:"name"`
+	CityName    string   `json:"cityname" gorm:"type: varchar(255)" validate:"required" form:"cityname"`
+	Address     string   `json:"address" gorm:"type: text" validate:"required" form:"address"`
+	Latitude    *float64 `json:"latitude" validate:"required_with=Longitude,omitempty,gte=-90,lte=90" form:"latitude"`
+	Longitude   *float64 `json:"longitude" validate:"required_with=Latitude,omitempty,gte=-180,lte=180" form:"longitude"`
+	Price       int      `json:"price" gorm:"type: int" validate:"required" form:"price"`
+	CleaningFee *int     `json:"cleaning_fee" validate:"omitempty,gte=0" form:"cleaning_fee"`
+	Deposit     *int     `json:"deposit" validate:"omitempty,gte=0" form:"deposit"`
+	TypeRent    string   `json:"type_rent" gorm:"type: varchar(225)" validate:"required" form:"Type_rent"`
+	Amenities   []string `json:"amenities" validate:"required,dive,required" form:"Amenities"`
+	Bedroom     int      `json:"Bedroom" gorm:"type: int" validate:"required" form:"Bedroom"`
+	Bathroom    int      `json:"Bathroom" gorm:"type: int" validate:"required" form:"Bathroom"`
+	Image       string   `json:"image" gorm:"type: varchar(255)" form:"Image"`
+	Description string   `json:"description" gorm:"type: varchar(255)" form:"description"`
+	Area        string   `json:"area" gorm:"type: varchar(255)" form:"area"`
 }
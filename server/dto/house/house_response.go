@@ -1,18 +1,45 @@
 package housesdto
 
-import "gorm.io/datatypes"
+import amenitydto "housy/dto/amenity"
 
 type ResponseHouse struct {
-	ID          int            `json:"id" gorm:"type: varchar(225)" form:"id"`
-	Name        string         `json:"name" gorm:"type: varchar(225)"  form:"Name"`
-	CityName    string         `json:"cityname" gorm:"type: varchar(255)"  form:"CityName"`
-	Address     string         `json:"address" gorm:"type: text"  form:"Address"`
-	Price       int            `json:"price" gorm:"type: int"  form:"Price"`
-	TypeRent    string         `json:"type_rent" gorm:"type: varchar(225)"  form:"TypeRent"`
-	Amenities   datatypes.JSON `json:"amenities" gorm:"type: JSON"  form:"Amenities"`
-	Bedroom     int            `json:"Bedroom" gorm:"type: int"  form:"Bedroom"`
-	Bathroom    int            `json:"Bathroom" gorm:"type: int"  form:"Bathroom"`
-	Image       string         `json:"image" gorm:"type: varchar(255)" form:"Image"`
-	Description string         `json:"description" gorm:"type: varchar(255)" form:"description"`
-	Area        string         `json:"area" gorm:"type: varchar(255)" form:"area"`
+	ID          int                          `json:"id" gorm:"type: varchar(225)" form:"id"`
+	Name        string                       `json:"name" gorm:"type: varchar(225)"  form:"Name"`
+	CityName    string                       `json:"cityname" gorm:"type: varchar(255)"  form:"CityName"`
+	Address     string                       `json:"address" gorm:"type: text"  form:"Address"`
+	Latitude    *float64                     `json:"latitude"`
+	Longitude   *float64                     `json:"longitude"`
+	Price       int                          `json:"price" gorm:"type: int"  form:"Price"`
+	CleaningFee int                          `json:"cleaning_fee"`
+	Deposit     int                          `json:"deposit"`
+	TypeRent    string                       `json:"type_rent" gorm:"type: varchar(225)"  form:"TypeRent"`
+	Amenities   []amenitydto.AmenityResponse `json:"amenities" form:"Amenities"`
+	Bedroom     int                          `json:"Bedroom" gorm:"type: int"  form:"Bedroom"`
+	Bathroom    int                          `json:"Bathroom" gorm:"type: int"  form:"Bathroom"`
+	Image       string                       `json:"image" gorm:"type: varchar(255)" form:"Image"`
+	Description string                       `json:"description" gorm:"type: varchar(255)" form:"description"`
+	Area        string                       `json:"area" gorm:"type: varchar(255)" form:"area"`
+	OwnerId     int                          `json:"owner_id" form:"owner_id"`
+	Rating      float64                      `json:"rating"`
+	ReviewCount int                          `json:"review_count"`
+
+	IsFavorited   bool     `json:"is_favorited"`
+	FavoriteCount *int     `json:"favorite_count,omitempty"`
+	DistanceKm    *float64 `json:"distance_km,omitempty"`
+}
+
+type ImportRowResponse struct {
+	Row    int      `json:"row"`
+	Name   string   `json:"name"`
+	ID     int      `json:"id,omitempty"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+type ImportResponse struct {
+	Mode    string              `json:"mode"`
+	Total   int                 `json:"total"`
+	Valid   int                 `json:"valid"`
+	Created int                 `json:"created"`
+	Failed  int                 `json:"failed"`
+	Rows    []ImportRowResponse `json:"rows"`
 }
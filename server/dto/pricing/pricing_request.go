@@ -0,0 +1,23 @@
+package pricingdto
+
+// PricingRequest replaces the whole pricing of a house.
+type PricingRequest struct {
+	Rules     []PricingRuleRequest  `json:"rules" validate:"max=100,dive"`
+	Discounts []StayDiscountRequest `json:"discounts" validate:"max=10,dive"`
+}
+
+// PricingRuleRequest leaves out the dates or weekdays to cover every
+// night, and sets a price, a minimum stay or both.
+type PricingRuleRequest struct {
+	Name      string   `json:"name" validate:"max=255"`
+	StartDate string   `json:"start_date" validate:"omitempty,datetime=2006-01-02"`
+	EndDate   string   `json:"end_date" validate:"omitempty,datetime=2006-01-02"`
+	Weekdays  []string `json:"weekdays" validate:"dive,oneof=sun mon tue wed thu fri sat"`
+	Price     int      `json:"price" validate:"gte=0"`
+	MinNights int      `json:"min_nights" validate:"gte=0,lte=365"`
+}
+
+type StayDiscountRequest struct {
+	MinNights int `json:"min_nights" validate:"required,gte=2"`
+	Percent   int `json:"percent" validate:"required,gte=1,lte=99"`
+}
@@ -0,0 +1,56 @@
+package pricingdto
+
+import transactiondto "housy/dto/transaction"
+
+type PricingResponse struct {
+	HouseId     int                    `json:"house_id"`
+	BasePrice   int                    `json:"base_price"`
+	CleaningFee int                    `json:"cleaning_fee"`
+	Deposit     int                    `json:"deposit"`
+	Rules       []PricingRuleResponse  `json:"rules"`
+	Discounts   []StayDiscountResponse `json:"discounts"`
+}
+
+type PricingRuleResponse struct {
+	ID        int      `json:"id"`
+	Name      string   `json:"name"`
+	StartDate string   `json:"start_date,omitempty"`
+	EndDate   string   `json:"end_date,omitempty"`
+	Weekdays  []string `json:"weekdays"`
+	Price     int      `json:"price"`
+	MinNights int      `json:"min_nights"`
+}
+
+type StayDiscountResponse struct {
+	ID        int `json:"id"`
+	MinNights int `json:"min_nights"`
+	Percent   int `json:"percent"`
+}
+
+// NightResponse is the price of the night starting on Date, and the
+// minimum stay of a check-in on Date.
+type NightResponse struct {
+	Date      string `json:"date"`
+	Price     int    `json:"price"`
+	Rule      string `json:"rule,omitempty"`
+	MinNights int    `json:"min_nights,omitempty"`
+}
+
+type QuoteResponse struct {
+	HouseId         int                           `json:"house_id"`
+	CheckIn         string                        `json:"check_in"`
+	CheckOut        string                        `json:"check_out"`
+	Nights          int                           `json:"nights"`
+	Nightly         []NightResponse               `json:"nightly"`
+	Subtotal        int                           `json:"subtotal"`
+	DiscountPercent int                           `json:"discount_percent"`
+	Discount        int                           `json:"discount"`
+	PromoCode       string                        `json:"promo_code,omitempty"`
+	PromoDiscount   int                           `json:"promo_discount"`
+	CleaningFee     int                           `json:"cleaning_fee"`
+	ServiceFee      int                           `json:"service_fee"`
+	Tax             int                           `json:"tax"`
+	Deposit         int                           `json:"deposit"`
+	Total           int                           `json:"total"`
+	Items           []transactiondto.ItemResponse `json:"items"`
+}
@@ -0,0 +1,29 @@
+package promodto
+
+// PromoCodeRequest creates a promo code. Amount is a percent of the total
+// for the percent kind and rupiah for the fixed kind. Zero limits and empty
+// restrictions don't apply.
+type PromoCodeRequest struct {
+	Code           string `json:"code" validate:"required,promo_code,min=3,max=32"`
+	Description    string `json:"description" validate:"max=255"`
+	Kind           string `json:"kind" validate:"required,oneof=percent fixed"`
+	Amount         int    `json:"amount" validate:"required,gte=1"`
+	MaxDiscount    int    `json:"max_discount" validate:"gte=0"`
+	MinSpend       int    `json:"min_spend" validate:"gte=0"`
+	ValidFrom      string `json:"valid_from" validate:"omitempty,datetime=2006-01-02"`
+	ValidUntil     string `json:"valid_until" validate:"omitempty,datetime=2006-01-02"`
+	MaxUses        int    `json:"max_uses" validate:"gte=0"`
+	MaxUsesPerUser int    `json:"max_uses_per_user" validate:"gte=0"`
+	HouseId        int    `json:"house_id" validate:"gte=0"`
+	CityName       string `json:"cityname" validate:"max=255"`
+}
+
+// UpdatePromoCodeRequest leaves empty fields as they are, a limit set to 0
+// is removed. The code, its amount and restrictions can't be changed once
+// it may have been redeemed, a new code is created instead.
+type UpdatePromoCodeRequest struct {
+	Description    string `json:"description" validate:"max=255"`
+	ValidUntil     string `json:"valid_until" validate:"omitempty,datetime=2006-01-02"`
+	MaxUses        *int   `json:"max_uses" validate:"omitempty,gte=0"`
+	MaxUsesPerUser *int   `json:"max_uses_per_user" validate:"omitempty,gte=0"`
+}
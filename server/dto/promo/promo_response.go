@@ -0,0 +1,18 @@
+package promodto
+
+type PromoCodeResponse struct {
+	ID             int    `json:"id"`
+	Code           string `json:"code"`
+	Description    string `json:"description"`
+	Kind           string `json:"kind"`
+	Amount         int    `json:"amount"`
+	MaxDiscount    int    `json:"max_discount"`
+	MinSpend       int    `json:"min_spend"`
+	ValidFrom      string `json:"valid_from,omitempty"`
+	ValidUntil     string `json:"valid_until,omitempty"`
+	MaxUses        int    `json:"max_uses"`
+	MaxUsesPerUser int    `json:"max_uses_per_user"`
+	Uses           int    `json:"uses"`
+	HouseId        int    `json:"house_id,omitempty"`
+	CityName       string `json:"cityname,omitempty"`
+}
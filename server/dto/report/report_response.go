@@ -0,0 +1,47 @@
+package reportdto
+
+type RevenueResponse struct {
+	Month    string `json:"month"`
+	Revenue  int    `json:"revenue"`
+	Bookings int    `json:"bookings"`
+}
+
+type OccupancyResponse struct {
+	HouseId         int     `json:"house_id"`
+	HouseName       string  `json:"house_name"`
+	BookedNights    int     `json:"booked_nights"`
+	AvailableNights int     `json:"available_nights"`
+	OccupancyRate   float64 `json:"occupancy_rate"`
+}
+
+type LengthOfStayResponse struct {
+	HouseId       int     `json:"house_id"`
+	HouseName     string  `json:"house_name"`
+	Bookings      int     `json:"bookings"`
+	AverageNights float64 `json:"average_nights"`
+}
+
+type LengthOfStaySummary struct {
+	Bookings      int                    `json:"bookings"`
+	AverageNights float64                `json:"average_nights"`
+	Houses        []LengthOfStayResponse `json:"houses"`
+}
+
+type StatusCount struct {
+	StatusPayment string `json:"status_payment"`
+	Total         int    `json:"total"`
+}
+
+type ConversionResponse struct {
+	Total          int     `json:"total"`
+	Pending        int     `json:"pending"`
+	Success        int     `json:"success"`
+	Failed         int     `json:"failed"`
+	ConversionRate float64 `json:"conversion_rate"`
+}
+
+type CancellationResponse struct {
+	Month         string `json:"month"`
+	Cancellations int    `json:"cancellations"`
+	LostRevenue   int    `json:"lost_revenue"`
+}
@@ -1,11 +1,66 @@
 package dto
 
+import "net/http"
+
 type SuccessResult struct {
 	Code int         `json:"code"`
 	Data interface{} `json:"data"`
 }
 
+// ErrorResult is the body of every error response. Code repeats the HTTP
+// status, Error is a stable machine-readable code clients can switch on and
+// Fields lists the invalid fields of a validation error.
 type ErrorResult struct {
-	Code    int    `json:"code"`
+	Code    int          `json:"code"`
+	Error   string       `json:"error"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"`
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
 	Message string `json:"message"`
-}
\ No newline at end of file
+}
+
+const (
+	ErrBadRequest       = "bad_request"
+	ErrValidation       = "validation_failed"
+	ErrUnauthorized     = "unauthorized"
+	ErrForbidden        = "forbidden"
+	ErrNotFound         = "not_found"
+	ErrConflict         = "conflict"
+	ErrPayloadTooLarge  = "payload_too_large"
+	ErrInternal         = "internal_error"
+	ErrBadGateway       = "bad_gateway"
+	ErrUnavailable      = "unavailable"
+	ErrMethodNotAllowed = "method_not_allowed"
+)
+
+var statusErrors = map[int]string{
+	http.StatusBadRequest:            ErrBadRequest,
+	http.StatusUnprocessableEntity:   ErrValidation,
+	http.StatusUnauthorized:          ErrUnauthorized,
+	http.StatusForbidden:             ErrForbidden,
+	http.StatusNotFound:              ErrNotFound,
+	http.StatusMethodNotAllowed:      ErrMethodNotAllowed,
+	http.StatusConflict:              ErrConflict,
+	http.StatusRequestEntityTooLarge: ErrPayloadTooLarge,
+	http.StatusInternalServerError:   ErrInternal,
+	http.StatusBadGateway:            ErrBadGateway,
+	http.StatusServiceUnavailable:    ErrUnavailable,
+}
+
+// NewError builds the error result of a status, with the error code
+// matching it.
+func NewError(status int, message string) ErrorResult {
+	code, ok := statusErrors[status]
+	if !ok {
+		code = ErrInternal
+		if status < http.StatusInternalServerError {
+			code = ErrBadRequest
+		}
+	}
+	return ErrorResult{Code: status, Error: code, Message: message}
+}
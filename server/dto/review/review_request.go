@@ -0,0 +1,17 @@
+package reviewdto
+
+type ReviewRequest struct {
+	Cleanliness int    `json:"cleanliness" validate:"required,min=1,max=5"`
+	Accuracy    int    `json:"accuracy" validate:"required,min=1,max=5"`
+	Location    int    `json:"location" validate:"required,min=1,max=5"`
+	Value       int    `json:"value" validate:"required,min=1,max=5"`
+	Comment     string `json:"comment" validate:"max=5000"`
+}
+
+type ReplyRequest struct {
+	Reply string `json:"reply" validate:"required,max=5000"`
+}
+
+type HideRequest struct {
+	Reason string `json:"reason" validate:"required,max=255"`
+}
@@ -0,0 +1,22 @@
+package reviewdto
+
+import "time"
+
+type ReviewResponse struct {
+	ID            int        `json:"id"`
+	HouseId       int        `json:"house_id"`
+	TransactionId int        `json:"transaction_id"`
+	UserId        int        `json:"user_id"`
+	Fullname      string     `json:"fullname"`
+	Cleanliness   int        `json:"cleanliness"`
+	Accuracy      int        `json:"accuracy"`
+	Location      int        `json:"location"`
+	Value         int        `json:"value"`
+	Rating        float64    `json:"rating"`
+	Comment       string     `json:"comment"`
+	Reply         string     `json:"reply,omitempty"`
+	RepliedAt     *time.Time `json:"replied_at,omitempty"`
+	Hidden        bool       `json:"hidden,omitempty"`
+	HiddenReason  string     `json:"hidden_reason,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
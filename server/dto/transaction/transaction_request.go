@@ -1,11 +1,14 @@
 package transactiondto
 
+// RequestTransaction books a house for the signed in user. The server prices
+// the stay, less the promo code if any, Total is optional and only checked
+// against that price when given. The booking is pending until the payment
+// gateway says otherwise.
 type RequestTransaction struct {
-	CheckIn       string `json:"check_in" gorm:"type: varchar(255)" validate:"required"`
-	CheckOut      string `json:"check_out" gorm:"type: varchar(255)" validate:"required"`
-	HouseId       int    `json:"house_id" gorm:"type: int"`
-	UserId        int    `json:"user_id" gorm:"type: int"`
-	Total         int    `json:"total" gorm:"type: int" validate:"required"`
-	StatusPayment string `json:"status_payment" gorm:"type: varchar(255)" validate:"required"`
-	Attachment    string `json:"attachment" gorm:"type: varchar(255)"`
+	CheckIn    string `json:"check_in" gorm:"type: varchar(255)" validate:"required,datetime=2006-01-02"`
+	CheckOut   string `json:"check_out" gorm:"type: varchar(255)" validate:"required,datetime=2006-01-02"`
+	HouseId    int    `json:"house_id" gorm:"type: int"`
+	Total      int    `json:"total" gorm:"type: int" validate:"gte=0"`
+	PromoCode  string `json:"promo_code" validate:"max=64"`
+	Attachment string `json:"attachment" gorm:"type: varchar(255)"`
 }
@@ -1,11 +1,24 @@
 package transactiondto
 
 type ResponseTransaction struct {
-	CheckIn       string `json:"check_in" gorm:"type: varchar(255)" validate:"required"`
-	CheckOut      string `json:"check_out" gorm:"type: varchar(255)" validate:"required"`
-	HouseId       int    `json:"house_id" gorm:"type: int" validate:"required"`
-	UserId        int    `json:"user_id" gorm:"type: int" validate:"required"`
-	Total         int    `json:"total" gorm:"type: int" validate:"required"`
-	StatusPayment string `json:"status_payment" gorm:"type: varchar(255)" validate:"required"`
-	Attachment    string `json:"attachment" gorm:"type: varchar(255)" validate:"required"`
+	CheckIn       string         `json:"check_in" gorm:"type: varchar(255)" validate:"required"`
+	CheckOut      string         `json:"check_out" gorm:"type: varchar(255)" validate:"required"`
+	HouseId       int            `json:"house_id" gorm:"type: int" validate:"required"`
+	UserId        int            `json:"user_id" gorm:"type: int" validate:"required"`
+	Total         int            `json:"total" gorm:"type: int" validate:"required"`
+	PromoCode     string         `json:"promo_code,omitempty"`
+	PromoDiscount int            `json:"promo_discount"`
+	OwnerPayout   int            `json:"owner_payout"`
+	Items         []ItemResponse `json:"items"`
+	StatusPayment string         `json:"status_payment" gorm:"type: varchar(255)" validate:"required"`
+	Attachment    string         `json:"attachment" gorm:"type: varchar(255)" validate:"required"`
+}
+
+// ItemResponse is a line of the bill of a booking, discounts are negative.
+type ItemResponse struct {
+	Kind        string `json:"kind"`
+	Description string `json:"description"`
+	Quantity    int    `json:"quantity"`
+	UnitPrice   int    `json:"unit_price"`
+	Amount      int    `json:"amount"`
 }
@@ -0,0 +1,5 @@
+package wishlistdto
+
+type WishlistRequest struct {
+	Name string `json:"name" validate:"required,max=255"`
+}
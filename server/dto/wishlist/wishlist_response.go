@@ -0,0 +1,13 @@
+package wishlistdto
+
+import (
+	housesdto "housy/dto/house"
+	"time"
+)
+
+type WishlistResponse struct {
+	ID        int                       `json:"id"`
+	Name      string                    `json:"name"`
+	Houses    []housesdto.ResponseHouse `json:"houses"`
+	CreatedAt time.Time                 `json:"created_at"`
+}
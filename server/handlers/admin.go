@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	dto "housy/dto/result"
+	"housy/pkg/search"
+	"housy/repositories"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type handlerAdmin struct {
+	HouseRepository       repositories.HouseRepository
+	UserRepository        repositories.UserRepository
+	TransactionRepository repositories.TransactionRepository
+	Search                search.Index
+}
+
+func HandlerAdmin(HouseRepository repositories.HouseRepository, UserRepository repositories.UserRepository, TransactionRepository repositories.TransactionRepository, Search search.Index) *handlerAdmin {
+	return &handlerAdmin{HouseRepository, UserRepository, TransactionRepository, Search}
+}
+
+func (h *handlerAdmin) RestoreHouse(w http.ResponseWriter, r *http.Request) {
+	h.restore(w, r, func(id int) (interface{}, error) {
+		house, err := h.HouseRepository.RestoreHouse(r.Context(), id)
+		if err == nil {
+			indexHouses(r.Context(), h.Search, house)
+		}
+		return convertResponseHouse(house), err
+	})
+}
+
+func (h *handlerAdmin) RestoreUser(w http.ResponseWriter, r *http.Request) {
+	h.restore(w, r, func(id int) (interface{}, error) {
+		user, err := h.UserRepository.RestoreUser(r.Context(), id)
+		return convertResponse(user), err
+	})
+}
+
+func (h *handlerAdmin) RestoreTransaction(w http.ResponseWriter, r *http.Request) {
+	h.restore(w, r, func(id int) (interface{}, error) {
+		transaction, err := h.TransactionRepository.RestoreTransaction(r.Context(), id)
+		return convertResponseTransaction(transaction), err
+	})
+}
+
+func (h *handlerAdmin) PurgeHouse(w http.ResponseWriter, r *http.Request) {
+	h.purge(w, r, func(ctx context.Context, id int) error {
+		if err := h.HouseRepository.PurgeHouse(ctx, id); err != nil {
+			return err
+		}
+		unindexHouses(ctx, h.Search, id)
+		return nil
+	})
+}
+
+func (h *handlerAdmin) PurgeUser(w http.ResponseWriter, r *http.Request) {
+	h.purge(w, r, h.UserRepository.PurgeUser)
+}
+
+func (h *handlerAdmin) PurgeTransaction(w http.ResponseWriter, r *http.Request) {
+	h.purge(w, r, h.TransactionRepository.PurgeTransaction)
+}
+
+func (h *handlerAdmin) restore(w http.ResponseWriter, r *http.Request, restore func(id int) (interface{}, error)) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if _, ok := requireAdmin(w, r, h.UserRepository); !ok {
+		return
+	}
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	data, err := restore(id)
+	if err != nil {
+		writeLookupError(w, r, err, "record")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: data}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *handlerAdmin) purge(w http.ResponseWriter, r *http.Request, purge func(ctx context.Context, id int) error) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if _, ok := requireAdmin(w, r, h.UserRepository); !ok {
+		return
+	}
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	err := purge(r.Context(), id)
+	if errors.Is(err, repositories.ErrHasTransactions) || errors.Is(err, repositories.ErrHasInvoice) {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: map[string]int{"id": id}}
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	amenitydto "housy/dto/amenity"
+	dto "housy/dto/result"
+	"housy/models"
+	"housy/repositories"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+type handlerAmenity struct {
+	AmenityRepository repositories.AmenityRepository
+	UserRepository    repositories.UserRepository
+}
+
+func HandlerAmenity(AmenityRepository repositories.AmenityRepository, UserRepository repositories.UserRepository) *handlerAmenity {
+	return &handlerAmenity{AmenityRepository, UserRepository}
+}
+
+func (h *handlerAmenity) FindAmenities(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	amenities, err := h.AmenityRepository.FindAmenities(r.Context())
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponseAmenities(amenities)}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *handlerAmenity) CreateAmenity(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if _, ok := requireAdmin(w, r, h.UserRepository); !ok {
+		return
+	}
+
+	request := new(amenitydto.AmenityRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	if err := validate.Struct(request); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+
+	existing, err := h.AmenityRepository.FindAmenitiesByCode(r.Context(), []string{request.Code})
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	if len(existing) > 0 {
+		writeError(w, http.StatusConflict, "amenity code "+request.Code+" already exists")
+		return
+	}
+
+	amenity, err := h.AmenityRepository.CreateAmenity(r.Context(), models.Amenity{
+		Code:     request.Code,
+		Label:    request.Label,
+		Icon:     request.Icon,
+		Category: request.Category,
+	})
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponseAmenity(amenity)}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *handlerAmenity) UpdateAmenity(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if _, ok := requireAdmin(w, r, h.UserRepository); !ok {
+		return
+	}
+
+	request := new(amenitydto.UpdateAmenityRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	if err := validate.Struct(request); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	amenity, err := h.AmenityRepository.GetAmenity(r.Context(), id)
+	if err != nil {
+		writeLookupError(w, r, err, "amenity")
+		return
+	}
+
+	if request.Label != "" {
+		amenity.Label = request.Label
+	}
+
+	if request.Icon != "" {
+		amenity.Icon = request.Icon
+	}
+
+	if request.Category != "" {
+		amenity.Category = request.Category
+	}
+
+	amenity, err = h.AmenityRepository.UpdateAmenity(r.Context(), amenity)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponseAmenity(amenity)}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *handlerAmenity) DeleteAmenity(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if _, ok := requireAdmin(w, r, h.UserRepository); !ok {
+		return
+	}
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	if _, err := h.AmenityRepository.GetAmenity(r.Context(), id); err != nil {
+		writeLookupError(w, r, err, "amenity")
+		return
+	}
+
+	err := h.AmenityRepository.DeleteAmenity(r.Context(), id)
+	if errors.Is(err, repositories.ErrAmenityInUse) {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: map[string]int{"id": id}}
+	json.NewEncoder(w).Encode(response)
+}
+
+// amenityCodes reads a list of amenity codes given as a JSON array or comma
+// separated, nil when value is empty. A malformed array is read as a comma
+// separated list, its parts are then reported as unknown codes.
+func amenityCodes(value string) []string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+
+	var codes []string
+	if strings.HasPrefix(value, "[") && json.Unmarshal([]byte(value), &codes) == nil {
+		if codes == nil {
+			codes = []string{}
+		}
+		return codes
+	}
+
+	for _, code := range strings.Split(value, ",") {
+		codes = append(codes, strings.TrimSpace(code))
+	}
+	return codes
+}
+
+// resolveAmenities looks the codes up in the catalog. It writes a 422
+// naming the unknown codes, or a 500, and returns false when it fails.
+func resolveAmenities(w http.ResponseWriter, r *http.Request, AmenityRepository repositories.AmenityRepository, codes []string) ([]models.Amenity, bool) {
+	if len(codes) == 0 {
+		return []models.Amenity{}, true
+	}
+
+	amenities, err := AmenityRepository.FindAmenitiesByCode(r.Context(), codes)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return nil, false
+	}
+
+	if unknown := unknownAmenities(amenities, codes); len(unknown) > 0 {
+		writeFieldErrors(w, amenitiesFieldError(unknown))
+		return nil, false
+	}
+
+	return amenities, true
+}
+
+// unknownAmenities returns the codes, once each, that none of the amenities
+// has.
+func unknownAmenities(amenities []models.Amenity, codes []string) []string {
+	known := make(map[string]bool)
+	for _, amenity := range amenities {
+		known[amenity.Code] = true
+	}
+
+	var unknown []string
+	for _, code := range codes {
+		if !known[code] {
+			unknown = append(unknown, code)
+			known[code] = true
+		}
+	}
+	return unknown
+}
+
+func amenitiesFieldError(unknown []string) dto.FieldError {
+	return dto.FieldError{
+		Field:   "amenities",
+		Rule:    "exists",
+		Message: "has unknown amenity codes: " + strings.Join(unknown, ", "),
+	}
+}
+
+func amenityCodesOf(amenities []models.Amenity) []string {
+	codes := make([]string, 0, len(amenities))
+	for _, amenity := range amenities {
+		codes = append(codes, amenity.Code)
+	}
+	return codes
+}
+
+func convertResponseAmenities(amenities []models.Amenity) []amenitydto.AmenityResponse {
+	responses := make([]amenitydto.AmenityResponse, 0, len(amenities))
+	for _, amenity := range amenities {
+		responses = append(responses, convertResponseAmenity(amenity))
+	}
+	return responses
+}
+
+func convertResponseAmenity(u models.Amenity) amenitydto.AmenityResponse {
+	return amenitydto.AmenityResponse{
+		ID:       u.ID,
+		Code:     u.Code,
+		Label:    u.Label,
+		Icon:     u.Icon,
+		Category: u.Category,
+	}
+}
@@ -2,27 +2,27 @@ package handlers
 
 import (
 	"encoding/json"
-	"fmt"
+	"errors"
 	authdto "housy/dto/auth"
 	dto "housy/dto/result"
 	"housy/models"
 	"housy/pkg/bcrypt"
 	jwtToken "housy/pkg/jwt"
 	"housy/repositories"
-	"log"
 	"net/http"
 	"time"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/golang-jwt/jwt/v4"
+	"gorm.io/gorm"
 )
 
 type handlerAuth struct {
 	AuthRepository repositories.AuthRepository
+	SecretKey      string
 }
 
-func HandlerAuth(AuthRepository repositories.AuthRepository) *handlerAuth {
-	return &handlerAuth{AuthRepository}
+func HandlerAuth(AuthRepository repositories.AuthRepository, SecretKey string) *handlerAuth {
+	return &handlerAuth{AuthRepository, SecretKey}
 }
 
 func (h *handlerAuth) SignUp(w http.ResponseWriter, r *http.Request) {
@@ -30,26 +30,19 @@ func (h *handlerAuth) SignUp(w http.ResponseWriter, r *http.Request) {
 
 	request := new(authdto.SignUpRequest)
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
-		json.NewEncoder(w).Encode(response)
+		writeDecodeError(w, err)
 		return
 	}
 
-	validation := validator.New()
-	err := validation.Struct(request)
-	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
-		json.NewEncoder(w).Encode(response)
+	if err := validate.Struct(request); err != nil {
+		writeValidationError(w, err)
 		return
 	}
 
 	password, err := bcrypt.HashingPassword(request.Password)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
-		json.NewEncoder(w).Encode(response)
+		writeInternalError(w, r, err)
+		return
 	}
 
 	user := models.User{
@@ -63,11 +56,10 @@ func (h *handlerAuth) SignUp(w http.ResponseWriter, r *http.Request) {
 		Address:    request.Address,
 	}
 
-	data, err := h.AuthRepository.SignUp(user)
+	data, err := h.AuthRepository.SignUp(r.Context(), user)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
-		json.NewEncoder(w).Encode(response)
+		writeInternalError(w, r, err)
+		return
 	}
 
 	signUpResponse := authdto.SignUpResponse{
@@ -93,9 +85,12 @@ func (h *handlerAuth) SignIn(w http.ResponseWriter, r *http.Request) {
 
 	request := new(authdto.SignInRequest)
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
-		json.NewEncoder(w).Encode(response)
+		writeDecodeError(w, err)
+		return
+	}
+
+	if err := validate.Struct(request); err != nil {
+		writeValidationError(w, err)
 		return
 	}
 
@@ -106,20 +101,20 @@ func (h *handlerAuth) SignIn(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check username
-	user, err := h.AuthRepository.SignIn(user.Username)
+	user, err := h.AuthRepository.SignIn(r.Context(), user.Username)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		writeError(w, http.StatusUnauthorized, "wrong username or password")
+		return
+	}
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
-		json.NewEncoder(w).Encode(response)
+		writeInternalError(w, r, err)
 		return
 	}
 
 	// Check password
 	isValid := bcrypt.CheckPasswordHash(request.Password, user.Password)
 	if !isValid {
-		w.WriteHeader(http.StatusBadRequest)
-		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: "wrong username or password"}
-		json.NewEncoder(w).Encode(response)
+		writeError(w, http.StatusUnauthorized, "wrong username or password")
 		return
 	}
 
@@ -128,10 +123,9 @@ func (h *handlerAuth) SignIn(w http.ResponseWriter, r *http.Request) {
 	claims["id"] = user.ID
 	claims["exp"] = time.Now().Add(time.Hour * 2).Unix() // 2 hours expired
 
-	token, errGenerateToken := jwtToken.GenerateToken(&claims)
+	token, errGenerateToken := jwtToken.GenerateToken(&claims, h.SecretKey)
 	if errGenerateToken != nil {
-		log.Println(errGenerateToken)
-		fmt.Println("Unauthorize")
+		writeInternalError(w, r, errGenerateToken)
 		return
 	}
 
@@ -161,11 +155,9 @@ func (h *handlerAuth) CheckAuth(w http.ResponseWriter, r *http.Request) {
 	userId := int(userInfo["id"].(float64))
 
 	// Check User by Id
-	user, err := h.AuthRepository.Getuser(userId)
+	user, err := h.AuthRepository.Getuser(r.Context(), userId)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
-		json.NewEncoder(w).Encode(response)
+		writeLookupError(w, r, err, "user")
 		return
 	}
 
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"encoding/json"
+	"housy/openapi"
+	"net/http"
+)
+
+// docsPage renders openapi.json, next to it, with Swagger UI.
+const docsPage = `<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8" />
+  <title>Housy API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css" />
+</head>
+<body>
+  <div id="docs"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    SwaggerUIBundle({ url: "openapi.json", dom_id: "#docs", persistAuthorization: true });
+  </script>
+</body>
+</html>
+`
+
+type handlerDocs struct {
+	spec []byte
+}
+
+// HandlerDocs encodes the document once, it doesn't change while serving.
+// Encoding can't fail, the document is made of plain structs and maps.
+func HandlerDocs(Spec *openapi.Document) *handlerDocs {
+	spec, _ := json.Marshal(Spec)
+	return &handlerDocs{spec}
+}
+
+func (h *handlerDocs) OpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(h.spec)
+}
+
+func (h *handlerDocs) Docs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(docsPage))
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	dto "housy/dto/result"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+// validate checks request DTOs. Field errors are reported with the JSON
+// name of the field, the one clients know.
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" || name == "" {
+			return field.Name
+		}
+		return name
+	})
+	v.RegisterValidation("code", func(field validator.FieldLevel) bool {
+		return codePattern.MatchString(field.Field().String())
+	})
+	v.RegisterValidation("promo_code", func(field validator.FieldLevel) bool {
+		return promoCodePattern.MatchString(field.Field().String())
+	})
+	return v
+}
+
+// codePattern is the format of catalog codes such as amenity codes.
+var codePattern = regexp.MustCompile(`^[a-z0-9]+(_[a-z0-9]+)*$`)
+
+// promoCodePattern is the format of promo codes, they are stored uppercase.
+var promoCodePattern = regexp.MustCompile(`^[A-Za-z0-9]+([-_][A-Za-z0-9]+)*$`)
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(dto.NewError(status, message))
+}
+
+// NotFound answers requests no route matches.
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	writeError(w, http.StatusNotFound, "no route matches "+r.URL.Path)
+}
+
+// MethodNotAllowed answers requests to a route with an unsupported method.
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	writeError(w, http.StatusMethodNotAllowed, r.Method+" is not allowed on "+r.URL.Path)
+}
+
+// writeInternalError logs err and answers 500 without leaking its details.
+func writeInternalError(w http.ResponseWriter, r *http.Request, err error) {
+	slog.ErrorContext(r.Context(), "request failed", "method", r.Method, "path", r.URL.Path, "error", err)
+	writeError(w, http.StatusInternalServerError, "internal server error")
+}
+
+// writeLookupError answers 404 when the record doesn't exist and 500 for
+// any other database error.
+func writeLookupError(w http.ResponseWriter, r *http.Request, err error, what string) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		writeError(w, http.StatusNotFound, what+" not found")
+		return
+	}
+	writeInternalError(w, r, err)
+}
+
+// writeValidationError answers 422 with one entry per invalid field. Errors
+// that aren't validator.ValidationErrors get a 400.
+func writeValidationError(w http.ResponseWriter, err error) {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeFieldErrors(w, fieldErrors(validationErrors)...)
+}
+
+// writeFieldErrors answers 422 for fields found invalid outside the
+// validator, e.g. values that must exist in the database.
+func writeFieldErrors(w http.ResponseWriter, fields ...dto.FieldError) {
+	response := dto.NewError(http.StatusUnprocessableEntity, "the request has invalid fields")
+	response.Fields = fields
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(response)
+}
+
+// writeDecodeError answers 400 for a request body that isn't valid JSON.
+func writeDecodeError(w http.ResponseWriter, err error) {
+	writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+}
+
+func fieldErrors(validationErrors validator.ValidationErrors) []dto.FieldError {
+	fields := make([]dto.FieldError, 0, len(validationErrors))
+	for _, fieldError := range validationErrors {
+		fields = append(fields, dto.FieldError{
+			Field:   fieldError.Field(),
+			Rule:    fieldError.Tag(),
+			Param:   fieldError.Param(),
+			Message: fieldMessage(fieldError),
+		})
+	}
+	return fields
+}
+
+func fieldMessage(fieldError validator.FieldError) string {
+	param := fieldError.Param()
+	switch fieldError.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "min", "gte":
+		if fieldError.Kind() == reflect.String {
+			return fmt.Sprintf("must be at least %s characters long", param)
+		}
+		return "must be at least " + param
+	case "max", "lte":
+		if fieldError.Kind() == reflect.String {
+			return fmt.Sprintf("must be at most %s characters long", param)
+		}
+		return "must be at most " + param
+	case "gt":
+		return "must be greater than " + param
+	case "lt":
+		return "must be less than " + param
+	case "required_with":
+		return "is required with " + strings.ToLower(param)
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(param, " ", ", ")
+	case "len":
+		return "must have a length of " + param
+	case "numeric", "number":
+		return "must be a number"
+	case "url":
+		return "must be a valid url"
+	case "datetime":
+		return "must be a date formatted as " + param
+	case "code":
+		return "must be lowercase letters and digits separated by underscores"
+	case "promo_code":
+		return "must be letters and digits separated by dashes or underscores"
+	}
+	return fmt.Sprintf("failed on the %s rule", fieldError.Tag())
+}
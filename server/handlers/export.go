@@ -0,0 +1,415 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	exportdto "housy/dto/export"
+	dto "housy/dto/result"
+	"housy/models"
+	"housy/pkg/export"
+	"housy/repositories"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// exportSyncLimit is the largest export streamed directly in the response,
+// bigger ones are generated by a background job.
+const exportSyncLimit = 5000
+
+const exportDir = "exports"
+
+var houseColumns = []string{"id", "name", "cityname", "address", "latitude", "longitude", "price", "cleaning_fee", "deposit", "type_rent", "amenities", "bedroom", "bathroom", "area", "description", "owner_id", "created_at", "updated_at"}
+
+var transactionColumns = []string{"id", "check_in", "check_out", "house_id", "house_name", "user_id", "user_fullname", "user_email", "total", "status_payment", "created_at", "updated_at"}
+
+type handlerExport struct {
+	HouseRepository       repositories.HouseRepository
+	TransactionRepository repositories.TransactionRepository
+	ExportJobRepository   repositories.ExportJobRepository
+	UserRepository        repositories.UserRepository
+	jobs                  chan int
+}
+
+func HandlerExport(HouseRepository repositories.HouseRepository, TransactionRepository repositories.TransactionRepository, ExportJobRepository repositories.ExportJobRepository, UserRepository repositories.UserRepository) *handlerExport {
+	return &handlerExport{HouseRepository, TransactionRepository, ExportJobRepository, UserRepository, make(chan int, 100)}
+}
+
+type exportOptions struct {
+	format   string
+	columns  []string
+	location *time.Location
+}
+
+func (h *handlerExport) ExportHouses(w http.ResponseWriter, r *http.Request) {
+	h.export(w, r, "houses")
+}
+
+func (h *handlerExport) ExportTransactions(w http.ResponseWriter, r *http.Request) {
+	h.export(w, r, "transactions")
+}
+
+func (h *handlerExport) export(w http.ResponseWriter, r *http.Request, resource string) {
+	user, ok := requireAdmin(w, r, h.UserRepository)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	options, err := parseExportOptions(resource, query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var count int64
+	if resource == "houses" {
+		var filter repositories.HouseFilter
+		if filter, err = houseFilter(query); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		count, err = h.HouseRepository.CountHouses(r.Context(), filter)
+	} else {
+		count, err = h.TransactionRepository.CountTransactions(r.Context(), transactionFilter(query))
+	}
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	if query.Get("async") == "true" || count > exportSyncLimit {
+		job, err := h.ExportJobRepository.CreateExportJob(r.Context(), models.ExportJob{
+			UserId:   user.ID,
+			Resource: resource,
+			Format:   options.format,
+			Query:    query.Encode(),
+			Status:   "pending",
+		})
+		if err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+		// a job that doesn't fit in the queue stays pending until the
+		// worker looks for unfinished jobs again
+		select {
+		case h.jobs <- job.ID:
+		default:
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		response := dto.SuccessResult{Code: http.StatusAccepted, Data: convertResponseExportJob(job)}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	w.Header().Set("Content-Type", export.ContentType(options.format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s.%s"`, resource, time.Now().In(options.location).Format("20060102-150405"), options.format))
+	w.WriteHeader(http.StatusOK)
+
+	if err := h.write(r.Context(), w, resource, query, options); err != nil {
+		// the status line is already sent, all we can do is log and cut the stream short
+		slog.ErrorContext(r.Context(), "export failed", "resource", resource, "error", err)
+	}
+}
+
+func (h *handlerExport) GetExportJob(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	job, ok := h.exportJob(w, r)
+	if !ok {
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponseExportJob(job)}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *handlerExport) DownloadExport(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.exportJob(w, r)
+	if !ok {
+		return
+	}
+
+	if job.Status != "done" {
+		writeError(w, http.StatusConflict, "export is "+job.Status)
+		return
+	}
+
+	w.Header().Set("Content-Type", export.ContentType(job.Format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%d.%s"`, job.Resource, job.ID, job.Format))
+	http.ServeFile(w, r, job.File)
+}
+
+// exportJob loads the job from the URL and checks it belongs to the caller.
+func (h *handlerExport) exportJob(w http.ResponseWriter, r *http.Request) (models.ExportJob, bool) {
+	user, ok := requireAdmin(w, r, h.UserRepository)
+	if !ok {
+		return models.ExportJob{}, false
+	}
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	job, err := h.ExportJobRepository.GetExportJob(r.Context(), id)
+	if err != nil || job.UserId != user.ID {
+		writeError(w, http.StatusNotFound, "export not found")
+		return job, false
+	}
+
+	return job, true
+}
+
+// QueueDepth is the number of exports waiting for the worker.
+func (h *handlerExport) QueueDepth() int {
+	return len(h.jobs)
+}
+
+// RunJobs generates the queued exports until ctx is done. Jobs left
+// unfinished by a previous shutdown are picked up first, and every minute
+// the pending jobs that didn't fit in the queue. A job already running when
+// ctx is done is finished, the queued ones stay pending.
+func (h *handlerExport) RunJobs(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	h.runUnfinishedJobs(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.runUnfinishedJobs(ctx)
+		case id := <-h.jobs:
+			h.runJob(context.WithoutCancel(ctx), id)
+		}
+	}
+}
+
+func (h *handlerExport) runUnfinishedJobs(ctx context.Context) {
+	unfinished, err := h.ExportJobRepository.FindUnfinishedExportJobs(ctx)
+	if err != nil {
+		slog.Error("loading unfinished exports failed", "error", err)
+	}
+	for _, job := range unfinished {
+		if ctx.Err() != nil {
+			return
+		}
+		h.runJob(context.WithoutCancel(ctx), job.ID)
+	}
+}
+
+func (h *handlerExport) runJob(ctx context.Context, id int) {
+	job, err := h.ExportJobRepository.GetExportJob(ctx, id)
+	if err != nil {
+		slog.Error("loading export job failed", "job_id", id, "error", err)
+		return
+	}
+	// a job queued while the unfinished ones were loaded comes twice
+	if job.Status == "done" || job.Status == "failed" {
+		return
+	}
+
+	job.Status = "running"
+	job, _ = h.ExportJobRepository.UpdateExportJob(ctx, job)
+
+	job.File = filepath.Join(exportDir, fmt.Sprintf("export-%d.%s", job.ID, job.Format))
+	err = h.writeFile(ctx, job)
+	if err != nil {
+		job.Status = "failed"
+		job.Error = err.Error()
+		os.Remove(job.File)
+	} else {
+		job.Status = "done"
+	}
+
+	if _, err := h.ExportJobRepository.UpdateExportJob(ctx, job); err != nil {
+		slog.Error("saving export job failed", "job_id", job.ID, "error", err)
+		return
+	}
+	slog.Info("export job finished", "job_id", job.ID, "resource", job.Resource, "status", job.Status)
+}
+
+func (h *handlerExport) writeFile(ctx context.Context, job models.ExportJob) error {
+	query, err := url.ParseQuery(job.Query)
+	if err != nil {
+		return err
+	}
+
+	options, err := parseExportOptions(job.Resource, query)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(exportDir, 0755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(job.File)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return h.write(ctx, file, job.Resource, query, options)
+}
+
+func (h *handlerExport) write(ctx context.Context, out io.Writer, resource string, query url.Values, options exportOptions) error {
+	writer, err := export.NewWriter(options.format, out)
+	if err != nil {
+		return err
+	}
+
+	if err := writer.Write(options.columns); err != nil {
+		return err
+	}
+
+	row := func(record map[string]string) []string {
+		values := make([]string, len(options.columns))
+		for i, column := range options.columns {
+			values[i] = record[column]
+		}
+		return values
+	}
+
+	if resource == "houses" {
+		var filter repositories.HouseFilter
+		if filter, err = houseFilter(query); err != nil {
+			return err
+		}
+		err = h.HouseRepository.EachHouse(ctx, filter, func(house models.House) error {
+			return writer.Write(row(houseRecord(house, options.location)))
+		})
+	} else {
+		err = h.TransactionRepository.EachTransaction(ctx, transactionFilter(query), func(transaction models.Transaction) error {
+			return writer.Write(row(transactionRecord(transaction, options.location)))
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
+// parseExportOptions reads the format, columns and tz query parameters.
+func parseExportOptions(resource string, query url.Values) (exportOptions, error) {
+	options := exportOptions{format: export.FormatCSV, location: time.UTC}
+
+	if format := query.Get("format"); format != "" {
+		if format != export.FormatCSV && format != export.FormatXLSX {
+			return options, errors.New("format must be csv or xlsx")
+		}
+		options.format = format
+	}
+
+	available := houseColumns
+	if resource == "transactions" {
+		available = transactionColumns
+	}
+	options.columns = available
+
+	if columns := query.Get("columns"); columns != "" {
+		options.columns = nil
+		for _, column := range strings.Split(columns, ",") {
+			column = strings.TrimSpace(column)
+			if !containsString(available, column) {
+				return options, fmt.Errorf("unknown column %q, available columns are %s", column, strings.Join(available, ","))
+			}
+			options.columns = append(options.columns, column)
+		}
+	}
+
+	if tz := query.Get("tz"); tz != "" {
+		location, err := time.LoadLocation(tz)
+		if err != nil {
+			return options, fmt.Errorf("unknown timezone %q", tz)
+		}
+		options.location = location
+	}
+
+	return options, nil
+}
+
+func houseRecord(house models.House, location *time.Location) map[string]string {
+	return map[string]string{
+		"id":           strconv.Itoa(house.ID),
+		"name":         house.Name,
+		"cityname":     house.CityName,
+		"address":      house.Address,
+		"latitude":     formatCoordinate(house.Latitude),
+		"longitude":    formatCoordinate(house.Longitude),
+		"price":        strconv.Itoa(house.Price),
+		"cleaning_fee": strconv.Itoa(house.CleaningFee),
+		"deposit":      strconv.Itoa(house.Deposit),
+		"type_rent":    house.TypeRent,
+		"amenities":    strings.Join(amenityCodesOf(house.Amenities), ","),
+		"bedroom":      strconv.Itoa(house.Bedroom),
+		"bathroom":     strconv.Itoa(house.Bathroom),
+		"area":         house.Area,
+		"description":  house.Description,
+		"owner_id":     strconv.Itoa(house.OwnerId),
+		"created_at":   house.CreatedAt.In(location).Format(time.RFC3339),
+		"updated_at":   house.UpdatedAt.In(location).Format(time.RFC3339),
+	}
+}
+
+// formatCoordinate writes a house coordinate, empty when the house isn't
+// located.
+func formatCoordinate(coordinate *float64) string {
+	if coordinate == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*coordinate, 'f', -1, 64)
+}
+
+func transactionRecord(transaction models.Transaction, location *time.Location) map[string]string {
+	return map[string]string{
+		"id":             strconv.Itoa(transaction.ID),
+		"check_in":       transaction.CheckIn,
+		"check_out":      transaction.CheckOut,
+		"house_id":       strconv.Itoa(transaction.HouseId),
+		"house_name":     transaction.House.Name,
+		"user_id":        strconv.Itoa(transaction.UserId),
+		"user_fullname":  transaction.User.Fullname,
+		"user_email":     transaction.User.Email,
+		"total":          strconv.Itoa(transaction.Total),
+		"status_payment": transaction.StatusPayment,
+		"created_at":     transaction.CreatedAt.In(location).Format(time.RFC3339),
+		"updated_at":     transaction.UpdatedAt.In(location).Format(time.RFC3339),
+	}
+}
+
+func convertResponseExportJob(job models.ExportJob) exportdto.ExportJobResponse {
+	response := exportdto.ExportJobResponse{
+		ID:        job.ID,
+		Resource:  job.Resource,
+		Format:    job.Format,
+		Status:    job.Status,
+		Error:     job.Error,
+		CreatedAt: job.CreatedAt,
+	}
+	if job.Status == "done" {
+		response.DownloadURL = fmt.Sprintf("/api/v1/exports/%d/download", job.ID)
+	}
+	return response
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	dto "housy/dto/result"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const readinessTimeout = 2 * time.Second
+
+// Check reports whether a dependency is usable.
+type Check func(ctx context.Context) error
+
+type handlerHealth struct {
+	Checks map[string]Check
+}
+
+func HandlerHealth(Checks map[string]Check) *handlerHealth {
+	return &handlerHealth{Checks}
+}
+
+// Healthz tells the process is up, without looking at dependencies.
+func (h *handlerHealth) Healthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: map[string]string{"status": "ok"}}
+	json.NewEncoder(w).Encode(response)
+}
+
+// Readyz runs every check and answers 503 when one of them fails, so the
+// instance is taken out of the load balancer. The errors are logged, the
+// response only names the failed checks.
+func (h *handlerHealth) Readyz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	names := make([]string, 0, len(h.Checks))
+	for name := range h.Checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var failed []string
+	results := make(map[string]string, len(names))
+	for _, name := range names {
+		if err := h.Checks[name](ctx); err != nil {
+			slog.ErrorContext(ctx, "readiness check failed", "check", name, "error", err)
+			failed = append(failed, name)
+			continue
+		}
+		results[name] = "ok"
+	}
+
+	if len(failed) > 0 {
+		writeError(w, http.StatusServiceUnavailable, "not ready: "+strings.Join(failed, ", "))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: results}
+	json.NewEncoder(w).Encode(response)
+}
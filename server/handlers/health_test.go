@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	dto "housy/dto/result"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestReadyzHidesCheckErrors(t *testing.T) {
+	h := HandlerHealth(map[string]Check{
+		"database": func(ctx context.Context) error { return errors.New("dial tcp 10.0.0.5:3306: connection refused") },
+		"search":   func(ctx context.Context) error { return nil },
+	})
+
+	w := httptest.NewRecorder()
+	h.Readyz(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", w.Code)
+	}
+	var result dto.ErrorResult
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Error != dto.ErrUnavailable || result.Message != "not ready: database" {
+		t.Errorf("got %+v, want the unavailable error naming the database", result)
+	}
+	if strings.Contains(w.Body.String(), "10.0.0.5") {
+		t.Errorf("the response leaks the error of the check: %s", w.Body.String())
+	}
+}
@@ -2,39 +2,88 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	housesdto "housy/dto/house"
 	dto "housy/dto/result"
 	"housy/models"
+	"housy/pkg/search"
+	"housy/pkg/storage"
 	"housy/repositories"
+	"math"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
-	"github.com/go-playground/validator/v10"
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/gorilla/mux"
-	"gorm.io/datatypes"
 )
 
-var path_file = "http://localhost:5000/uploads/"
-
 type handlerHouse struct {
-	HouseRepository repositories.HouseRepository
+	HouseRepository    repositories.HouseRepository
+	WishlistRepository repositories.WishlistRepository
+	AmenityRepository  repositories.AmenityRepository
+	UserRepository     repositories.UserRepository
+	Storage            storage.Storage
+	Search             search.Index
 }
 
-func HandlerHouse(HouseRepository repositories.HouseRepository) *handlerHouse {
-	return &handlerHouse{HouseRepository}
+func HandlerHouse(HouseRepository repositories.HouseRepository, WishlistRepository repositories.WishlistRepository, AmenityRepository repositories.AmenityRepository, UserRepository repositories.UserRepository, Storage storage.Storage, Search search.Index) *handlerHouse {
+	return &handlerHouse{HouseRepository, WishlistRepository, AmenityRepository, UserRepository, Storage, Search}
 }
 
 func (h *handlerHouse) FindHouses(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	houses, err := h.HouseRepository.FindHouses()
+	page, paginated, err := pageFromQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter, err := houseFilter(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	hits, err := h.searchHouses(r, &filter)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	houses, err := h.HouseRepository.FindHouses(r.Context(), filter, page)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(err.Error())
+		writeInternalError(w, r, err)
+		return
+	}
+
+	if paginated {
+		total, err := h.HouseRepository.CountHouses(r.Context(), filter)
+		if err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+		setTotalCount(w, total)
 	}
 
 	for i, p := range houses {
-		houses[i].Image = path_file + p.Image
+		houses[i].Image = h.Storage.URL(p.Image)
+		if filter.Near != nil && p.Latitude != nil && p.Longitude != nil {
+			distance := math.Round(filter.Near.DistanceKm(*p.Latitude, *p.Longitude)*100) / 100
+			houses[i].DistanceKm = &distance
+		}
+		if hit, ok := hits[p.ID]; ok {
+			houses[i].Score = &hit.Score
+			houses[i].Highlights = hit.Highlights
+		}
+	}
+
+	if err := markFavorites(r, h.WishlistRepository, houses); err != nil {
+		writeInternalError(w, r, err)
+		return
 	}
 
 	w.WriteHeader(http.StatusOK)
@@ -48,18 +97,22 @@ func (h *handlerHouse) GetHouse(w http.ResponseWriter, r *http.Request) {
 	id, _ := strconv.Atoi(mux.Vars(r)["id"])
 
 	// var profile models.Profile
-	house, err := h.HouseRepository.GetHouse(id)
+	house, err := h.HouseRepository.GetHouse(r.Context(), id)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
-		json.NewEncoder(w).Encode(response)
+		writeLookupError(w, r, err, "house")
 		return
 	}
 
-	house.Image = path_file + house.Image
+	house.Image = h.Storage.URL(house.Image)
+
+	houses := []models.House{house}
+	if err := markFavorites(r, h.WishlistRepository, houses); err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
 
 	w.WriteHeader(http.StatusOK)
-	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponseHouse(house)}
+	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponseHouse(houses[0])}
 	json.NewEncoder(w).Encode(response)
 }
 
@@ -69,6 +122,9 @@ func (h *handlerHouse) CreateHouse(w http.ResponseWriter, r *http.Request) {
 	dataContex := r.Context().Value("dataFile")
 	filename := dataContex.(string)
 
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	userId := int(userInfo["id"].(float64))
+
 	price, _ := strconv.Atoi(r.FormValue("price"))
 	Bedroom, _ := strconv.Atoi(r.FormValue("Bedroom"))
 	Bathroom, _ := strconv.Atoi(r.FormValue("Bathroom"))
@@ -77,21 +133,29 @@ func (h *handlerHouse) CreateHouse(w http.ResponseWriter, r *http.Request) {
 		Name:        r.FormValue("name"),
 		CityName:    r.FormValue("cityname"),
 		Address:     r.FormValue("address"),
+		Latitude:    formFloat(r.FormValue("latitude")),
+		Longitude:   formFloat(r.FormValue("longitude")),
+		CleaningFee: formInt(r.FormValue("cleaning_fee")),
+		Deposit:     formInt(r.FormValue("deposit")),
 		TypeRent:    r.FormValue("type_rent"),
 		Description: r.FormValue("description"),
 		Area:        r.FormValue("area"),
-		Amenities:   datatypes.JSON(r.FormValue("amenities")),
+		Amenities:   amenityCodes(r.FormValue("amenities")),
 		Bedroom:     Bedroom,
 		Price:       price,
 		Bathroom:    Bathroom,
 	}
 
-	validation := validator.New()
-	err := validation.Struct(request)
+	err := validate.Struct(request)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
-		json.NewEncoder(w).Encode(response)
+		h.Storage.Remove(filename)
+		writeValidationError(w, err)
+		return
+	}
+
+	amenities, ok := resolveAmenities(w, r, h.AmenityRepository, request.Amenities)
+	if !ok {
+		h.Storage.Remove(filename)
 		return
 	}
 
@@ -99,25 +163,33 @@ func (h *handlerHouse) CreateHouse(w http.ResponseWriter, r *http.Request) {
 		Name:        request.Name,
 		CityName:    request.CityName,
 		Address:     request.Address,
+		Latitude:    request.Latitude,
+		Longitude:   request.Longitude,
 		Price:       request.Price,
 		TypeRent:    request.TypeRent,
-		Amenities:   request.Amenities,
+		Amenities:   amenities,
 		Bedroom:     request.Bedroom,
 		Bathroom:    request.Bathroom,
 		Description: request.Description,
 		Area:        request.Area,
 		Image:       filename,
+		OwnerId:     userId,
+	}
+	if request.CleaningFee != nil {
+		house.CleaningFee = *request.CleaningFee
+	}
+	if request.Deposit != nil {
+		house.Deposit = *request.Deposit
 	}
 
-	house, err = h.HouseRepository.CreateHouse(house)
+	house, err = h.HouseRepository.CreateHouse(r.Context(), house)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
-		json.NewEncoder(w).Encode(response)
+		writeInternalError(w, r, err)
 		return
 	}
+	indexHouses(r.Context(), h.Search, house)
 
-	house, _ = h.HouseRepository.GetHouse(house.ID)
+	house, _ = h.HouseRepository.GetHouse(r.Context(), house.ID)
 
 	w.WriteHeader(http.StatusOK)
 	response := dto.SuccessResult{Code: http.StatusOK, Data: house}
@@ -129,27 +201,55 @@ func (h *handlerHouse) DeleteHouse(w http.ResponseWriter, r *http.Request) {
 
 	id, _ := strconv.Atoi(mux.Vars(r)["id"])
 
-	house, err := h.HouseRepository.GetHouse(id)
+	house, err := h.HouseRepository.GetHouse(r.Context(), id)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
-		json.NewEncoder(w).Encode(response)
+		writeLookupError(w, r, err, "house")
+		return
+	}
+	if !requireHouseOwner(w, r, h.UserRepository, house) {
 		return
 	}
 
-	data, err := h.HouseRepository.DeleteHouse(house)
+	upcoming, err := h.HouseRepository.HasUpcomingBookings(r.Context(), house.ID)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
-		json.NewEncoder(w).Encode(response)
+		writeInternalError(w, r, err)
+		return
+	}
+	if upcoming {
+		writeError(w, http.StatusConflict, "house has upcoming paid bookings and can't be deleted")
 		return
 	}
 
+	data, err := h.HouseRepository.DeleteHouse(r.Context(), house)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	unindexHouses(r.Context(), h.Search, house.ID)
+
 	w.WriteHeader(http.StatusOK)
 	response := dto.SuccessResult{Code: http.StatusOK, Data: data}
 	json.NewEncoder(w).Encode(response)
 }
 
+// requireHouseOwner reports whether the signed in user owns the house or is an
+// admin, otherwise it writes a forbidden response.
+func requireHouseOwner(w http.ResponseWriter, r *http.Request, UserRepository repositories.UserRepository, house models.House) bool {
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	userId := int(userInfo["id"].(float64))
+	if house.OwnerId == userId {
+		return true
+	}
+
+	user, err := UserRepository.GetUser(r.Context(), userId)
+	if err != nil || !isAdmin(user) {
+		writeError(w, http.StatusForbidden, "only the owner of the house and admins can do this")
+		return false
+	}
+
+	return true
+}
+
 func (h *handlerHouse) UpdateHouse(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -163,22 +263,35 @@ func (h *handlerHouse) UpdateHouse(w http.ResponseWriter, r *http.Request) {
 		Name:        r.FormValue("name"),
 		CityName:    r.FormValue("cityname"),
 		Address:     r.FormValue("address"),
+		Latitude:    formFloat(r.FormValue("latitude")),
+		Longitude:   formFloat(r.FormValue("longitude")),
+		CleaningFee: formInt(r.FormValue("cleaning_fee")),
+		Deposit:     formInt(r.FormValue("deposit")),
 		TypeRent:    r.FormValue("type_rent"),
 		Description: r.FormValue("description"),
 		Area:        r.FormValue("area"),
-		Amenities:   datatypes.JSON(r.FormValue("amenities")),
+		Amenities:   amenityCodes(r.FormValue("amenities")),
 		Price:       price,
 		Bedroom:     bedroom,
 		Bathroom:    bathroom,
 		Image:       filename,
 	}
 
+	if err := validate.StructPartial(request, "Latitude", "Longitude", "CleaningFee", "Deposit"); err != nil {
+		h.Storage.Remove(filename)
+		writeValidationError(w, err)
+		return
+	}
+
 	id, _ := strconv.Atoi(mux.Vars(r)["id"])
-	house, err := h.HouseRepository.GetHouse(int(id))
+	house, err := h.HouseRepository.GetHouse(r.Context(), int(id))
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
-		json.NewEncoder(w).Encode(response)
+		h.Storage.Remove(filename)
+		writeLookupError(w, r, err, "house")
+		return
+	}
+	if !requireHouseOwner(w, r, h.UserRepository, house) {
+		h.Storage.Remove(filename)
 		return
 	}
 
@@ -194,16 +307,34 @@ func (h *handlerHouse) UpdateHouse(w http.ResponseWriter, r *http.Request) {
 		house.Address = request.Address
 	}
 
+	if request.Latitude != nil {
+		house.Latitude = request.Latitude
+		house.Longitude = request.Longitude
+	}
+
 	if request.Price != 0 {
 		house.Price = request.Price
 	}
 
+	if request.CleaningFee != nil {
+		house.CleaningFee = *request.CleaningFee
+	}
+
+	if request.Deposit != nil {
+		house.Deposit = *request.Deposit
+	}
+
 	if request.TypeRent != "" {
 		house.TypeRent = request.TypeRent
 	}
 
 	if request.Amenities != nil {
-		house.Amenities = request.Amenities
+		amenities, ok := resolveAmenities(w, r, h.AmenityRepository, request.Amenities)
+		if !ok {
+			h.Storage.Remove(filename)
+			return
+		}
+		house.Amenities = amenities
 	}
 
 	if request.Bedroom != 0 {
@@ -226,32 +357,160 @@ func (h *handlerHouse) UpdateHouse(w http.ResponseWriter, r *http.Request) {
 		house.Image = request.Image
 	}
 
-	data, err := h.HouseRepository.UpdateHouse(house)
+	data, err := h.HouseRepository.UpdateHouse(r.Context(), house)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
-		json.NewEncoder(w).Encode(response)
+		writeInternalError(w, r, err)
 		return
 	}
+	indexHouses(r.Context(), h.Search, data)
 
 	w.WriteHeader(http.StatusOK)
 	response := dto.SuccessResult{Code: http.StatusOK, Data: data}
 	json.NewEncoder(w).Encode(response)
 }
 
+// houseFilter reads the house list filters from the query string.
+func houseFilter(query url.Values) (repositories.HouseFilter, error) {
+	minPrice, _ := strconv.Atoi(query.Get("min_price"))
+	maxPrice, _ := strconv.Atoi(query.Get("max_price"))
+	bedroom, _ := strconv.Atoi(query.Get("bedroom"))
+	bathroom, _ := strconv.Atoi(query.Get("bathroom"))
+
+	var amenities []string
+	for _, value := range query["amenities"] {
+		amenities = append(amenities, amenityCodes(value)...)
+	}
+
+	filter := repositories.HouseFilter{
+		CityName:  query.Get("cityname"),
+		TypeRent:  query.Get("type_rent"),
+		MinPrice:  minPrice,
+		MaxPrice:  maxPrice,
+		Bedroom:   bedroom,
+		Bathroom:  bathroom,
+		Amenities: amenities,
+	}
+
+	if query.Get("lat") != "" || query.Get("lng") != "" {
+		lat, err := strconv.ParseFloat(query.Get("lat"), 64)
+		if err != nil || !(lat >= -90 && lat <= 90) {
+			return filter, errors.New("lat must be a latitude between -90 and 90")
+		}
+		lng, err := strconv.ParseFloat(query.Get("lng"), 64)
+		if err != nil || !(lng >= -180 && lng <= 180) {
+			return filter, errors.New("lng must be a longitude between -180 and 180")
+		}
+		filter.Near = &repositories.GeoPoint{Lat: lat, Lng: lng}
+	}
+
+	if value := query.Get("radius_km"); value != "" {
+		radius, err := strconv.ParseFloat(value, 64)
+		if err != nil || !(radius > 0) || math.IsInf(radius, 0) {
+			return filter, errors.New("radius_km must be a positive number")
+		}
+		if filter.Near == nil {
+			return filter, errors.New("radius_km needs lat and lng")
+		}
+		if filter.Near.CrossesAntimeridian(radius) {
+			return filter, errors.New("radius_km must not reach past longitude 180 or -180")
+		}
+		filter.RadiusKm = radius
+	}
+
+	if value := query.Get("bbox"); value != "" {
+		bounds, err := geoBounds(value)
+		if err != nil {
+			return filter, err
+		}
+		filter.Bounds = &bounds
+	}
+
+	switch sort := query.Get("sort"); sort {
+	case "":
+	case repositories.HouseSortDistance:
+		if filter.Near == nil {
+			return filter, errors.New("sort=distance needs lat and lng")
+		}
+		filter.Sort = sort
+	default:
+		return filter, fmt.Errorf("unknown sort %q, houses can be sorted by distance", sort)
+	}
+
+	return filter, nil
+}
+
+// geoBounds reads a bbox parameter, west,south,east,north in decimal
+// degrees as in GeoJSON.
+func geoBounds(value string) (repositories.GeoBounds, error) {
+	invalid := errors.New("bbox must be west,south,east,north in decimal degrees")
+
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return repositories.GeoBounds{}, invalid
+	}
+
+	var coordinates [4]float64
+	for i, part := range parts {
+		coordinate, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return repositories.GeoBounds{}, invalid
+		}
+		coordinates[i] = coordinate
+	}
+
+	bounds := repositories.GeoBounds{West: coordinates[0], South: coordinates[1], East: coordinates[2], North: coordinates[3]}
+	valid := bounds.West >= -180 && bounds.West <= 180 && bounds.East >= -180 && bounds.East <= 180 &&
+		bounds.South >= -90 && bounds.North <= 90 && bounds.South <= bounds.North
+	if !valid {
+		return repositories.GeoBounds{}, invalid
+	}
+	return bounds, nil
+}
+
+// formFloat reads an optional decimal form value, nil when it is empty or
+// not a number.
+func formFloat(value string) *float64 {
+	number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return nil
+	}
+	return &number
+}
+
+// formInt reads an optional whole number form value, nil when it is empty
+// or not a number.
+func formInt(value string) *int {
+	number, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return nil
+	}
+	return &number
+}
+
 func convertResponseHouse(u models.House) housesdto.ResponseHouse {
 	return housesdto.ResponseHouse{
 		ID:          u.ID,
 		Name:        u.Name,
 		CityName:    u.CityName,
 		Address:     u.Address,
+		Latitude:    u.Latitude,
+		Longitude:   u.Longitude,
 		Price:       u.Price,
+		CleaningFee: u.CleaningFee,
+		Deposit:     u.Deposit,
 		TypeRent:    u.TypeRent,
-		Amenities:   u.Amenities,
+		Amenities:   convertResponseAmenities(u.Amenities),
 		Bedroom:     u.Bedroom,
 		Bathroom:    u.Bathroom,
 		Image:       u.Image,
 		Description: u.Description,
 		Area:        u.Area,
+		OwnerId:     u.OwnerId,
+		Rating:      u.Rating,
+		ReviewCount: u.ReviewCount,
+
+		IsFavorited:   u.IsFavorited,
+		FavoriteCount: u.FavoriteCount,
+		DistanceKm:    u.DistanceKm,
 	}
 }
@@ -0,0 +1,478 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	housesdto "housy/dto/house"
+	dto "housy/dto/result"
+	"housy/models"
+	"io"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	importDryRun          = "dry-run"
+	importCommit          = "commit"
+	importDefaultBatch    = 100
+	importMaxUploadSize   = 64 << 20
+	importMaxImageSize    = 10 << 20
+	importDownloadTimeout = 15 * time.Second
+	importMaxRedirects    = 5
+)
+
+// errImportAddress refuses image URLs that reach the server itself or its
+// private network.
+var errImportAddress = errors.New("the image url must be a public address")
+
+// importBlockedNetworks are the ranges beyond net.IP's own checks that don't
+// reach the public internet.
+var importBlockedNetworks = parseNetworks("0.0.0.0/8", "100.64.0.0/10", "192.0.0.0/24", "198.18.0.0/15", "240.0.0.0/4", "64:ff9b::/96")
+
+// importClient downloads the images of imported rows. Every connection,
+// redirects included, is checked against the resolved address so a name
+// can't point the server at its loopback, private or metadata addresses.
+var importClient = &http.Client{
+	Timeout: importDownloadTimeout,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: importDownloadTimeout,
+			Control: func(network, address string, _ syscall.RawConn) error {
+				host, _, err := net.SplitHostPort(address)
+				if err != nil {
+					return err
+				}
+				if !isPublicIP(net.ParseIP(host)) {
+					return errImportAddress
+				}
+				return nil
+			},
+		}).DialContext,
+		TLSHandshakeTimeout: importDownloadTimeout,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= importMaxRedirects {
+			return errors.New("too many redirects")
+		}
+		if !isImageURL(req.URL.String()) {
+			return errors.New("redirected to a url that isn't http or https")
+		}
+		return nil
+	},
+}
+
+type importRow struct {
+	row     int
+	request housesdto.HouseRequest
+	report  housesdto.ImportRowResponse
+}
+
+// ImportHouses creates houses in bulk from a CSV or JSON file. Images are
+// either URLs or file names inside an optional zip archive. In dry-run mode,
+// the default, rows are only validated.
+func (h *handlerHouse) ImportHouses(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	user, ok := requireOwner(w, r, h.UserRepository)
+	if !ok {
+		return
+	}
+	userId := user.ID
+
+	mode := r.URL.Query().Get("mode")
+	if mode == "" {
+		mode = importDryRun
+	}
+	if mode != importDryRun && mode != importCommit {
+		writeError(w, http.StatusBadRequest, "mode must be dry-run or commit")
+		return
+	}
+
+	batchSize := importDefaultBatch
+	if value := r.URL.Query().Get("batch_size"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 1 {
+			writeError(w, http.StatusBadRequest, "batch_size must be a positive number")
+			return
+		}
+		batchSize = size
+	}
+
+	if err := r.ParseMultipartForm(importMaxUploadSize); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "a csv or json file is required in the file field")
+		return
+	}
+	defer file.Close()
+
+	rows, err := parseImportFile(file, header)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var images *zip.Reader
+	if archive, archiveHeader, err := r.FormFile("images"); err == nil {
+		defer archive.Close()
+		images, err = zip.NewReader(archive, archiveHeader.Size)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "images must be a zip archive")
+			return
+		}
+	}
+
+	amenities, err := h.AmenityRepository.FindAmenities(r.Context())
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	catalog := make(map[string]models.Amenity)
+	for _, amenity := range amenities {
+		catalog[amenity.Code] = amenity
+	}
+
+	for i := range rows {
+		rows[i].report.Errors = validateImportRow(rows[i].request, images, catalog)
+	}
+
+	report := housesdto.ImportResponse{Mode: mode, Total: len(rows)}
+
+	for start := 0; start < len(rows); start += batchSize {
+		end := start + batchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+		if mode == importCommit {
+			h.importBatch(r.Context(), rows[start:end], images, catalog, userId)
+		}
+	}
+
+	for _, row := range rows {
+		if len(row.report.Errors) > 0 {
+			report.Failed++
+		} else {
+			report.Valid++
+		}
+		if row.report.ID != 0 {
+			report.Created++
+		}
+		report.Rows = append(report.Rows, row.report)
+	}
+
+	status := http.StatusOK
+	if report.Created > 0 {
+		status = http.StatusCreated
+	}
+	w.WriteHeader(status)
+	response := dto.SuccessResult{Code: status, Data: report}
+	json.NewEncoder(w).Encode(response)
+}
+
+// importBatch stores the images of the valid rows and creates their houses
+// in one database transaction. When the transaction fails every row of the
+// batch is reported as failed and its images are removed again.
+func (h *handlerHouse) importBatch(ctx context.Context, rows []importRow, images *zip.Reader, catalog map[string]models.Amenity, ownerId int) {
+	var houses []models.House
+	var imported []*importRow
+	var files []string
+
+	for i := range rows {
+		row := &rows[i]
+		if len(row.report.Errors) > 0 {
+			continue
+		}
+
+		image, err := h.storeImportImage(row.request.Image, images)
+		if err != nil {
+			row.report.Errors = append(row.report.Errors, "image: "+err.Error())
+			continue
+		}
+		files = append(files, image)
+
+		amenities := []models.Amenity{}
+		for _, code := range row.request.Amenities {
+			amenities = append(amenities, catalog[code])
+		}
+
+		house := models.House{
+			Name:        row.request.Name,
+			CityName:    row.request.CityName,
+			Address:     row.request.Address,
+			Latitude:    row.request.Latitude,
+			Longitude:   row.request.Longitude,
+			Price:       row.request.Price,
+			TypeRent:    row.request.TypeRent,
+			Amenities:   amenities,
+			Bedroom:     row.request.Bedroom,
+			Bathroom:    row.request.Bathroom,
+			Description: row.request.Description,
+			Area:        row.request.Area,
+			Image:       image,
+			OwnerId:     ownerId,
+		}
+		if row.request.CleaningFee != nil {
+			house.CleaningFee = *row.request.CleaningFee
+		}
+		if row.request.Deposit != nil {
+			house.Deposit = *row.request.Deposit
+		}
+		houses = append(houses, house)
+		imported = append(imported, row)
+	}
+
+	if len(houses) == 0 {
+		return
+	}
+
+	houses, err := h.HouseRepository.CreateHouses(ctx, houses)
+	if err != nil {
+		for _, row := range imported {
+			row.report.Errors = append(row.report.Errors, "batch rolled back: "+err.Error())
+		}
+		for _, file := range files {
+			h.Storage.Remove(file)
+		}
+		return
+	}
+
+	for i, row := range imported {
+		row.report.ID = houses[i].ID
+	}
+	indexHouses(ctx, h.Search, houses...)
+}
+
+func parseImportFile(file multipart.File, header *multipart.FileHeader) ([]importRow, error) {
+	switch strings.ToLower(filepath.Ext(header.Filename)) {
+	case ".json":
+		var requests []housesdto.HouseRequest
+		if err := json.NewDecoder(file).Decode(&requests); err != nil {
+			return nil, fmt.Errorf("invalid json: %s", err.Error())
+		}
+
+		rows := make([]importRow, len(requests))
+		for i, request := range requests {
+			rows[i] = importRow{row: i + 1, request: request}
+			rows[i].report = housesdto.ImportRowResponse{Row: i + 1, Name: request.Name}
+		}
+		return rows, nil
+	case ".csv":
+		return parseImportCSV(file)
+	}
+
+	return nil, errors.New("file must be a .csv or .json file")
+}
+
+// parseImportCSV reads rows whose header uses the same keys as the JSON
+// form of housesdto.HouseRequest, in any letter case.
+func parseImportCSV(file io.Reader) ([]importRow, error) {
+	reader := csv.NewReader(file)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv: %s", err.Error())
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	var rows []importRow
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv: %s", err.Error())
+		}
+
+		value := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		price, _ := strconv.Atoi(value("price"))
+		bedroom, _ := strconv.Atoi(value("bedroom"))
+		bathroom, _ := strconv.Atoi(value("bathroom"))
+
+		request := housesdto.HouseRequest{
+			Name:        value("name"),
+			CityName:    value("cityname"),
+			Address:     value("address"),
+			Latitude:    formFloat(value("latitude")),
+			Longitude:   formFloat(value("longitude")),
+			Price:       price,
+			CleaningFee: formInt(value("cleaning_fee")),
+			Deposit:     formInt(value("deposit")),
+			TypeRent:    value("type_rent"),
+			Bedroom:     bedroom,
+			Bathroom:    bathroom,
+			Image:       value("image"),
+			Description: value("description"),
+			Area:        value("area"),
+		}
+		request.Amenities = amenityCodes(value("amenities"))
+
+		rows = append(rows, importRow{
+			row:     line,
+			request: request,
+			report:  housesdto.ImportRowResponse{Row: line, Name: request.Name},
+		})
+	}
+
+	return rows, nil
+}
+
+func validateImportRow(request housesdto.HouseRequest, images *zip.Reader, catalog map[string]models.Amenity) []string {
+	var errs []string
+
+	if err := validate.Struct(request); err != nil {
+		var validationErrors validator.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			for _, field := range fieldErrors(validationErrors) {
+				errs = append(errs, field.Field+": "+field.Message)
+			}
+		} else {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	var unknown []string
+	for _, code := range request.Amenities {
+		if _, ok := catalog[code]; !ok && code != "" {
+			unknown = append(unknown, code)
+		}
+	}
+	if len(unknown) > 0 {
+		field := amenitiesFieldError(unknown)
+		errs = append(errs, field.Field+": "+field.Message)
+	}
+
+	switch {
+	case request.Image == "":
+		errs = append(errs, "image: is required")
+	case isImageURL(request.Image):
+	case images == nil:
+		errs = append(errs, "image: must be a url or a file in the images zip")
+	case findZipImage(images, request.Image) == nil:
+		errs = append(errs, fmt.Sprintf("image: %s is not in the images zip", request.Image))
+	}
+
+	return errs
+}
+
+// storeImportImage saves the image to the upload storage the same way
+// middleware.UploadFile does and returns its file name. Only files whose
+// content is an image are saved.
+func (h *handlerHouse) storeImportImage(image string, images *zip.Reader) (string, error) {
+	var source io.ReadCloser
+
+	if isImageURL(image) {
+		resp, err := importClient.Get(image)
+		if err != nil {
+			return "", err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return "", fmt.Errorf("download failed with status %d", resp.StatusCode)
+		}
+		source = resp.Body
+	} else {
+		entry := findZipImage(images, image)
+		if entry == nil {
+			return "", fmt.Errorf("%s is not in the images zip", image)
+		}
+		file, err := entry.Open()
+		if err != nil {
+			return "", err
+		}
+		source = file
+	}
+	defer source.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(source, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	head = head[:n]
+	if contentType := http.DetectContentType(head); !strings.HasPrefix(contentType, "image/") {
+		return "", fmt.Errorf("the file is %s, not an image", contentType)
+	}
+
+	limited := &io.LimitedReader{R: io.MultiReader(bytes.NewReader(head), source), N: importMaxImageSize + 1}
+	name, err := h.Storage.Save(limited, "image-*.png")
+	if err != nil {
+		return "", err
+	}
+	if limited.N == 0 {
+		h.Storage.Remove(name)
+		return "", errors.New("max size is 10MB")
+	}
+
+	return name, nil
+}
+
+// isPublicIP reports whether ip is a unicast address of the public
+// internet.
+func isPublicIP(ip net.IP) bool {
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, network := range importBlockedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseNetworks(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+func isImageURL(image string) bool {
+	return strings.HasPrefix(image, "http://") || strings.HasPrefix(image, "https://")
+}
+
+func findZipImage(images *zip.Reader, name string) *zip.File {
+	if images == nil {
+		return nil
+	}
+	for _, file := range images.File {
+		if file.Name == name || path.Base(file.Name) == name {
+			return file
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip     string
+		public bool
+	}{
+		{"8.8.8.8", true},
+		{"2001:4860:4860::8888", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.100.100.200", false},
+		{"0.0.0.0", false},
+		{"fd00:ec2::254", false},
+		{"fe80::1", false},
+		{"::ffff:127.0.0.1", false},
+	}
+
+	for _, test := range tests {
+		if got := isPublicIP(net.ParseIP(test.ip)); got != test.public {
+			t.Errorf("isPublicIP(%s) = %v, want %v", test.ip, got, test.public)
+		}
+	}
+}
+
+func TestImportClientRefusesPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("internal"))
+	}))
+	defer server.Close()
+
+	_, err := importClient.Get(server.URL)
+	if !errors.Is(err, errImportAddress) {
+		t.Fatalf("got %v, want %v", err, errImportAddress)
+	}
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"context"
+	"housy/models"
+	"housy/pkg/search"
+	"housy/repositories"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// searchLimit is the number of best matches a text search of the houses
+// keeps, the filters then apply to them.
+const searchLimit = 1000
+
+// searchHouses runs the text search of the q parameter and restricts the
+// filter to its matches, best first. It returns the matches by house id, nil
+// without q.
+func (h *handlerHouse) searchHouses(r *http.Request, filter *repositories.HouseFilter) (map[int]search.Hit, error) {
+	text := strings.TrimSpace(r.URL.Query().Get("q"))
+	if text == "" {
+		return nil, nil
+	}
+
+	found, err := h.Search.Search(text, searchLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make(map[int]search.Hit, len(found))
+	filter.Matching = make([]int, 0, len(found))
+	for _, hit := range found {
+		hits[hit.ID] = hit
+		filter.Matching = append(filter.Matching, hit.ID)
+	}
+	return hits, nil
+}
+
+// SyncSearch brings the search index up to date with the houses when the
+// server starts, indexing every house and dropping the deleted ones. It
+// catches up with the changes the index missed, or builds it from scratch.
+func (h *handlerHouse) SyncSearch(ctx context.Context) {
+	indexed, err := h.Search.IDs()
+	if err != nil {
+		slog.ErrorContext(ctx, "search sync failed", "error", err)
+		return
+	}
+
+	stale := make(map[int]bool, len(indexed))
+	for _, id := range indexed {
+		stale[id] = true
+	}
+
+	var batch []search.Document
+	count := 0
+	err = h.HouseRepository.EachHouse(ctx, repositories.HouseFilter{}, func(house models.House) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		delete(stale, house.ID)
+		batch = append(batch, searchDocument(house))
+		count++
+		if len(batch) < 500 {
+			return nil
+		}
+
+		err := h.Search.Index(batch...)
+		batch = batch[:0]
+		return err
+	})
+	if err == nil {
+		err = h.Search.Index(batch...)
+	}
+	if err == nil && len(stale) > 0 {
+		ids := make([]int, 0, len(stale))
+		for id := range stale {
+			ids = append(ids, id)
+		}
+		err = h.Search.Delete(ids...)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "search sync failed", "error", err)
+		return
+	}
+
+	slog.InfoContext(ctx, "search index synced", "houses", count, "removed", len(stale))
+}
+
+// indexHouses adds or updates the houses in the search index. The database
+// change is already committed, so a failure is only logged, the index
+// catches up on the next start.
+func indexHouses(ctx context.Context, index search.Index, houses ...models.House) {
+	docs := make([]search.Document, 0, len(houses))
+	for _, house := range houses {
+		docs = append(docs, searchDocument(house))
+	}
+
+	if err := index.Index(docs...); err != nil {
+		slog.ErrorContext(ctx, "search index update failed", "error", err)
+	}
+}
+
+// unindexHouses removes the houses from the search index, logging failures
+// like indexHouses.
+func unindexHouses(ctx context.Context, index search.Index, ids ...int) {
+	if err := index.Delete(ids...); err != nil {
+		slog.ErrorContext(ctx, "search index update failed", "error", err)
+	}
+}
+
+func searchDocument(house models.House) search.Document {
+	return search.Document{
+		ID:          house.ID,
+		Name:        house.Name,
+		Description: house.Description,
+		Address:     house.Address,
+		CityName:    house.CityName,
+	}
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"housy/repositories"
+)
+
+// TotalCountHeader carries the size of the whole list on paginated responses.
+const TotalCountHeader = "X-Total-Count"
+
+const (
+	defaultPerPage = 20
+	maxPerPage     = 100
+)
+
+// pageFromQuery reads the optional page, from 1, and per_page parameters.
+// Lists requested without either are returned whole, as before pagination.
+func pageFromQuery(query url.Values) (repositories.Page, bool, error) {
+	if query.Get("page") == "" && query.Get("per_page") == "" {
+		return repositories.Page{}, false, nil
+	}
+
+	number := 1
+	if value := query.Get("page"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return repositories.Page{}, false, errors.New("page must be a positive number")
+		}
+		number = n
+	}
+
+	perPage := defaultPerPage
+	if value := query.Get("per_page"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxPerPage {
+			return repositories.Page{}, false, errors.New("per_page must be between 1 and " + strconv.Itoa(maxPerPage))
+		}
+		perPage = n
+	}
+
+	return repositories.Page{Limit: perPage, Offset: (number - 1) * perPage}, true, nil
+}
+
+func setTotalCount(w http.ResponseWriter, total int64) {
+	w.Header().Set(TotalCountHeader, strconv.FormatInt(total, 10))
+}
@@ -0,0 +1,394 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	pricingdto "housy/dto/pricing"
+	dto "housy/dto/result"
+	"housy/models"
+	"housy/pkg/pricing"
+	"housy/repositories"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/mux"
+)
+
+// maxQuoteNights is the longest stay a quote or a booking prices.
+const maxQuoteNights = 3 * 366
+
+// maxCalendarDays is the longest range the price calendar shows.
+const maxCalendarDays = 366
+
+type handlerPricing struct {
+	PricingRepository repositories.PricingRepository
+	HouseRepository   repositories.HouseRepository
+	PromoRepository   repositories.PromoRepository
+	Fees              pricing.Fees
+}
+
+func HandlerPricing(PricingRepository repositories.PricingRepository, HouseRepository repositories.HouseRepository, PromoRepository repositories.PromoRepository, Fees pricing.Fees) *handlerPricing {
+	return &handlerPricing{PricingRepository, HouseRepository, PromoRepository, Fees}
+}
+
+func (h *handlerPricing) GetPricing(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	p, ok := loadPricing(w, r, h.PricingRepository, h.HouseRepository, h.Fees, id)
+	if !ok {
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponsePricing(p)}
+	json.NewEncoder(w).Encode(response)
+}
+
+// UpdatePricing replaces the pricing rules and stay discounts of a house.
+// Only its owner can change them.
+func (h *handlerPricing) UpdatePricing(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	userId := int(userInfo["id"].(float64))
+
+	request := new(pricingdto.PricingRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	if err := validate.Struct(request); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+
+	if fields := pricingFieldErrors(*request); len(fields) > 0 {
+		writeFieldErrors(w, fields...)
+		return
+	}
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	house, err := h.HouseRepository.GetHouse(r.Context(), id)
+	if err != nil {
+		writeLookupError(w, r, err, "house")
+		return
+	}
+	if house.OwnerId != userId {
+		writeError(w, http.StatusForbidden, "only the owner of the house can change its pricing")
+		return
+	}
+
+	rules := make([]models.PricingRule, 0, len(request.Rules))
+	for _, rule := range request.Rules {
+		rules = append(rules, models.PricingRule{
+			Name:      rule.Name,
+			StartDate: rule.StartDate,
+			EndDate:   rule.EndDate,
+			Weekdays:  strings.Join(rule.Weekdays, ","),
+			Price:     rule.Price,
+			MinNights: rule.MinNights,
+		})
+	}
+
+	discounts := make([]models.StayDiscount, 0, len(request.Discounts))
+	for _, discount := range request.Discounts {
+		discounts = append(discounts, models.StayDiscount{
+			MinNights: discount.MinNights,
+			Percent:   discount.Percent,
+		})
+	}
+
+	if err := h.PricingRepository.ReplacePricing(r.Context(), house.ID, rules, discounts); err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	p, ok := loadPricing(w, r, h.PricingRepository, h.HouseRepository, h.Fees, house.ID)
+	if !ok {
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponsePricing(p)}
+	json.NewEncoder(w).Encode(response)
+}
+
+// Quote prices a stay at the house without booking it, with the promo code
+// if one is given. The limit of uses per user of the code is checked for
+// signed in users.
+func (h *handlerPricing) Quote(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	query := r.URL.Query()
+	checkIn, checkOut, err := stayDates(query.Get("check_in"), query.Get("check_out"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	p, ok := loadPricing(w, r, h.PricingRepository, h.HouseRepository, h.Fees, id)
+	if !ok {
+		return
+	}
+
+	quote := p.Quote(checkIn, checkOut)
+	if field, ok := minStayFieldError(quote); !ok {
+		writeFieldErrors(w, field)
+		return
+	}
+
+	if code := query.Get("promo_code"); code != "" {
+		userId, _ := callerId(r)
+		quote, _, ok = applyPromo(w, r, h.PromoRepository, p, quote, code, userId)
+		if !ok {
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponseQuote(p.House.ID, quote)}
+	json.NewEncoder(w).Encode(response)
+}
+
+// Calendar lists the nightly price of the house from from to to included,
+// by default the next 30 days.
+func (h *handlerPricing) Calendar(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	from, to, err := calendarRange(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	p, ok := loadPricing(w, r, h.PricingRepository, h.HouseRepository, h.Fees, id)
+	if !ok {
+		return
+	}
+
+	nights := make([]pricingdto.NightResponse, 0)
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		nights = append(nights, convertResponseNight(p.Night(date)))
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: nights}
+	json.NewEncoder(w).Encode(response)
+}
+
+// loadPricing reads the house with its pricing rules and discounts, priced
+// with the fees. It writes the error response itself and returns false when
+// it fails.
+func loadPricing(w http.ResponseWriter, r *http.Request, PricingRepository repositories.PricingRepository, HouseRepository repositories.HouseRepository, fees pricing.Fees, houseId int) (pricing.Pricing, bool) {
+	var p pricing.Pricing
+
+	house, err := HouseRepository.GetHouse(r.Context(), houseId)
+	if err != nil {
+		writeLookupError(w, r, err, "house")
+		return p, false
+	}
+
+	rules, err := PricingRepository.FindPricingRules(r.Context(), house.ID)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return p, false
+	}
+
+	discounts, err := PricingRepository.FindStayDiscounts(r.Context(), house.ID)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return p, false
+	}
+
+	return pricing.Pricing{House: house, Rules: rules, Discounts: discounts, Fees: fees}, true
+}
+
+// pricingFieldErrors checks what the validator can't: the order of the
+// dates of a rule, that a rule changes something, and that no two
+// discounts start at the same stay.
+func pricingFieldErrors(request pricingdto.PricingRequest) []dto.FieldError {
+	var fields []dto.FieldError
+
+	for i, rule := range request.Rules {
+		if rule.StartDate != "" && rule.EndDate != "" && rule.EndDate < rule.StartDate {
+			fields = append(fields, dto.FieldError{
+				Field:   fmt.Sprintf("rules[%d].end_date", i),
+				Rule:    "gtefield",
+				Param:   "start_date",
+				Message: "must not be before start_date",
+			})
+		}
+		if rule.Price == 0 && rule.MinNights == 0 {
+			fields = append(fields, dto.FieldError{
+				Field:   fmt.Sprintf("rules[%d].price", i),
+				Rule:    "required_without",
+				Param:   "min_nights",
+				Message: "is required without min_nights",
+			})
+		}
+	}
+
+	seen := make(map[int]bool)
+	for i, discount := range request.Discounts {
+		if seen[discount.MinNights] {
+			fields = append(fields, dto.FieldError{
+				Field:   fmt.Sprintf("discounts[%d].min_nights", i),
+				Rule:    "unique",
+				Message: "must be unique",
+			})
+		}
+		seen[discount.MinNights] = true
+	}
+
+	return fields
+}
+
+// minStayFieldError returns the 422 entry of a quote shorter than the
+// minimum stay of its check-in, and false when there is one.
+func minStayFieldError(quote pricing.Quote) (dto.FieldError, bool) {
+	if len(quote.Nights) >= quote.MinNights {
+		return dto.FieldError{}, true
+	}
+
+	return dto.FieldError{
+		Field:   "check_out",
+		Rule:    "min_nights",
+		Param:   strconv.Itoa(quote.MinNights),
+		Message: fmt.Sprintf("must be at least %d nights after check_in", quote.MinNights),
+	}, false
+}
+
+// stayDates parses the check-in and check-out dates (YYYY-MM-DD) of a
+// stay.
+func stayDates(checkInValue, checkOutValue string) (time.Time, time.Time, error) {
+	checkIn, err := time.Parse(pricing.DateLayout, checkInValue)
+	if err != nil {
+		return checkIn, checkIn, errors.New("check_in must be a date formatted as YYYY-MM-DD")
+	}
+
+	checkOut, err := time.Parse(pricing.DateLayout, checkOutValue)
+	if err != nil {
+		return checkIn, checkIn, errors.New("check_out must be a date formatted as YYYY-MM-DD")
+	}
+
+	if !checkOut.After(checkIn) {
+		return checkIn, checkOut, errors.New("check_out must be after check_in")
+	}
+	if checkOut.Sub(checkIn) > maxQuoteNights*24*time.Hour {
+		return checkIn, checkOut, fmt.Errorf("a stay can't be longer than %d nights", maxQuoteNights)
+	}
+
+	return checkIn, checkOut, nil
+}
+
+// calendarRange parses the from and to dates (YYYY-MM-DD) of the price
+// calendar, defaulting to today and the 30 days after from.
+func calendarRange(query url.Values) (time.Time, time.Time, error) {
+	from, _ := time.Parse(pricing.DateLayout, time.Now().Format(pricing.DateLayout))
+	if value := query.Get("from"); value != "" {
+		parsed, err := time.Parse(pricing.DateLayout, value)
+		if err != nil {
+			return from, from, errors.New("from must be a date formatted as YYYY-MM-DD")
+		}
+		from = parsed
+	}
+
+	to := from.AddDate(0, 0, 30)
+	if value := query.Get("to"); value != "" {
+		parsed, err := time.Parse(pricing.DateLayout, value)
+		if err != nil {
+			return from, to, errors.New("to must be a date formatted as YYYY-MM-DD")
+		}
+		to = parsed
+	}
+
+	if from.After(to) {
+		return from, to, errors.New("from must not be after to")
+	}
+	if to.Sub(from) >= maxCalendarDays*24*time.Hour {
+		return from, to, fmt.Errorf("the calendar can't show more than %d days", maxCalendarDays)
+	}
+
+	return from, to, nil
+}
+
+func convertResponsePricing(p pricing.Pricing) pricingdto.PricingResponse {
+	rules := make([]pricingdto.PricingRuleResponse, 0, len(p.Rules))
+	for _, rule := range p.Rules {
+		weekdays := []string{}
+		if rule.Weekdays != "" {
+			weekdays = strings.Split(rule.Weekdays, ",")
+		}
+		rules = append(rules, pricingdto.PricingRuleResponse{
+			ID:        rule.ID,
+			Name:      rule.Name,
+			StartDate: rule.StartDate,
+			EndDate:   rule.EndDate,
+			Weekdays:  weekdays,
+			Price:     rule.Price,
+			MinNights: rule.MinNights,
+		})
+	}
+
+	discounts := make([]pricingdto.StayDiscountResponse, 0, len(p.Discounts))
+	for _, discount := range p.Discounts {
+		discounts = append(discounts, pricingdto.StayDiscountResponse{
+			ID:        discount.ID,
+			MinNights: discount.MinNights,
+			Percent:   discount.Percent,
+		})
+	}
+
+	return pricingdto.PricingResponse{
+		HouseId:     p.House.ID,
+		BasePrice:   p.BaseNightly(),
+		CleaningFee: p.House.CleaningFee,
+		Deposit:     p.House.Deposit,
+		Rules:       rules,
+		Discounts:   discounts,
+	}
+}
+
+func convertResponseQuote(houseId int, quote pricing.Quote) pricingdto.QuoteResponse {
+	nightly := make([]pricingdto.NightResponse, 0, len(quote.Nights))
+	for _, night := range quote.Nights {
+		nightly = append(nightly, convertResponseNight(night))
+	}
+
+	return pricingdto.QuoteResponse{
+		HouseId:         houseId,
+		CheckIn:         quote.CheckIn.Format(pricing.DateLayout),
+		CheckOut:        quote.CheckOut.Format(pricing.DateLayout),
+		Nights:          len(quote.Nights),
+		Nightly:         nightly,
+		Subtotal:        quote.Subtotal,
+		DiscountPercent: quote.DiscountPercent,
+		Discount:        quote.Discount,
+		PromoCode:       quote.PromoCode,
+		PromoDiscount:   quote.PromoDiscount,
+		CleaningFee:     quote.CleaningFee,
+		ServiceFee:      quote.ServiceFee,
+		Tax:             quote.Tax,
+		Deposit:         quote.Deposit,
+		Total:           quote.Total,
+		Items:           convertResponseItems(quote.Items()),
+	}
+}
+
+func convertResponseNight(night pricing.Night) pricingdto.NightResponse {
+	return pricingdto.NightResponse{
+		Date:      night.Date.Format(pricing.DateLayout),
+		Price:     night.Price,
+		Rule:      night.Rule,
+		MinNights: night.MinNights,
+	}
+}
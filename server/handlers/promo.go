@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	promodto "housy/dto/promo"
+	dto "housy/dto/result"
+	"housy/models"
+	"housy/pkg/pricing"
+	"housy/repositories"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+type handlerPromo struct {
+	PromoRepository repositories.PromoRepository
+	HouseRepository repositories.HouseRepository
+	UserRepository  repositories.UserRepository
+}
+
+func HandlerPromo(PromoRepository repositories.PromoRepository, HouseRepository repositories.HouseRepository, UserRepository repositories.UserRepository) *handlerPromo {
+	return &handlerPromo{PromoRepository, HouseRepository, UserRepository}
+}
+
+func (h *handlerPromo) FindPromoCodes(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if _, ok := requireAdmin(w, r, h.UserRepository); !ok {
+		return
+	}
+
+	promos, err := h.PromoRepository.FindPromoCodes(r.Context())
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	responses := make([]promodto.PromoCodeResponse, 0, len(promos))
+	for _, promo := range promos {
+		responses = append(responses, convertResponsePromoCode(promo))
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: responses}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *handlerPromo) CreatePromoCode(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if _, ok := requireAdmin(w, r, h.UserRepository); !ok {
+		return
+	}
+
+	request := new(promodto.PromoCodeRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	if err := validate.Struct(request); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+
+	var fields []dto.FieldError
+	if request.Kind == models.PromoPercent && request.Amount > 100 {
+		fields = append(fields, dto.FieldError{Field: "amount", Rule: "lte", Param: "100", Message: "must be at most 100 for a percent promo code"})
+	}
+	if request.ValidFrom != "" && request.ValidUntil != "" && request.ValidUntil < request.ValidFrom {
+		fields = append(fields, dto.FieldError{Field: "valid_until", Rule: "gtefield", Param: "valid_from", Message: "must not be before valid_from"})
+	}
+	if request.HouseId != 0 {
+		_, err := h.HouseRepository.GetHouse(r.Context(), request.HouseId)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			fields = append(fields, dto.FieldError{Field: "house_id", Rule: "exists", Message: "is not a house"})
+		} else if err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+	}
+	if len(fields) > 0 {
+		writeFieldErrors(w, fields...)
+		return
+	}
+
+	code := strings.ToUpper(request.Code)
+	exists, err := h.PromoRepository.PromoCodeExists(r.Context(), code)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	if exists {
+		writeError(w, http.StatusConflict, "promo code "+code+" already exists")
+		return
+	}
+
+	promo, err := h.PromoRepository.CreatePromoCode(r.Context(), models.PromoCode{
+		Code:           code,
+		Description:    request.Description,
+		Kind:           request.Kind,
+		Amount:         request.Amount,
+		MaxDiscount:    request.MaxDiscount,
+		MinSpend:       request.MinSpend,
+		ValidFrom:      request.ValidFrom,
+		ValidUntil:     request.ValidUntil,
+		MaxUses:        request.MaxUses,
+		MaxUsesPerUser: request.MaxUsesPerUser,
+		HouseId:        request.HouseId,
+		CityName:       request.CityName,
+	})
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponsePromoCode(promo)}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *handlerPromo) UpdatePromoCode(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if _, ok := requireAdmin(w, r, h.UserRepository); !ok {
+		return
+	}
+
+	request := new(promodto.UpdatePromoCodeRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	if err := validate.Struct(request); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	promo, err := h.PromoRepository.GetPromoCode(r.Context(), id)
+	if err != nil {
+		writeLookupError(w, r, err, "promo code")
+		return
+	}
+
+	if request.Description != "" {
+		promo.Description = request.Description
+	}
+
+	if request.ValidUntil != "" {
+		if promo.ValidFrom != "" && request.ValidUntil < promo.ValidFrom {
+			writeFieldErrors(w, dto.FieldError{Field: "valid_until", Rule: "gtefield", Param: "valid_from", Message: "must not be before valid_from"})
+			return
+		}
+		promo.ValidUntil = request.ValidUntil
+	}
+
+	if request.MaxUses != nil {
+		promo.MaxUses = *request.MaxUses
+	}
+
+	if request.MaxUsesPerUser != nil {
+		promo.MaxUsesPerUser = *request.MaxUsesPerUser
+	}
+
+	promo, err = h.PromoRepository.UpdatePromoCode(r.Context(), promo)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponsePromoCode(promo)}
+	json.NewEncoder(w).Encode(response)
+}
+
+// DeletePromoCode ends a promo code, bookings that redeemed it keep their
+// discount.
+func (h *handlerPromo) DeletePromoCode(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if _, ok := requireAdmin(w, r, h.UserRepository); !ok {
+		return
+	}
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	if _, err := h.PromoRepository.GetPromoCode(r.Context(), id); err != nil {
+		writeLookupError(w, r, err, "promo code")
+		return
+	}
+
+	if err := h.PromoRepository.DeletePromoCode(r.Context(), id); err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: map[string]int{"id": id}}
+	json.NewEncoder(w).Encode(response)
+}
+
+// applyPromo takes the promo code off the quote of a stay booked today by
+// userId, whose own limit of uses is only checked when userId isn't 0. It
+// writes a 422 on the promo_code field, or a 500, and returns false when
+// the code doesn't apply. The limits are checked again when the booking
+// redeems the code.
+func applyPromo(w http.ResponseWriter, r *http.Request, PromoRepository repositories.PromoRepository, p pricing.Pricing, quote pricing.Quote, code string, userId int) (pricing.Quote, models.PromoCode, bool) {
+	promo, err := PromoRepository.GetPromoCodeByCode(r.Context(), strings.ToUpper(strings.TrimSpace(code)))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		writeFieldErrors(w, promoFieldError("exists", "is not a valid promo code"))
+		return quote, promo, false
+	}
+	if err != nil {
+		writeInternalError(w, r, err)
+		return quote, promo, false
+	}
+
+	quote, err = p.ApplyPromo(quote, promo, time.Now())
+	if err != nil {
+		writeFieldErrors(w, promoFieldError("promo", err.Error()))
+		return quote, promo, false
+	}
+
+	if promo.MaxUses > 0 && promo.Uses >= promo.MaxUses {
+		writeFieldErrors(w, promoFieldError("max_uses", "has been used up"))
+		return quote, promo, false
+	}
+
+	if userId != 0 && promo.MaxUsesPerUser > 0 {
+		uses, err := PromoRepository.CountUserRedemptions(r.Context(), promo.ID, userId)
+		if err != nil {
+			writeInternalError(w, r, err)
+			return quote, promo, false
+		}
+		if uses >= promo.MaxUsesPerUser {
+			writeFieldErrors(w, promoFieldError("max_uses_per_user", "has already been used the most times allowed per user"))
+			return quote, promo, false
+		}
+	}
+
+	return quote, promo, true
+}
+
+func promoFieldError(rule, message string) dto.FieldError {
+	return dto.FieldError{Field: "promo_code", Rule: rule, Message: message}
+}
+
+func convertResponsePromoCode(u models.PromoCode) promodto.PromoCodeResponse {
+	return promodto.PromoCodeResponse{
+		ID:             u.ID,
+		Code:           u.Code,
+		Description:    u.Description,
+		Kind:           u.Kind,
+		Amount:         u.Amount,
+		MaxDiscount:    u.MaxDiscount,
+		MinSpend:       u.MinSpend,
+		ValidFrom:      u.ValidFrom,
+		ValidUntil:     u.ValidUntil,
+		MaxUses:        u.MaxUses,
+		MaxUsesPerUser: u.MaxUsesPerUser,
+		Uses:           u.Uses,
+		HouseId:        u.HouseId,
+		CityName:       u.CityName,
+	}
+}
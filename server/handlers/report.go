@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	reportdto "housy/dto/report"
+	dto "housy/dto/result"
+	"housy/repositories"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+type handlerReport struct {
+	ReportRepository repositories.ReportRepository
+	UserRepository   repositories.UserRepository
+}
+
+func HandlerReport(ReportRepository repositories.ReportRepository, UserRepository repositories.UserRepository) *handlerReport {
+	return &handlerReport{ReportRepository, UserRepository}
+}
+
+func (h *handlerReport) Revenue(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	filter, ok := h.reportFilter(w, r)
+	if !ok {
+		return
+	}
+
+	revenue, err := h.ReportRepository.RevenueByMonth(r.Context(), filter)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: revenue}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *handlerReport) Occupancy(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	filter, ok := h.reportFilter(w, r)
+	if !ok {
+		return
+	}
+
+	occupancy, err := h.ReportRepository.Occupancy(r.Context(), filter)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	from, _ := time.Parse("2006-01-02", filter.From)
+	to, _ := time.Parse("2006-01-02", filter.To)
+	available := int(to.Sub(from).Hours()/24) + 1
+	for i := range occupancy {
+		occupancy[i].AvailableNights = available
+		occupancy[i].OccupancyRate = float64(occupancy[i].BookedNights) / float64(available)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: occupancy}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *handlerReport) LengthOfStay(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	filter, ok := h.reportFilter(w, r)
+	if !ok {
+		return
+	}
+
+	stays, err := h.ReportRepository.LengthOfStay(r.Context(), filter)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	summary := reportdto.LengthOfStaySummary{Houses: stays}
+	var nights float64
+	for _, stay := range stays {
+		summary.Bookings += stay.Bookings
+		nights += stay.AverageNights * float64(stay.Bookings)
+	}
+	if summary.Bookings > 0 {
+		summary.AverageNights = nights / float64(summary.Bookings)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: summary}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *handlerReport) Conversion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	filter, ok := h.reportFilter(w, r)
+	if !ok {
+		return
+	}
+
+	counts, err := h.ReportRepository.CountByStatus(r.Context(), filter)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	var conversion reportdto.ConversionResponse
+	for _, count := range counts {
+		conversion.Total += count.Total
+		switch count.StatusPayment {
+		case "pending":
+			conversion.Pending += count.Total
+		case "success":
+			conversion.Success += count.Total
+		case "failed":
+			conversion.Failed += count.Total
+		}
+	}
+	if conversion.Total > 0 {
+		conversion.ConversionRate = float64(conversion.Success) / float64(conversion.Total)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: conversion}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *handlerReport) Cancellations(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	filter, ok := h.reportFilter(w, r)
+	if !ok {
+		return
+	}
+
+	cancellations, err := h.ReportRepository.Cancellations(r.Context(), filter)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: cancellations}
+	json.NewEncoder(w).Encode(response)
+}
+
+// reportFilter reads the house_id, from and to query parameters and scopes
+// owners to their own houses. It writes the error response itself and
+// returns false when the request can't be served.
+func (h *handlerReport) reportFilter(w http.ResponseWriter, r *http.Request) (repositories.ReportFilter, bool) {
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	userId := int(userInfo["id"].(float64))
+
+	var filter repositories.ReportFilter
+
+	user, err := h.UserRepository.GetUser(r.Context(), userId)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return filter, false
+	}
+
+	switch {
+	case isAdmin(user):
+	case strings.EqualFold(user.ListAsRole, "owner"):
+		filter.OwnerId = user.ID
+	default:
+		writeError(w, http.StatusForbidden, "reports are only available to owners and admins")
+		return filter, false
+	}
+
+	query := r.URL.Query()
+
+	if houseId := query.Get("house_id"); houseId != "" {
+		filter.HouseId, err = strconv.Atoi(houseId)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "house_id must be a number")
+			return filter, false
+		}
+	}
+
+	from, to, err := reportRange(query.Get("from"), query.Get("to"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return filter, false
+	}
+	filter.From = from.Format("2006-01-02")
+	filter.To = to.Format("2006-01-02")
+
+	return filter, true
+}
+
+// reportRange parses the from and to dates (YYYY-MM-DD), defaulting to the
+// last twelve months.
+func reportRange(fromValue, toValue string) (time.Time, time.Time, error) {
+	to := time.Now().Truncate(24 * time.Hour)
+	if toValue != "" {
+		parsed, err := time.Parse("2006-01-02", toValue)
+		if err != nil {
+			return to, to, errors.New("to must be a date formatted as YYYY-MM-DD")
+		}
+		to = parsed
+	}
+
+	from := to.AddDate(-1, 0, 1)
+	if fromValue != "" {
+		parsed, err := time.Parse("2006-01-02", fromValue)
+		if err != nil {
+			return from, to, errors.New("from must be a date formatted as YYYY-MM-DD")
+		}
+		from = parsed
+	}
+
+	if from.After(to) {
+		return from, to, errors.New("from must not be after to")
+	}
+
+	return from, to, nil
+}
@@ -0,0 +1,331 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	dto "housy/dto/result"
+	reviewdto "housy/dto/review"
+	"housy/models"
+	"housy/repositories"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/mux"
+)
+
+type handlerReview struct {
+	ReviewRepository      repositories.ReviewRepository
+	TransactionRepository repositories.TransactionRepository
+	HouseRepository       repositories.HouseRepository
+	UserRepository        repositories.UserRepository
+	UnitOfWork            repositories.UnitOfWork
+}
+
+func HandlerReview(ReviewRepository repositories.ReviewRepository, TransactionRepository repositories.TransactionRepository, HouseRepository repositories.HouseRepository, UserRepository repositories.UserRepository, UnitOfWork repositories.UnitOfWork) *handlerReview {
+	return &handlerReview{ReviewRepository, TransactionRepository, HouseRepository, UserRepository, UnitOfWork}
+}
+
+func (h *handlerReview) FindHouseReviews(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	page, paginated, err := pageFromQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if _, err := h.HouseRepository.GetHouse(r.Context(), id); err != nil {
+		writeLookupError(w, r, err, "house")
+		return
+	}
+
+	reviews, err := h.ReviewRepository.FindHouseReviews(r.Context(), id, page)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	if paginated {
+		total, err := h.ReviewRepository.CountHouseReviews(r.Context(), id)
+		if err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+		setTotalCount(w, total)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponseReviews(reviews)}
+	json.NewEncoder(w).Encode(response)
+}
+
+// CreateReview lets the tenant of a paid booking review the stay once they
+// have checked out.
+func (h *handlerReview) CreateReview(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	userId := int(userInfo["id"].(float64))
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	request := new(reviewdto.ReviewRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	if err := validate.Struct(request); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+
+	transaction, err := h.TransactionRepository.GetTransaction(r.Context(), id)
+	if err != nil {
+		writeLookupError(w, r, err, "transaction")
+		return
+	}
+
+	if transaction.UserId != userId {
+		writeError(w, http.StatusForbidden, "only the tenant of the booking can review it")
+		return
+	}
+	if transaction.StatusPayment != "success" {
+		writeError(w, http.StatusConflict, "only paid bookings can be reviewed")
+		return
+	}
+	if transaction.CheckOut > time.Now().Format("2006-01-02") {
+		writeError(w, http.StatusConflict, "the stay can be reviewed after check-out")
+		return
+	}
+
+	reviewed, err := h.ReviewRepository.HasReview(r.Context(), transaction.ID)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	if reviewed {
+		writeError(w, http.StatusConflict, "the stay was already reviewed")
+		return
+	}
+
+	review := models.Review{
+		TransactionId: transaction.ID,
+		HouseId:       transaction.HouseId,
+		UserId:        userId,
+		Cleanliness:   request.Cleanliness,
+		Accuracy:      request.Accuracy,
+		Location:      request.Location,
+		Value:         request.Value,
+		Rating:        float64(request.Cleanliness+request.Accuracy+request.Location+request.Value) / 4,
+		Comment:       request.Comment,
+	}
+
+	err = h.UnitOfWork.WithTx(r.Context(), func(tx repositories.Repositories) error {
+		var err error
+		if review, err = tx.Reviews.CreateReview(r.Context(), review); err != nil {
+			return err
+		}
+		return tx.Reviews.UpdateHouseRating(r.Context(), review.HouseId)
+	})
+	if errors.Is(err, repositories.ErrReviewExists) {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	h.writeReview(w, r, review.ID)
+}
+
+// ReplyReview lets the owner of the house answer a review, replying again
+// replaces the answer.
+func (h *handlerReview) ReplyReview(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	userId := int(userInfo["id"].(float64))
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	request := new(reviewdto.ReplyRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	if err := validate.Struct(request); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+
+	review, err := h.ReviewRepository.GetReview(r.Context(), id)
+	if err != nil {
+		writeLookupError(w, r, err, "review")
+		return
+	}
+
+	house, err := h.HouseRepository.GetHouse(r.Context(), review.HouseId)
+	if err != nil {
+		writeLookupError(w, r, err, "house")
+		return
+	}
+	if house.OwnerId != userId {
+		writeError(w, http.StatusForbidden, "only the owner of the house can reply")
+		return
+	}
+
+	now := time.Now()
+	review.Reply = request.Reply
+	review.RepliedAt = &now
+
+	if _, err := h.ReviewRepository.UpdateReview(r.Context(), review); err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponseReview(review)}
+	json.NewEncoder(w).Encode(response)
+}
+
+// FindHiddenReviews lists the reviews hidden by moderators, so they can be
+// looked at again.
+func (h *handlerReview) FindHiddenReviews(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if _, ok := requireAdmin(w, r, h.UserRepository); !ok {
+		return
+	}
+
+	page, paginated, err := pageFromQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	reviews, err := h.ReviewRepository.FindHiddenReviews(r.Context(), page)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	if paginated {
+		total, err := h.ReviewRepository.CountHiddenReviews(r.Context())
+		if err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+		setTotalCount(w, total)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponseReviews(reviews)}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *handlerReview) HideReview(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if _, ok := requireAdmin(w, r, h.UserRepository); !ok {
+		return
+	}
+
+	request := new(reviewdto.HideRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	if err := validate.Struct(request); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+
+	h.moderate(w, r, true, request.Reason)
+}
+
+func (h *handlerReview) UnhideReview(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if _, ok := requireAdmin(w, r, h.UserRepository); !ok {
+		return
+	}
+
+	h.moderate(w, r, false, "")
+}
+
+// moderate hides or shows the review and updates the rating of its house in
+// the same transaction.
+func (h *handlerReview) moderate(w http.ResponseWriter, r *http.Request, hidden bool, reason string) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	review, err := h.ReviewRepository.GetReview(r.Context(), id)
+	if err != nil {
+		writeLookupError(w, r, err, "review")
+		return
+	}
+
+	review.Hidden = hidden
+	review.HiddenReason = reason
+
+	err = h.UnitOfWork.WithTx(r.Context(), func(tx repositories.Repositories) error {
+		if _, err := tx.Reviews.UpdateReview(r.Context(), review); err != nil {
+			return err
+		}
+		return tx.Reviews.UpdateHouseRating(r.Context(), review.HouseId)
+	})
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	h.writeReview(w, r, review.ID)
+}
+
+func (h *handlerReview) writeReview(w http.ResponseWriter, r *http.Request, id int) {
+	review, err := h.ReviewRepository.GetReview(r.Context(), id)
+	if err != nil {
+		writeLookupError(w, r, err, "review")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponseReview(review)}
+	json.NewEncoder(w).Encode(response)
+}
+
+func convertResponseReviews(reviews []models.Review) []reviewdto.ReviewResponse {
+	responses := make([]reviewdto.ReviewResponse, 0, len(reviews))
+	for _, review := range reviews {
+		responses = append(responses, convertResponseReview(review))
+	}
+	return responses
+}
+
+func convertResponseReview(u models.Review) reviewdto.ReviewResponse {
+	return reviewdto.ReviewResponse{
+		ID:            u.ID,
+		HouseId:       u.HouseId,
+		TransactionId: u.TransactionId,
+		UserId:        u.UserId,
+		Fullname:      u.User.Fullname,
+		Cleanliness:   u.Cleanliness,
+		Accuracy:      u.Accuracy,
+		Location:      u.Location,
+		Value:         u.Value,
+		Rating:        u.Rating,
+		Comment:       u.Comment,
+		Reply:         u.Reply,
+		RepliedAt:     u.RepliedAt,
+		Hidden:        u.Hidden,
+		HiddenReason:  u.HiddenReason,
+		CreatedAt:     u.CreatedAt,
+	}
+}
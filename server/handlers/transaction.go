@@ -1,53 +1,114 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	dto "housy/dto/result"
 	"housy/models"
-	"log"
-	"os"
+	invoicepdf "housy/pkg/invoice"
+	"housy/pkg/mail"
+	"housy/pkg/metrics"
+	"housy/pkg/payment"
+	"housy/pkg/pricing"
+	"housy/pkg/storage"
+	"log/slog"
 	"strconv"
+	"strings"
 	"time"
 
 	transactiondto "housy/dto/transaction"
 	"housy/repositories"
 	"net/http"
+	"net/url"
 
-	"github.com/go-playground/validator/v10"
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/gorilla/mux"
 	"github.com/midtrans/midtrans-go"
-	"github.com/midtrans/midtrans-go/coreapi"
 	"github.com/midtrans/midtrans-go/snap"
-	"gopkg.in/gomail.v2"
 )
 
-var path1_file = "http://localhost:8000/uploads/"
+// transactionMailTopic is the outbox topic of the mails telling tenants
+// about the payment status of their booking.
+const transactionMailTopic = "transaction.mail"
 
-var c = coreapi.Client{
-	ServerKey: os.Getenv("SERVER_KEY"),
-	ClientKey: os.Getenv("CLIENT_KEY"),
-}
+// outboxMaxAttempts is how many times an outbox event is tried before it is
+// marked failed.
+const outboxMaxAttempts = 5
 
 type handlerTransaction struct {
 	TransactionRepository repositories.TransactionRepository
+	InvoiceRepository     repositories.InvoiceRepository
+	UserRepository        repositories.UserRepository
+	HouseRepository       repositories.HouseRepository
+	PricingRepository     repositories.PricingRepository
+	PromoRepository       repositories.PromoRepository
+	OutboxRepository      repositories.OutboxRepository
+	UnitOfWork            repositories.UnitOfWork
+	Mailer                mail.Mailer
+	Payment               payment.Gateway
+	ServerKey             string
+	Storage               storage.Storage
+	Fees                  pricing.Fees
+	mails                 chan struct{}
+}
+
+func HandlerTransaction(TransactionRepository repositories.TransactionRepository, InvoiceRepository repositories.InvoiceRepository, UserRepository repositories.UserRepository, HouseRepository repositories.HouseRepository, PricingRepository repositories.PricingRepository, PromoRepository repositories.PromoRepository, OutboxRepository repositories.OutboxRepository, UnitOfWork repositories.UnitOfWork, Mailer mail.Mailer, Payment payment.Gateway, ServerKey string, Storage storage.Storage, Fees pricing.Fees) *handlerTransaction {
+	return &handlerTransaction{TransactionRepository, InvoiceRepository, UserRepository, HouseRepository, PricingRepository, PromoRepository, OutboxRepository, UnitOfWork, Mailer, Payment, ServerKey, Storage, Fees, make(chan struct{}, 1)}
 }
 
-func HandlerTransaction(TransactionRepository repositories.TransactionRepository) *handlerTransaction {
-	return &handlerTransaction{TransactionRepository}
+// transactionMail is the payload of a transactionMailTopic event.
+type transactionMail struct {
+	TransactionId int    `json:"transaction_id"`
+	Status        string `json:"status"`
+	Invoice       bool   `json:"invoice"`
 }
 
 func (h *handlerTransaction) FindTransaction(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	transactions, err := h.TransactionRepository.FindTransaction()
+	page, paginated, err := pageFromQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	user, err := h.UserRepository.GetUser(r.Context(), int(userInfo["id"].(float64)))
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	// admins see every booking, owners those of their houses and tenants
+	// their own
+	filter := transactionFilter(r.URL.Query())
+	switch {
+	case isAdmin(user):
+	case strings.EqualFold(user.ListAsRole, "owner"):
+		filter.OwnerId = user.ID
+	default:
+		filter.UserId = user.ID
+	}
+
+	transactions, err := h.TransactionRepository.FindTransaction(r.Context(), filter, page)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(err.Error())
+		writeInternalError(w, r, err)
+		return
+	}
+
+	if paginated {
+		total, err := h.TransactionRepository.CountTransactions(r.Context(), filter)
+		if err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+		setTotalCount(w, total)
 	}
 
 	for i, p := range transactions {
-		transactions[i].Attachment = path1_file + p.Attachment
+		transactions[i].Attachment = h.Storage.URL(p.Attachment)
 	}
 
 	w.WriteHeader(http.StatusOK)
@@ -60,78 +121,121 @@ func (h *handlerTransaction) GetTransaction(w http.ResponseWriter, r *http.Reque
 
 	id, _ := strconv.Atoi(mux.Vars(r)["id"])
 
-	// var profile models.Profile
-	transaction, err := h.TransactionRepository.GetTransaction(id)
+	transaction, err := h.TransactionRepository.GetTransaction(r.Context(), id)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
-		json.NewEncoder(w).Encode(response)
+		writeLookupError(w, r, err, "transaction")
 		return
 	}
 
-	transaction.Attachment = path1_file + transaction.Attachment
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	user, err := h.UserRepository.GetUser(r.Context(), int(userInfo["id"].(float64)))
+	if err != nil || !canAccessTransaction(user, transaction) {
+		writeError(w, http.StatusForbidden, "forbidden")
+		return
+	}
+
+	transaction.Attachment = h.Storage.URL(transaction.Attachment)
 
 	w.WriteHeader(http.StatusOK)
 	response := dto.SuccessResult{Code: http.StatusOK, Data: convertResponseTransaction(transaction)}
 	json.NewEncoder(w).Encode(response)
 }
 
+// CreateTransaction books a house for the signed in user.
 func (h *handlerTransaction) CreateTransaction(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	userId := int(userInfo["id"].(float64))
+
 	request := new(transactiondto.RequestTransaction)
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
-		json.NewEncoder(w).Encode(response)
+		writeDecodeError(w, err)
 		return
 	}
 
-	validation := validator.New()
-	err := validation.Struct(request)
+	err := validate.Struct(request)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
-		json.NewEncoder(w).Encode(response)
+		writeValidationError(w, err)
 		return
 	}
 
-	var TransIdIsMatch = false
-	var TransactionId int
-	for !TransIdIsMatch {
-		TransactionId = int(time.Now().Unix())
-		transactionData, _ := h.TransactionRepository.GetTransaction(TransactionId)
-		if transactionData.ID == 0 {
-			TransIdIsMatch = true
-		}
+	checkIn, checkOut, err := stayDates(request.CheckIn, request.CheckOut)
+	if err != nil {
+		writeFieldErrors(w, dto.FieldError{Field: "check_out", Rule: "stay", Message: err.Error()})
+		return
 	}
 
-	transaction := models.Transaction{
-		ID:            TransactionId,
-		CheckIn:       request.CheckIn,
-		CheckOut:      request.CheckOut,
-		HouseId:       request.HouseId,
-		UserId:        request.UserId,
-		Total:         request.Total,
-		StatusPayment: request.StatusPayment,
+	p, ok := loadPricing(w, r, h.PricingRepository, h.HouseRepository, h.Fees, request.HouseId)
+	if !ok {
+		return
 	}
 
-	newTransaction, err := h.TransactionRepository.CreateTransaction(transaction)
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(err.Error())
+	// the stay is priced by the rules of the house, a total sent by the
+	// client is only a check that it showed the same price
+	quote := p.Quote(checkIn, checkOut)
+	if field, ok := minStayFieldError(quote); !ok {
+		writeFieldErrors(w, field)
 		return
 	}
 
-	transaction, _ = h.TransactionRepository.GetTransaction(newTransaction.ID)
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(err.Error())
+	var promo models.PromoCode
+	if request.PromoCode != "" {
+		quote, promo, ok = applyPromo(w, r, h.PromoRepository, p, quote, request.PromoCode, userId)
+		if !ok {
+			return
+		}
+	}
+
+	if request.Total != 0 && request.Total != quote.Total {
+		writeError(w, http.StatusConflict, fmt.Sprintf("the price of the stay is now %d, quote it again", quote.Total))
 		return
 	}
 
-	s := snap.Client{}
-	s.New(os.Getenv("SERVER_KEY"), midtrans.Sandbox)
+	var transaction models.Transaction
+	err = h.UnitOfWork.WithTx(r.Context(), func(tx repositories.Repositories) error {
+		// the database numbers the booking, its id is the order id of the
+		// payment
+		newTransaction, err := tx.Transactions.CreateTransaction(r.Context(), models.Transaction{
+			CheckIn:       request.CheckIn,
+			CheckOut:      request.CheckOut,
+			HouseId:       request.HouseId,
+			UserId:        userId,
+			Total:         quote.Total,
+			PromoCode:     quote.PromoCode,
+			PromoDiscount: quote.PromoDiscount,
+			OwnerPayout:   quote.OwnerPayout,
+			StatusPayment: "pending",
+			Items:         quote.Items(),
+		})
+		if err != nil {
+			return err
+		}
+
+		// the redemption commits with the booking, or neither does
+		if promo.ID != 0 {
+			err := tx.Promos.RedeemPromoCode(r.Context(), promo, models.PromoRedemption{
+				UserId:        newTransaction.UserId,
+				TransactionId: newTransaction.ID,
+				Discount:      quote.PromoDiscount,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		transaction, err = tx.Transactions.GetTransaction(r.Context(), newTransaction.ID)
+		return err
+	})
+	if errors.Is(err, repositories.ErrPromoUsedUp) {
+		writeFieldErrors(w, promoFieldError("max_uses", "has been used up"))
+		return
+	}
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	metrics.BookingsCreated.Inc()
 
 	req := &snap.Request{
 		TransactionDetails: midtrans.TransactionDetails{
@@ -145,79 +249,283 @@ func (h *handlerTransaction) CreateTransaction(w http.ResponseWriter, r *http.Re
 			FName: transaction.User.Fullname,
 			Email: transaction.User.Email,
 		},
+		Items: paymentItems(transaction.Items),
 	}
 
-	snapResp, _ := s.CreateTransaction(req)
+	snapResp, err := h.Payment.CreateTransaction(r.Context(), req)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "creating payment failed", "transaction_id", transaction.ID, "error", err)
+		h.abandonTransaction(r.Context(), transaction.ID)
+		writeError(w, http.StatusBadGateway, "the payment gateway could not create the payment, try again later")
+		return
+	}
 
 	w.WriteHeader(http.StatusOK)
 	response := dto.SuccessResult{Code: http.StatusOK, Data: snapResp}
 	json.NewEncoder(w).Encode(response)
 }
 
+// abandonTransaction fails a booking whose payment couldn't be created and
+// gives its promo code back. Midtrans never heard of the order, so no
+// notification would do it later.
+func (h *handlerTransaction) abandonTransaction(ctx context.Context, transactionId int) {
+	err := h.UnitOfWork.WithTx(ctx, func(tx repositories.Repositories) error {
+		if err := tx.Transactions.UpdateTransaction(ctx, "failed", strconv.Itoa(transactionId)); err != nil {
+			return err
+		}
+		return tx.Promos.ReleasePromoRedemption(ctx, transactionId)
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failing the unpaid transaction failed", "transaction_id", transactionId, "error", err)
+	}
+}
+
 func (h *handlerTransaction) Notification(w http.ResponseWriter, r *http.Request) {
 	var notificationPayload map[string]interface{}
 
 	err := json.NewDecoder(r.Body).Decode(&notificationPayload)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
-		json.NewEncoder(w).Encode(response)
+		writeDecodeError(w, err)
 		return
 	}
 
-	transactionStatus := notificationPayload["transaction_status"].(string)
-	fraudStatus := notificationPayload["fraud_status"].(string)
-	orderId := notificationPayload["order_id"].(string)
+	transactionStatus, _ := notificationPayload["transaction_status"].(string)
+	fraudStatus, _ := notificationPayload["fraud_status"].(string)
+	orderId, _ := notificationPayload["order_id"].(string)
+	paymentType, _ := notificationPayload["payment_type"].(string)
+	statusCode, _ := notificationPayload["status_code"].(string)
+	grossAmount, _ := notificationPayload["gross_amount"].(string)
+	signature, _ := notificationPayload["signature_key"].(string)
+	if transactionStatus == "" || orderId == "" {
+		writeError(w, http.StatusBadRequest, "transaction_status and order_id are required")
+		return
+	}
+
+	// anyone can post here, only Midtrans knows the server key
+	if !payment.VerifySignature(h.ServerKey, orderId, statusCode, grossAmount, signature) {
+		writeError(w, http.StatusForbidden, "invalid signature_key")
+		return
+	}
+
+	transaction, err := h.TransactionRepository.GetOneTransaction(r.Context(), orderId)
+	if err != nil {
+		writeLookupError(w, r, err, "transaction")
+		return
+	}
+
+	status := paymentOutcome(transactionStatus, fraudStatus)
+	// a paid booking stays paid, its invoice is issued and its promo code
+	// is used, whatever the notifications arriving late or out of order say
+	if transaction.StatusPayment == "success" {
+		status = "ignored"
+	}
+	if status != "ignored" {
+		// the new status, the invoice and the mail commit together, so a
+		// notification retried by Midtrans after an error doesn't mail twice
+		err = h.UnitOfWork.WithTx(r.Context(), func(tx repositories.Repositories) error {
+			if err := tx.Transactions.UpdateTransaction(r.Context(), status, orderId); err != nil {
+				return err
+			}
+
+			// a booking that won't be paid gives its promo code back
+			if status == "failed" {
+				if err := tx.Promos.ReleasePromoRedemption(r.Context(), transaction.ID); err != nil {
+					return err
+				}
+			}
+
+			if status == "success" {
+				_, err := tx.Invoices.CreateInvoice(r.Context(), models.Invoice{
+					TransactionId: transaction.ID,
+					PaymentMethod: paymentType,
+					IssuedAt:      time.Now(),
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			payload, err := json.Marshal(transactionMail{TransactionId: transaction.ID, Status: status, Invoice: status == "success"})
+			if err != nil {
+				return err
+			}
+			_, err = tx.Outbox.AddOutboxEvent(r.Context(), models.OutboxEvent{Topic: transactionMailTopic, Payload: string(payload)})
+			return err
+		})
+		if err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+
+		// wake the mail worker up, it is already awake when the channel is full
+		select {
+		case h.mails <- struct{}{}:
+		default:
+		}
+	}
+
+	metrics.PaymentOutcomes.WithLabelValues(status).Inc()
+}
+
+// paymentOutcome maps a Midtrans notification to the status it gives the
+// transaction, keeping the metric labels to a fixed set.
+func paymentOutcome(transactionStatus, fraudStatus string) string {
+	switch transactionStatus {
+	case "capture":
+		switch fraudStatus {
+		case "challenge":
+			return "pending"
+		case "accept":
+			return "success"
+		}
+	case "settlement":
+		return "success"
+	case "deny", "cancel", "expire":
+		return "failed"
+	case "pending":
+		return "pending"
+	}
+	return "ignored"
+}
+
+// SendMails delivers the transaction mails of the outbox until ctx is done.
+// The outbox is checked every interval and whenever a notification queued a
+// mail. A mail being sent when ctx is done is finished.
+func (h *handlerTransaction) SendMails(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		h.sendDueMails(context.WithoutCancel(ctx))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-h.mails:
+		}
+	}
+}
+
+// OutboxDepth is the number of mails waiting in the outbox.
+func (h *handlerTransaction) OutboxDepth() int {
+	count, err := h.OutboxRepository.CountPendingOutboxEvents(context.Background(), transactionMailTopic)
+	if err != nil {
+		slog.Error("counting outbox events failed", "error", err)
+	}
+	return int(count)
+}
 
-	transaction, err := h.TransactionRepository.GetOneTransaction(orderId)
+func (h *handlerTransaction) sendDueMails(ctx context.Context) {
+	events, err := h.OutboxRepository.FindDueOutboxEvents(ctx, transactionMailTopic, 100)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
-		json.NewEncoder(w).Encode(response)
+		slog.Error("loading outbox events failed", "error", err)
 		return
 	}
 
-	if transactionStatus == "capture" {
-		if fraudStatus == "challenge" {
-			SendMail("success", transaction)
-			h.TransactionRepository.UpdateTransaction("pending", orderId)
-		} else if fraudStatus == "accept" {
-			SendMail("success", transaction)
-			h.TransactionRepository.UpdateTransaction("success", orderId)
+	for _, event := range events {
+		err := h.deliverMail(ctx, event)
+
+		event.Attempts++
+		if err == nil {
+			now := time.Now()
+			event.Status = "done"
+			event.Error = ""
+			event.ProcessedAt = &now
+		} else {
+			slog.Error("sending transaction mail failed", "event_id", event.ID, "attempts", event.Attempts, "error", err)
+			event.Error = err.Error()
+			if event.Attempts >= outboxMaxAttempts {
+				event.Status = "failed"
+			} else {
+				// back off 1, 4, 9 then 16 minutes
+				event.AvailableAt = time.Now().Add(time.Duration(event.Attempts*event.Attempts) * time.Minute)
+			}
+		}
+
+		if _, err := h.OutboxRepository.UpdateOutboxEvent(ctx, event); err != nil {
+			slog.Error("saving outbox event failed", "event_id", event.ID, "error", err)
+		}
+	}
+}
+
+func (h *handlerTransaction) deliverMail(ctx context.Context, event models.OutboxEvent) error {
+	var payload transactionMail
+	if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+		return err
+	}
+
+	transaction, err := h.TransactionRepository.GetTransaction(ctx, payload.TransactionId)
+	if err != nil {
+		return err
+	}
+
+	var invoice []byte
+	if payload.Invoice {
+		issued, err := h.InvoiceRepository.GetInvoiceByTransaction(ctx, transaction.ID)
+		if err != nil {
+			return err
+		}
+		invoice, err = invoicepdf.Generate(issued, issued.Transaction)
+		if err != nil {
+			return err
 		}
-	} else if transactionStatus == "settlement" {
-		SendMail("success", transaction)
-		h.TransactionRepository.UpdateTransaction("success", orderId)
-	} else if transactionStatus == "deny" {
-		SendMail("success", transaction)
-		h.TransactionRepository.UpdateTransaction("failed", orderId)
-	} else if transactionStatus == "cancel" || transactionStatus == "expire" {
-		SendMail("success", transaction)
-		h.TransactionRepository.UpdateTransaction("failed", orderId)
-	} else if transactionStatus == "pending" {
-		SendMail("success", transaction)
-		h.TransactionRepository.UpdateTransaction("pending", orderId)
 	}
 
+	if err := h.sendMail(ctx, payload.Status, transaction, invoice); err != nil {
+		return err
+	}
+
+	slog.Info("transaction mail sent", "transaction_id", transaction.ID)
+	return nil
 }
 
-func SendMail(status string, transaction models.Transaction) {
+func (h *handlerTransaction) GetInvoice(w http.ResponseWriter, r *http.Request) {
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	userId := int(userInfo["id"].(float64))
 
-	if status != transaction.StatusPayment && (status == "success") {
-		var CONFIG_SMTP_HOST = "smtp.gmail.com"
-		var CONFIG_SMTP_PORT = 587
-		var CONFIG_SENDER_NAME = "Housy <neysatarigan@gmail.com>"
-		var CONFIG_AUTH_EMAIL = os.Getenv("EMAIL_SYSTEM")
-		var CONFIG_AUTH_PASSWORD = os.Getenv("PASSWORD_SYSTEM")
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
 
-		var productName = transaction.House.Name
-		var price = strconv.Itoa(transaction.House.Price)
+	invoice, err := h.InvoiceRepository.GetInvoiceByTransaction(r.Context(), id)
+	if err != nil {
+		writeLookupError(w, r, err, "invoice")
+		return
+	}
+
+	user, err := h.UserRepository.GetUser(r.Context(), userId)
+	if err != nil || !canAccessTransaction(user, invoice.Transaction) {
+		writeError(w, http.StatusForbidden, "forbidden")
+		return
+	}
+
+	file, err := invoicepdf.Generate(invoice, invoice.Transaction)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="invoice-%d.pdf"`, invoice.TransactionId))
+	w.WriteHeader(http.StatusOK)
+	w.Write(file)
+}
+
+// canAccessTransaction allows the tenant, the owner of the house and admins.
+func canAccessTransaction(user models.User, transaction models.Transaction) bool {
+	return isAdmin(user) ||
+		user.ID == transaction.UserId ||
+		(transaction.House.OwnerId != 0 && user.ID == transaction.House.OwnerId)
+}
+
+func (h *handlerTransaction) sendMail(ctx context.Context, status string, transaction models.Transaction, invoice []byte) error {
+	var productName = transaction.House.Name
+	var price = strconv.Itoa(transaction.House.Price)
 
-		mailer := gomail.NewMessage()
-		mailer.SetHeader("From", CONFIG_SENDER_NAME)
-		mailer.SetHeader("To", transaction.User.Email)
-		mailer.SetHeader("Subject", "Transaction Status")
-		mailer.SetBody("text/html", fmt.Sprintf(`<!DOCTYPE html>
+	message := mail.Message{
+		To:      transaction.User.Email,
+		Subject: "Transaction Status",
+	}
+	message.HTML = fmt.Sprintf(`<!DOCTYPE html>
 	  <html>
 	  <head>
 		<meta name="viewport" content="width=device-width, initial-scale=1.0"/>
@@ -576,22 +884,16 @@ func SendMail(status string, transaction models.Transaction) {
 		  </tr>
 		</table>
 	  </body>
-	</html>`, productName, price, status))
-
-		dialer := gomail.NewDialer(
-			CONFIG_SMTP_HOST,
-			CONFIG_SMTP_PORT,
-			CONFIG_AUTH_EMAIL,
-			CONFIG_AUTH_PASSWORD,
-		)
+	</html>`, productName, price, status)
 
-		err := dialer.DialAndSend(mailer)
-		if err != nil {
-			log.Fatal(err.Error())
-		}
-
-		log.Println("Mail sent! to " + transaction.User.Email)
+	if invoice != nil {
+		message.Attachments = append(message.Attachments, mail.Attachment{
+			Name:    fmt.Sprintf("invoice-%d.pdf", transaction.ID),
+			Content: invoice,
+		})
 	}
+
+	return h.Mailer.Send(ctx, message)
 }
 
 func (h *handlerTransaction) DeleteTransaction(w http.ResponseWriter, r *http.Request) {
@@ -599,19 +901,23 @@ func (h *handlerTransaction) DeleteTransaction(w http.ResponseWriter, r *http.Re
 
 	id, _ := strconv.Atoi(mux.Vars(r)["id"])
 
-	transaction, err := h.TransactionRepository.GetTransaction(id)
+	transaction, err := h.TransactionRepository.GetTransaction(r.Context(), id)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
-		json.NewEncoder(w).Encode(response)
+		writeLookupError(w, r, err, "transaction")
+		return
+	}
+
+	// the owner of the house can't take a booking back, only the tenant
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	user, err := h.UserRepository.GetUser(r.Context(), int(userInfo["id"].(float64)))
+	if err != nil || !(isAdmin(user) || user.ID == transaction.UserId) {
+		writeError(w, http.StatusForbidden, "only the tenant and admins can delete a transaction")
 		return
 	}
 
-	data, err := h.TransactionRepository.DeleteTransaction(transaction)
+	data, err := h.TransactionRepository.DeleteTransaction(r.Context(), transaction)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
-		json.NewEncoder(w).Encode(response)
+		writeInternalError(w, r, err)
 		return
 	}
 
@@ -620,6 +926,20 @@ func (h *handlerTransaction) DeleteTransaction(w http.ResponseWriter, r *http.Re
 	json.NewEncoder(w).Encode(response)
 }
 
+// transactionFilter reads the transaction list filters from the query string.
+func transactionFilter(query url.Values) repositories.TransactionFilter {
+	houseId, _ := strconv.Atoi(query.Get("house_id"))
+	userId, _ := strconv.Atoi(query.Get("user_id"))
+
+	return repositories.TransactionFilter{
+		StatusPayment: query.Get("status_payment"),
+		HouseId:       houseId,
+		UserId:        userId,
+		From:          query.Get("from"),
+		To:            query.Get("to"),
+	}
+}
+
 func convertResponseTransaction(u models.Transaction) transactiondto.ResponseTransaction {
 	return transactiondto.ResponseTransaction{
 		CheckIn:       u.CheckIn,
@@ -627,6 +947,47 @@ func convertResponseTransaction(u models.Transaction) transactiondto.ResponseTra
 		HouseId:       u.HouseId,
 		UserId:        u.UserId,
 		Total:         u.Total,
+		PromoCode:     u.PromoCode,
+		PromoDiscount: u.PromoDiscount,
+		OwnerPayout:   u.OwnerPayout,
 		StatusPayment: u.StatusPayment,
+		Items:         convertResponseItems(u.Items),
+	}
+}
+
+func convertResponseItems(items []models.TransactionItem) []transactiondto.ItemResponse {
+	responses := make([]transactiondto.ItemResponse, 0, len(items))
+	for _, item := range items {
+		responses = append(responses, transactiondto.ItemResponse{
+			Kind:        item.Kind,
+			Description: item.Description,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			Amount:      item.Amount,
+		})
+	}
+	return responses
+}
+
+// paymentItems lists the lines of a booking for the payment page, they add
+// up to its total. Midtrans cuts names longer than 50 characters.
+func paymentItems(items []models.TransactionItem) *[]midtrans.ItemDetails {
+	if len(items) == 0 {
+		return nil
+	}
+
+	details := make([]midtrans.ItemDetails, 0, len(items))
+	for _, item := range items {
+		name := item.Description
+		if runes := []rune(name); len(runes) > 50 {
+			name = string(runes[:50])
+		}
+		details = append(details, midtrans.ItemDetails{
+			ID:    item.Kind,
+			Name:  name,
+			Price: int64(item.UnitPrice),
+			Qty:   int32(item.Quantity),
+		})
 	}
+	return &details
 }
@@ -9,8 +9,10 @@ import (
 	"housy/repositories"
 	"net/http"
 	"strconv"
+	"strings"
 
 	// "github.com/go-playground/validator/v10"
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/gorilla/mux"
 )
 
@@ -25,10 +27,10 @@ func HandlerUser(UserRepository repositories.UserRepository) *handlerUser {
 func (h *handlerUser) FindUsers(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	users, err := h.UserRepository.FindUsers()
+	users, err := h.UserRepository.FindUsers(r.Context())
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(err.Error())
+		writeInternalError(w, r, err)
+		return
 	}
 
 	w.WriteHeader(http.StatusOK)
@@ -41,11 +43,9 @@ func (h *handlerUser) GetUser(w http.ResponseWriter, r *http.Request) {
 
 	id, _ := strconv.Atoi(mux.Vars(r)["id"])
 
-	user, err := h.UserRepository.GetUser(id)
+	user, err := h.UserRepository.GetUser(r.Context(), id)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
-		json.NewEncoder(w).Encode(response)
+		writeLookupError(w, r, err, "user")
 		return
 	}
 
@@ -105,26 +105,19 @@ func (h *handlerUser) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	request := new(usersdto.RequestUser)
 
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
-		json.NewEncoder(w).Encode(response)
+		writeDecodeError(w, err)
 		return
 	}
 
 	id, _ := strconv.Atoi((mux.Vars(r)["id"]))
-	user, err := h.UserRepository.GetUser(int(id))
-	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
-		json.NewEncoder(w).Encode(response)
+	if !requireSelfOrAdmin(w, r, h.UserRepository, id) {
 		return
 	}
 
-	password, err := bcrypt.HashingPassword(request.Password)
+	user, err := h.UserRepository.GetUser(r.Context(), int(id))
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
-		json.NewEncoder(w).Encode(response)
+		writeLookupError(w, r, err, "user")
+		return
 	}
 
 	if request.Fullname != "" {
@@ -139,14 +132,15 @@ func (h *handlerUser) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		user.Email = request.Email
 	}
 
-	if password != "" {
+	if request.Password != "" {
+		password, err := bcrypt.HashingPassword(request.Password)
+		if err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
 		user.Password = password
 	}
 
-	if request.ListAsRole != "" {
-		user.ListAsRole = request.ListAsRole
-	}
-
 	if request.Gender != "" {
 		user.Gender = request.Gender
 	}
@@ -163,11 +157,9 @@ func (h *handlerUser) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	// 	user.Image = request.Image
 	// }
 
-	data, err := h.UserRepository.UpdateUser(user, id)
+	data, err := h.UserRepository.UpdateUser(r.Context(), user, id)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
-		json.NewEncoder(w).Encode(response)
+		writeInternalError(w, r, err)
 		return
 	}
 
@@ -190,24 +182,85 @@ func convertResponse(u models.User) usersdto.UserResponse {
 	}
 }
 
+func isAdmin(u models.User) bool {
+	return strings.EqualFold(u.ListAsRole, "admin")
+}
+
+// callerId returns the id of the signed in user on routes behind
+// middleware.OptionalAuth, false for anonymous requests.
+func callerId(r *http.Request) (int, bool) {
+	userInfo, ok := r.Context().Value("userInfo").(jwt.MapClaims)
+	if !ok {
+		return 0, false
+	}
+	id, ok := userInfo["id"].(float64)
+	return int(id), ok
+}
+
+// requireAdmin returns the signed in user when they are an admin, otherwise
+// it writes a forbidden response and returns false.
+func requireAdmin(w http.ResponseWriter, r *http.Request, UserRepository repositories.UserRepository) (models.User, bool) {
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	userId := int(userInfo["id"].(float64))
+
+	user, err := UserRepository.GetUser(r.Context(), userId)
+	if err != nil || !isAdmin(user) {
+		writeError(w, http.StatusForbidden, "only admins can do this")
+		return user, false
+	}
+
+	return user, true
+}
+
+// requireSelfOrAdmin reports whether the signed in user is the user with the
+// given id or an admin, otherwise it writes a forbidden response.
+func requireSelfOrAdmin(w http.ResponseWriter, r *http.Request, UserRepository repositories.UserRepository, id int) bool {
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	userId := int(userInfo["id"].(float64))
+	if userId == id {
+		return true
+	}
+
+	user, err := UserRepository.GetUser(r.Context(), userId)
+	if err != nil || !isAdmin(user) {
+		writeError(w, http.StatusForbidden, "only the user and admins can do this")
+		return false
+	}
+
+	return true
+}
+
+// requireOwner is requireAdmin for what owners may do as well.
+func requireOwner(w http.ResponseWriter, r *http.Request, UserRepository repositories.UserRepository) (models.User, bool) {
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	userId := int(userInfo["id"].(float64))
+
+	user, err := UserRepository.GetUser(r.Context(), userId)
+	if err != nil || !(isAdmin(user) || strings.EqualFold(user.ListAsRole, "owner")) {
+		writeError(w, http.StatusForbidden, "only owners and admins can do this")
+		return user, false
+	}
+
+	return user, true
+}
+
 func (h *handlerUser) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	if !requireSelfOrAdmin(w, r, h.UserRepository, id) {
+		return
+	}
 
-	user, err := h.UserRepository.GetUser(id)
+	user, err := h.UserRepository.GetUser(r.Context(), id)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		response := dto.ErrorResult{Code: http.StatusBadRequest, Message: err.Error()}
-		json.NewEncoder(w).Encode(response)
+		writeLookupError(w, r, err, "user")
 		return
 	}
 
-	data, err := h.UserRepository.DeleteUser(user, id)
+	data, err := h.UserRepository.DeleteUser(r.Context(), user, id)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		response := dto.ErrorResult{Code: http.StatusInternalServerError, Message: err.Error()}
-		json.NewEncoder(w).Encode(response)
+		writeInternalError(w, r, err)
 		return
 	}
 
@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"encoding/json"
+	housesdto "housy/dto/house"
+	dto "housy/dto/result"
+	wishlistdto "housy/dto/wishlist"
+	"housy/models"
+	"housy/pkg/storage"
+	"housy/repositories"
+	"net/http"
+	"strconv"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/mux"
+)
+
+type handlerWishlist struct {
+	WishlistRepository repositories.WishlistRepository
+	HouseRepository    repositories.HouseRepository
+	Storage            storage.Storage
+}
+
+func HandlerWishlist(WishlistRepository repositories.WishlistRepository, HouseRepository repositories.HouseRepository, Storage storage.Storage) *handlerWishlist {
+	return &handlerWishlist{WishlistRepository, HouseRepository, Storage}
+}
+
+func (h *handlerWishlist) FindMyWishlists(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	userId := int(userInfo["id"].(float64))
+
+	wishlists, err := h.WishlistRepository.FindWishlists(r.Context(), userId)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	responses := make([]wishlistdto.WishlistResponse, 0, len(wishlists))
+	for _, wishlist := range wishlists {
+		response, err := h.convertResponseWishlist(r, wishlist)
+		if err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+		responses = append(responses, response)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: responses}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *handlerWishlist) CreateWishlist(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	userId := int(userInfo["id"].(float64))
+
+	request := new(wishlistdto.WishlistRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	if err := validate.Struct(request); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+
+	wishlist, err := h.WishlistRepository.CreateWishlist(r.Context(), models.Wishlist{UserId: userId, Name: request.Name})
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	h.writeWishlist(w, r, wishlist.ID)
+}
+
+// UpdateWishlist renames the wishlist.
+func (h *handlerWishlist) UpdateWishlist(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	request := new(wishlistdto.WishlistRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	if err := validate.Struct(request); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+
+	wishlist, ok := h.ownWishlist(w, r)
+	if !ok {
+		return
+	}
+
+	wishlist.Name = request.Name
+	if _, err := h.WishlistRepository.UpdateWishlist(r.Context(), wishlist); err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	h.writeWishlist(w, r, wishlist.ID)
+}
+
+func (h *handlerWishlist) DeleteWishlist(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	wishlist, ok := h.ownWishlist(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.WishlistRepository.DeleteWishlist(r.Context(), wishlist.ID); err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: map[string]int{"id": wishlist.ID}}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *handlerWishlist) AddHouse(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	wishlist, ok := h.ownWishlist(w, r)
+	if !ok {
+		return
+	}
+
+	houseId, _ := strconv.Atoi(mux.Vars(r)["houseId"])
+	if _, err := h.HouseRepository.GetHouse(r.Context(), houseId); err != nil {
+		writeLookupError(w, r, err, "house")
+		return
+	}
+
+	if err := h.WishlistRepository.AddWishlistHouse(r.Context(), wishlist.ID, houseId); err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	h.writeWishlist(w, r, wishlist.ID)
+}
+
+func (h *handlerWishlist) RemoveHouse(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	wishlist, ok := h.ownWishlist(w, r)
+	if !ok {
+		return
+	}
+
+	houseId, _ := strconv.Atoi(mux.Vars(r)["houseId"])
+	if err := h.WishlistRepository.RemoveWishlistHouse(r.Context(), wishlist.ID, houseId); err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	h.writeWishlist(w, r, wishlist.ID)
+}
+
+// ownWishlist loads the wishlist of the route, writing an error response
+// unless it belongs to the signed in user.
+func (h *handlerWishlist) ownWishlist(w http.ResponseWriter, r *http.Request) (models.Wishlist, bool) {
+	userInfo := r.Context().Value("userInfo").(jwt.MapClaims)
+	userId := int(userInfo["id"].(float64))
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	wishlist, err := h.WishlistRepository.GetWishlist(r.Context(), id)
+	if err != nil {
+		writeLookupError(w, r, err, "wishlist")
+		return wishlist, false
+	}
+	if wishlist.UserId != userId {
+		writeError(w, http.StatusForbidden, "forbidden")
+		return wishlist, false
+	}
+
+	return wishlist, true
+}
+
+func (h *handlerWishlist) writeWishlist(w http.ResponseWriter, r *http.Request, id int) {
+	wishlist, err := h.WishlistRepository.GetWishlist(r.Context(), id)
+	if err != nil {
+		writeLookupError(w, r, err, "wishlist")
+		return
+	}
+
+	data, err := h.convertResponseWishlist(r, wishlist)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	response := dto.SuccessResult{Code: http.StatusOK, Data: data}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *handlerWishlist) convertResponseWishlist(r *http.Request, wishlist models.Wishlist) (wishlistdto.WishlistResponse, error) {
+	for i, house := range wishlist.Houses {
+		wishlist.Houses[i].Image = h.Storage.URL(house.Image)
+	}
+	if err := markFavorites(r, h.WishlistRepository, wishlist.Houses); err != nil {
+		return wishlistdto.WishlistResponse{}, err
+	}
+
+	houses := make([]housesdto.ResponseHouse, 0, len(wishlist.Houses))
+	for _, house := range wishlist.Houses {
+		houses = append(houses, convertResponseHouse(house))
+	}
+
+	return wishlistdto.WishlistResponse{
+		ID:        wishlist.ID,
+		Name:      wishlist.Name,
+		Houses:    houses,
+		CreatedAt: wishlist.CreatedAt,
+	}, nil
+}
+
+// markFavorites sets IsFavorited on the houses for the signed in caller, and
+// FavoriteCount on the ones they own. Anonymous callers get neither.
+func markFavorites(r *http.Request, wishlists repositories.WishlistRepository, houses []models.House) error {
+	userId, ok := callerId(r)
+	if !ok || len(houses) == 0 {
+		return nil
+	}
+
+	var ids, owned []int
+	for _, house := range houses {
+		ids = append(ids, house.ID)
+		if house.OwnerId == userId {
+			owned = append(owned, house.ID)
+		}
+	}
+
+	favorited, err := wishlists.FavoritedHouses(r.Context(), userId, ids)
+	if err != nil {
+		return err
+	}
+
+	counts := map[int]int{}
+	if len(owned) > 0 {
+		if counts, err = wishlists.CountFavorites(r.Context(), owned); err != nil {
+			return err
+		}
+	}
+
+	for i, house := range houses {
+		houses[i].IsFavorited = favorited[house.ID]
+		if house.OwnerId == userId {
+			count := counts[house.ID]
+			houses[i].FavoriteCount = &count
+		}
+	}
+	return nil
+}
@@ -1,43 +1,100 @@
 package main
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"housy/app"
+	"housy/config"
 	"housy/database"
-	"housy/pkg/mysql"
-	"housy/routes"
+	"housy/pkg/tracing"
+	"log/slog"
 	"net/http"
+	"os"
 
-	"github.com/gorilla/handlers"
-	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
 )
 
 func main() {
 
-	// env
-	errEnv := godotenv.Load()
-	if errEnv != nil {
-		panic("Failed to load env file")
+	// env, a missing .env file only means everything comes from the real environment
+	envErr := godotenv.Load()
+
+	cfg, args, err := config.Load(os.Args[1:])
+
+	// migrate new doesn't need a database connection, nor a valid config
+	if len(args) > 1 && args[0] == "migrate" && args[1] == "new" {
+		if err := database.Command(nil, args[1:], os.Stdout); err != nil {
+			slog.Error("migrate new failed", "error", err)
+			os.Exit(1)
+		}
+		return
 	}
 
-	mysql.DatabaseInit()
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
 
-	// run migration
-	database.RunMigration()
+	a, err := app.New(cfg)
+	if err != nil {
+		slog.Error("connecting to the database failed", "driver", cfg.Database.Driver, "error", err)
+		os.Exit(1)
+	}
+	// everything logging through slog or the log package gets the same format and redaction
+	slog.SetDefault(a.Logger)
 
-	r := mux.NewRouter()
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.TracingConfig())
+	if err != nil {
+		slog.Error("setting up tracing failed", "error", err)
+		os.Exit(1)
+	}
 
-	routes.RouteInit(r.PathPrefix("/api/v1").Subrouter())
-	r.PathPrefix("/uploads").Handler(http.StripPrefix("/uploads/", http.FileServer(http.Dir("./uploads")))) // add this code
+	if envErr != nil {
+		slog.Info("no .env file loaded", "error", envErr)
+	}
+	slog.Info("connected to the database", "driver", cfg.Database.Driver)
 
-	// Setup allowed Header, Method, and Origin for CORS on this below code ...
-	var AllowedHeaders = handlers.AllowedHeaders([]string{"X-Requested-With", "Content-Type", "Authorization"})
-	var AllowedMethods = handlers.AllowedMethods([]string{"GET", "POST", "PUT", "HEAD", "OPTIONS", "PATCH", "DELETE"})
-	var AllowedOrigins = handlers.AllowedOrigins([]string{"*"})
+	// migrate subcommand: go run . migrate up | down [steps] | status | new <name>
+	if len(args) > 0 && args[0] == "migrate" {
+		err := database.Command(a.DB, args[1:], os.Stdout)
+		a.Close()
+		if err != nil {
+			slog.Error("migrate failed", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
 
-	var port = "8000"
-	fmt.Println("server running localhost:" + port)
+	// openapi subcommand: go run . openapi [check]
+	if len(args) > 0 && args[0] == "openapi" {
+		err := openapiCommand(a, args[1:], os.Stdout)
+		a.Close()
+		if err != nil {
+			slog.Error("openapi failed", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
 
-	// Embed the setup allowed in 2 parameter on this below code ...
-	http.ListenAndServe("localhost:"+port, handlers.CORS(AllowedHeaders, AllowedMethods, AllowedOrigins)(r))
+	// run migration
+	database.RunMigration(a.DB)
+
+	if err := a.OpenSearch(); err != nil {
+		slog.Error("opening the search index failed", "dir", cfg.Search.Dir, "error", err)
+		os.Exit(1)
+	}
+
+	err = serve(a)
+
+	// flush the spans of the last requests
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Error("flushing traces failed", "error", err)
+	}
+
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("server failed", "error", err)
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Amenity is an entry of the amenities catalog houses pick from. Code is the
+// stable identifier used by forms and search filters.
+type Amenity struct {
+	ID        int       `json:"id" gorm:"primary_key:auto_increment"`
+	Code      string    `json:"code" gorm:"type: varchar(64);uniqueIndex"`
+	Label     string    `json:"label" gorm:"type: varchar(255)"`
+	Icon      string    `json:"icon" gorm:"type: varchar(255)"`
+	Category  string    `json:"category" gorm:"type: varchar(64)"`
+	CreatedAt time.Time `json:"-"`
+	UpdatedAt time.Time `json:"-"`
+}
+
+// HouseAmenity is the join row of an amenity of a house.
+type HouseAmenity struct {
+	HouseId   int `gorm:"primaryKey"`
+	AmenityId int `gorm:"primaryKey;index"`
+}
+
+func (HouseAmenity) TableName() string {
+	return "house_amenities"
+}
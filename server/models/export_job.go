@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+type ExportJob struct {
+	ID        int       `json:"id" gorm:"primary_key:auto_increment"`
+	UserId    int       `json:"user_id"`
+	Resource  string    `json:"resource" gorm:"type: varchar(255)"`
+	Format    string    `json:"format" gorm:"type: varchar(255)"`
+	Query     string    `json:"query" gorm:"type: text"`
+	Status    string    `json:"status" gorm:"type: varchar(255)"`
+	File      string    `json:"-" gorm:"type: varchar(255)"`
+	Error     string    `json:"error" gorm:"type: text"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
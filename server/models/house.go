@@ -3,7 +3,7 @@ package models
 import (
 	"time"
 
-	"gorm.io/datatypes"
+	"gorm.io/gorm"
 )
 
 type House struct {
@@ -11,16 +11,32 @@ type House struct {
 	Name        string         `json:"name" gorm:"type: varchar(255)"`
 	CityName    string         `json:"cityname" gorm:"type: varchar(255)"`
 	Address     string         `json:"address" gorm:"type: text"`
+	Latitude    *float64       `json:"latitude" gorm:"index:idx_houses_location,priority:1"`
+	Longitude   *float64       `json:"longitude" gorm:"index:idx_houses_location,priority:2"`
 	Price       int            `json:"price" gorm:"type: int"`
+	CleaningFee int            `json:"cleaning_fee"`
+	Deposit     int            `json:"deposit"`
 	TypeRent    string         `json:"type_rent" gorm:"type: varchar(255)"`
-	Amenities   datatypes.JSON `json:"amenities" gorm:"type: json"`
+	Amenities   []Amenity      `json:"amenities" gorm:"many2many:house_amenities"`
 	Bedroom     int            `json:"bedroom" gorm:"type: int"`
 	Bathroom    int            `json:"bathroom" gorm:"type: int"`
-	Area        string         `json:"area" grom:"type: text"`
-	Description string         `json:"description" grom:"type: int"`
+	Area        string         `json:"area" gorm:"type: text"`
+	Description string         `json:"description" gorm:"type: text"`
 	Image       string         `json:"image" gorm:"type: varchar(255)"`
+	OwnerId     int            `json:"owner_id" gorm:"type: int"`
+	Rating      float64        `json:"rating"`
+	ReviewCount int            `json:"review_count"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// IsFavorited, FavoriteCount, DistanceKm, Score and Highlights depend on
+	// the request and aren't stored.
+	IsFavorited   bool              `json:"is_favorited" gorm:"-"`
+	FavoriteCount *int              `json:"favorite_count,omitempty" gorm:"-"`
+	DistanceKm    *float64          `json:"distance_km,omitempty" gorm:"-"`
+	Score         *float64          `json:"score,omitempty" gorm:"-"`
+	Highlights    map[string]string `json:"highlights,omitempty" gorm:"-"`
 }
 
 func (House) TableName() string {
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+type Invoice struct {
+	ID            int         `json:"id" gorm:"primary_key:auto_increment"`
+	Number        string      `json:"number" gorm:"type: varchar(255);uniqueIndex"`
+	TransactionId int         `json:"transaction_id" gorm:"uniqueIndex"`
+	Transaction   Transaction `json:"transaction"`
+	PaymentMethod string      `json:"payment_method" gorm:"type: varchar(255)"`
+	IssuedAt      time.Time   `json:"issued_at"`
+	CreatedAt     time.Time   `json:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+}
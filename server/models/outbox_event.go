@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// OutboxEvent is a side effect, such as an email, recorded in the same
+// database transaction as the change that causes it and delivered after the
+// commit by a background worker.
+type OutboxEvent struct {
+	ID          int        `json:"id" gorm:"primary_key:auto_increment"`
+	Topic       string     `json:"topic" gorm:"type: varchar(255)"`
+	Payload     string     `json:"payload" gorm:"type: text"`
+	Status      string     `json:"status" gorm:"type: varchar(255);index"`
+	Attempts    int        `json:"attempts"`
+	Error       string     `json:"error" gorm:"type: text"`
+	AvailableAt time.Time  `json:"available_at" gorm:"index"`
+	ProcessedAt *time.Time `json:"processed_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// PricingRule sets the nightly price of a house, or the minimum stay of the
+// check-ins, on the nights it covers: the nights from StartDate to EndDate
+// included, on the Weekdays, a comma separated list such as "fri,sat". Each
+// of them is optional. Price and MinNights are ignored when 0.
+type PricingRule struct {
+	ID        int       `json:"id" gorm:"primary_key:auto_increment"`
+	HouseId   int       `json:"house_id" gorm:"index"`
+	Name      string    `json:"name" gorm:"type: varchar(255)"`
+	StartDate string    `json:"start_date" gorm:"type: varchar(10)"`
+	EndDate   string    `json:"end_date" gorm:"type: varchar(10)"`
+	Weekdays  string    `json:"weekdays" gorm:"type: varchar(32)"`
+	Price     int       `json:"price"`
+	MinNights int       `json:"min_nights"`
+	CreatedAt time.Time `json:"-"`
+	UpdatedAt time.Time `json:"-"`
+}
+
+// StayDiscount takes Percent off the stays of at least MinNights nights,
+// such as weekly and monthly discounts.
+type StayDiscount struct {
+	ID        int       `json:"id" gorm:"primary_key:auto_increment"`
+	HouseId   int       `json:"house_id" gorm:"index"`
+	MinNights int       `json:"min_nights"`
+	Percent   int       `json:"percent"`
+	CreatedAt time.Time `json:"-"`
+	UpdatedAt time.Time `json:"-"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Kinds of the amount of a promo code.
+const (
+	PromoPercent = "percent"
+	PromoFixed   = "fixed"
+)
+
+// PromoCode takes Amount percent, up to MaxDiscount when it isn't 0, or
+// Amount rupiah off the bookings made from ValidFrom to ValidUntil included.
+// A limit, the minimum spend or a restriction is ignored when 0 or empty.
+// Uses counts the bookings that redeemed the code.
+type PromoCode struct {
+	ID             int            `json:"id" gorm:"primary_key:auto_increment"`
+	Code           string         `json:"code" gorm:"type: varchar(64);uniqueIndex"`
+	Description    string         `json:"description" gorm:"type: varchar(255)"`
+	Kind           string         `json:"kind" gorm:"type: varchar(16)"`
+	Amount         int            `json:"amount"`
+	MaxDiscount    int            `json:"max_discount"`
+	MinSpend       int            `json:"min_spend"`
+	ValidFrom      string         `json:"valid_from" gorm:"type: varchar(10)"`
+	ValidUntil     string         `json:"valid_until" gorm:"type: varchar(10)"`
+	MaxUses        int            `json:"max_uses"`
+	MaxUsesPerUser int            `json:"max_uses_per_user"`
+	Uses           int            `json:"uses"`
+	HouseId        int            `json:"house_id"`
+	CityName       string         `json:"cityname" gorm:"type: varchar(255)"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"-"`
+	DeletedAt      gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// PromoUsage counts the bookings of a user that redeemed a promo code.
+type PromoUsage struct {
+	PromoCodeId int `gorm:"primaryKey"`
+	UserId      int `gorm:"primaryKey"`
+	Uses        int
+}
+
+// PromoRedemption is the use of a promo code by a booking.
+type PromoRedemption struct {
+	ID            int       `json:"id" gorm:"primary_key:auto_increment"`
+	PromoCodeId   int       `json:"promo_code_id" gorm:"index"`
+	UserId        int       `json:"user_id"`
+	TransactionId int       `json:"transaction_id" gorm:"uniqueIndex"`
+	Discount      int       `json:"discount"`
+	CreatedAt     time.Time `json:"created_at"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Review is the feedback of a tenant on a stay. Rating is the mean of the
+// four category ratings, hidden reviews are left out of the house rating.
+type Review struct {
+	ID            int        `json:"id" gorm:"primary_key:auto_increment"`
+	TransactionId int        `json:"transaction_id" gorm:"uniqueIndex"`
+	HouseId       int        `json:"house_id" gorm:"index"`
+	UserId        int        `json:"user_id"`
+	User          User       `json:"user"`
+	Cleanliness   int        `json:"cleanliness"`
+	Accuracy      int        `json:"accuracy"`
+	Location      int        `json:"location"`
+	Value         int        `json:"value"`
+	Rating        float64    `json:"rating"`
+	Comment       string     `json:"comment" gorm:"type: text"`
+	Reply         string     `json:"reply" gorm:"type: text"`
+	RepliedAt     *time.Time `json:"replied_at"`
+	Hidden        bool       `json:"hidden" gorm:"index"`
+	HiddenReason  string     `json:"hidden_reason" gorm:"type: varchar(255)"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
@@ -1,18 +1,40 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Transaction struct {
-	ID            int       `json:"id" gorm:"primary_key:auto_increment"`
-	CheckIn       string    `json:"check_in"`
-	CheckOut      string    `json:"check_out"`
-	HouseId       int       `json:"house_id"`
-	House         House     `json:"house"`
-	UserId        int       `json:"user_id"`
-	User          User      `json:"user" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
-	Total         int       `json:"total"`
-	StatusPayment string    `json:"status_payment"`
-	Attachment    string    `json:"attachment" `
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"upadated_at"`
+	ID            int               `json:"id" gorm:"primary_key:auto_increment"`
+	CheckIn       string            `json:"check_in"`
+	CheckOut      string            `json:"check_out"`
+	HouseId       int               `json:"house_id"`
+	House         House             `json:"house"`
+	UserId        int               `json:"user_id"`
+	User          User              `json:"user" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Total         int               `json:"total"`
+	PromoCode     string            `json:"promo_code,omitempty" gorm:"type: varchar(64)"`
+	PromoDiscount int               `json:"promo_discount"`
+	OwnerPayout   int               `json:"owner_payout"`
+	Items         []TransactionItem `json:"items,omitempty"`
+	StatusPayment string            `json:"status_payment"`
+	Attachment    string            `json:"attachment" `
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"upadated_at"`
+	DeletedAt     gorm.DeletedAt    `json:"-" gorm:"index"`
+}
+
+// TransactionItem is a line of the bill of a booking: rent, discounts,
+// fees, tax or deposit. Discounts are negative, the amounts add up to the
+// total of the transaction.
+type TransactionItem struct {
+	ID            int    `json:"-" gorm:"primary_key:auto_increment"`
+	TransactionId int    `json:"-" gorm:"index"`
+	Kind          string `json:"kind" gorm:"type: varchar(32)"`
+	Description   string `json:"description" gorm:"type: varchar(255)"`
+	Quantity      int    `json:"quantity"`
+	UnitPrice     int    `json:"unit_price"`
+	Amount        int    `json:"amount"`
 }
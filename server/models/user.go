@@ -1,19 +1,24 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type User struct {
-	ID         int       `json:"id"`
-	Fullname   string    `json:"fullname" gorm:"type: varchar(255)"`
-	Email      string    `json:"email" gorm:"type: varchar(255)"`
-	Password   string    `json:"-" gorm:"type: varchar(255)"`
-	Username   string    `json:"username" gorm:"type: varchar(255)"`
-	ListAsRole string    `json:"listAsRole" gorm:"type: varchar(225)"`
-	Address    string    `json:"addres" gorm:"type: varchar(225)"`
-	Gender     string    `json:"gender" gorm:"type: varchar(225)"`
-	Phone      string    `json:"phone" gorm:"type: varchar(225)"`
-	CreatedAt  time.Time `json:"-"`
-	UpdatedAt  time.Time `json:"-"`
+	ID         int            `json:"id"`
+	Fullname   string         `json:"fullname" gorm:"type: varchar(255)"`
+	Email      string         `json:"email" gorm:"type: varchar(255)"`
+	Password   string         `json:"-" gorm:"type: varchar(255)"`
+	Username   string         `json:"username" gorm:"type: varchar(255)"`
+	ListAsRole string         `json:"listAsRole" gorm:"type: varchar(225)"`
+	Address    string         `json:"addres" gorm:"type: varchar(225)"`
+	Gender     string         `json:"gender" gorm:"type: varchar(225)"`
+	Phone      string         `json:"phone" gorm:"type: varchar(225)"`
+	CreatedAt  time.Time      `json:"-"`
+	UpdatedAt  time.Time      `json:"-"`
+	DeletedAt  gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
 type UsersProfileResponse struct {
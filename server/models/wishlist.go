@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Wishlist is a named list of houses a user keeps track of.
+type Wishlist struct {
+	ID        int       `json:"id" gorm:"primary_key:auto_increment"`
+	UserId    int       `json:"user_id" gorm:"index"`
+	Name      string    `json:"name" gorm:"type: varchar(255)"`
+	Houses    []House   `json:"houses" gorm:"many2many:wishlist_houses"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WishlistHouse is the join row of a house in a wishlist.
+type WishlistHouse struct {
+	WishlistId int `gorm:"primaryKey"`
+	HouseId    int `gorm:"primaryKey;index"`
+	CreatedAt  time.Time
+}
+
+func (WishlistHouse) TableName() string {
+	return "wishlist_houses"
+}
@@ -0,0 +1,53 @@
+package openapi
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// Check compares the routes registered under BasePath with the operations
+// of the document and describes every difference, so routes can't be added
+// or removed without updating the spec.
+func Check(router *mux.Router, doc *Document) ([]string, error) {
+	routed := map[string]bool{}
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil || !strings.HasPrefix(path, BasePath+"/") {
+			return nil
+		}
+		// subrouters and prefixes match any method
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		for _, method := range methods {
+			routed[method+" "+strings.TrimPrefix(path, BasePath)] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	documented := map[string]bool{}
+	for _, operation := range doc.Operations() {
+		documented[operation] = true
+	}
+
+	var drift []string
+	for operation := range routed {
+		if !documented[operation] {
+			drift = append(drift, "missing from the spec: "+operation)
+		}
+	}
+	for operation := range documented {
+		if !routed[operation] {
+			drift = append(drift, "not routed: "+operation)
+		}
+	}
+	sort.Strings(drift)
+
+	return drift, nil
+}
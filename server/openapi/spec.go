@@ -0,0 +1,514 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	amenitydto "housy/dto/amenity"
+	authdto "housy/dto/auth"
+	exportdto "housy/dto/export"
+	housesdto "housy/dto/house"
+	pricingdto "housy/dto/pricing"
+	promodto "housy/dto/promo"
+	reportdto "housy/dto/report"
+	dto "housy/dto/result"
+	reviewdto "housy/dto/review"
+	transactiondto "housy/dto/transaction"
+	usersdto "housy/dto/users"
+	wishlistdto "housy/dto/wishlist"
+	"housy/models"
+)
+
+// BasePath is where the API is mounted, the spec paths are relative to it.
+const BasePath = "/api/v1"
+
+const bearerAuth = "bearerAuth"
+
+// errorResponses names the shared error responses by status code.
+var errorResponses = map[int]string{
+	http.StatusBadRequest:            "BadRequest",
+	http.StatusUnauthorized:          "Unauthorized",
+	http.StatusForbidden:             "Forbidden",
+	http.StatusNotFound:              "NotFound",
+	http.StatusConflict:              "Conflict",
+	http.StatusRequestEntityTooLarge: "PayloadTooLarge",
+	http.StatusUnprocessableEntity:   "ValidationFailed",
+	http.StatusInternalServerError:   "InternalError",
+	http.StatusBadGateway:            "BadGateway",
+}
+
+// operation describes one endpoint, Spec expands it into an OpenAPI
+// operation with the success envelope and the shared error responses. data
+// is the payload of the success envelope, content replaces the envelope for
+// files, and also adds other success statuses. optionalAuth marks public
+// endpoints whose responses depend on the caller when a token is sent.
+type operation struct {
+	method       string
+	path         string
+	id           string
+	tag          string
+	summary      string
+	auth         bool
+	optionalAuth bool
+	params       []Parameter
+	body         *RequestBody
+	data         *Schema
+	content      map[string]MediaType
+	also         map[int]*Schema
+	errors       []int
+}
+
+// Spec returns the OpenAPI document of every /api/v1 endpoint.
+func Spec() *Document {
+	s := schemas{}
+
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title:   "Housy API",
+			Version: "1.0.0",
+			Description: "House rentals: listings, bookings, payments and reports.\n\n" +
+				"Successful responses are wrapped as `{\"code\": <status>, \"data\": ...}`, errors as `ErrorResult`. " +
+				"Endpoints marked with a lock need an `Authorization: Bearer <token>` header, the token comes from `POST /sign-in`.",
+		},
+		Servers: []Server{{URL: BasePath}},
+		Tags: []Tag{
+			{Name: "auth", Description: "Sign up, sign in and token checks."},
+			{Name: "users"},
+			{Name: "houses"},
+			{Name: "amenities", Description: "The catalog of amenities houses pick from."},
+			{Name: "pricing", Description: "Seasonal and weekday prices, minimum stays, stay discounts and quotes."},
+			{Name: "promos", Description: "Promo codes taken off bookings, managed by admins."},
+			{Name: "transactions", Description: "Bookings, payments and invoices."},
+			{Name: "reviews", Description: "Ratings of stays, owner replies and moderation."},
+			{Name: "wishlists", Description: "Named lists of favorite houses."},
+			{Name: "reports", Description: "Figures for owners and admins."},
+			{Name: "exports", Description: "CSV and XLSX exports for admins."},
+			{Name: "admin", Description: "Restoring and purging soft deleted records."},
+			{Name: "docs"},
+		},
+		Paths: map[string]PathItem{},
+		Components: Components{
+			Schemas:   s,
+			Responses: map[string]Response{},
+			SecuritySchemes: map[string]SecurityScheme{
+				bearerAuth: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+
+	errorSchema := s.of(dto.ErrorResult{})
+	for status, name := range errorResponses {
+		doc.Components.Responses[name] = Response{
+			Description: http.StatusText(status),
+			Content:     map[string]MediaType{"application/json": {Schema: errorSchema}},
+		}
+	}
+
+	for _, op := range operations(s) {
+		item, ok := doc.Paths[op.path]
+		if !ok {
+			item = PathItem{}
+			doc.Paths[op.path] = item
+		}
+		item[strings.ToLower(op.method)] = op.build()
+	}
+
+	return doc
+}
+
+func (op operation) build() *Operation {
+	o := &Operation{
+		Tags:        []string{op.tag},
+		Summary:     op.summary,
+		OperationID: op.id,
+		Parameters:  op.params,
+		RequestBody: op.body,
+		Responses:   map[string]Response{},
+	}
+
+	var path []Parameter
+	for _, name := range pathParams(op.path) {
+		path = append(path, Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "integer"}})
+	}
+	o.Parameters = append(path, o.Parameters...)
+
+	success := Response{Description: http.StatusText(http.StatusOK), Content: op.content}
+	if op.data != nil {
+		success.Content = map[string]MediaType{"application/json": {Schema: envelope(http.StatusOK, op.data)}}
+	}
+	o.Responses[fmt.Sprint(http.StatusOK)] = success
+	for status, data := range op.also {
+		o.Responses[fmt.Sprint(status)] = Response{
+			Description: http.StatusText(status),
+			Content:     map[string]MediaType{"application/json": {Schema: envelope(status, data)}},
+		}
+	}
+
+	errors := append([]int{http.StatusInternalServerError}, op.errors...)
+	switch {
+	case op.auth:
+		o.Security = []map[string][]string{{bearerAuth: {}}}
+		errors = append(errors, http.StatusUnauthorized)
+	case op.optionalAuth:
+		o.Security = []map[string][]string{{bearerAuth: {}}, {}}
+		errors = append(errors, http.StatusUnauthorized)
+	}
+	for _, status := range errors {
+		o.Responses[fmt.Sprint(status)] = Response{Ref: "#/components/responses/" + errorResponses[status]}
+	}
+
+	return o
+}
+
+// Operations lists the method and path, relative to BasePath, of every
+// documented endpoint, sorted.
+func (d *Document) Operations() []string {
+	var operations []string
+	for path, item := range d.Paths {
+		for method := range item {
+			operations = append(operations, strings.ToUpper(method)+" "+path)
+		}
+	}
+	sort.Strings(operations)
+	return operations
+}
+
+func envelope(status int, data *Schema) *Schema {
+	return &Schema{
+		Type:     "object",
+		Required: []string{"code", "data"},
+		Properties: map[string]*Schema{
+			"code": {Type: "integer", Description: fmt.Sprintf("Always %d.", status)},
+			"data": data,
+		},
+	}
+}
+
+func pathParams(path string) []string {
+	var names []string
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			names = append(names, strings.Trim(segment, "{}"))
+		}
+	}
+	return names
+}
+
+func query(name, kind, description string) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Schema: &Schema{Type: kind}}
+}
+
+// required marks a query parameter the operation can't do without.
+func required(parameter Parameter) Parameter {
+	parameter.Required = true
+	return parameter
+}
+
+func queryEnum(name, description string, values ...string) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Schema: &Schema{Type: "string", Enum: values}}
+}
+
+func jsonBody(schema *Schema) *RequestBody {
+	return &RequestBody{Required: true, Content: map[string]MediaType{"application/json": {Schema: schema}}}
+}
+
+func multipartBody(schema *Schema) *RequestBody {
+	return &RequestBody{Required: true, Content: map[string]MediaType{"multipart/form-data": {Schema: schema}}}
+}
+
+func arrayOf(schema *Schema) *Schema {
+	return &Schema{Type: "array", Items: schema}
+}
+
+func file(mediaTypes ...string) map[string]MediaType {
+	content := map[string]MediaType{}
+	for _, mediaType := range mediaTypes {
+		content[mediaType] = MediaType{Schema: &Schema{Type: "string", Format: "binary"}}
+	}
+	return content
+}
+
+// houseForm is the multipart form the house create and update handlers
+// read, Bedroom and Bathroom are capitalized unlike the other fields.
+func houseForm(required bool) *Schema {
+	form := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"name":         {Type: "string"},
+			"cityname":     {Type: "string"},
+			"address":      {Type: "string"},
+			"latitude":     {Type: "number", Description: "Decimal degrees, given together with longitude."},
+			"longitude":    {Type: "number", Description: "Decimal degrees, given together with latitude."},
+			"price":        {Type: "integer"},
+			"cleaning_fee": {Type: "integer", Description: "Charged once per booking, 0 when left out on create."},
+			"deposit":      {Type: "integer", Description: "Refundable, charged once per booking, 0 when left out on create."},
+			"type_rent":    {Type: "string"},
+			"amenities":    {Type: "string", Description: "Codes from GET /amenities, as a JSON array or comma separated. On update an empty array removes every amenity."},
+			"Bedroom":      {Type: "integer"},
+			"Bathroom":     {Type: "integer"},
+			"description":  {Type: "string"},
+			"area":         {Type: "string"},
+			"image":        {Type: "string", Format: "binary"},
+		},
+		Required: []string{"image"},
+	}
+	if required {
+		form.Required = []string{"name", "cityname", "address", "price", "type_rent", "amenities", "Bedroom", "Bathroom", "image"}
+	}
+	return form
+}
+
+var (
+	houseFilters = []Parameter{
+		query("cityname", "string", "Part of the city name."),
+		query("type_rent", "string", "Exact rent type, e.g. day, month or year."),
+		query("min_price", "integer", ""),
+		query("max_price", "integer", ""),
+		query("bedroom", "integer", "Minimum number of bedrooms."),
+		query("bathroom", "integer", "Minimum number of bathrooms."),
+		query("amenities", "string", "Comma separated amenity codes, houses must have all of them."),
+		query("lat", "number", "Latitude of the point the distance_km of the results is measured from, given with lng."),
+		query("lng", "number", "Longitude of the point, given with lat."),
+		query("radius_km", "number", "Only houses within this distance of lat and lng. The circle must not reach past longitude 180 or -180."),
+		query("bbox", "string", "Only houses inside the map area west,south,east,north, in decimal degrees."),
+		queryEnum("sort", "Nearest to lat and lng first, houses without a location last. Defaults to the id order.", "distance"),
+	}
+	houseSearch = []Parameter{
+		query("q", "string", "Words to search in the name, city, address and description, tolerating typos. The houses are ranked by relevance, with a score and highlighted snippets, unless sort is given. Only the 1000 best matches are filtered."),
+	}
+	transactionFilters = []Parameter{
+		query("status_payment", "string", "pending, success or failed."),
+		query("house_id", "integer", ""),
+		query("user_id", "integer", ""),
+		query("from", "string", "Earliest check in date, YYYY-MM-DD."),
+		query("to", "string", "Latest check in date, YYYY-MM-DD."),
+	}
+	pagination = []Parameter{
+		query("page", "integer", "Page number from 1, the X-Total-Count response header then holds the size of the list. Without page or per_page the whole list is returned."),
+		query("per_page", "integer", "Items per page, 20 by default and at most 100."),
+	}
+	reportFilters = []Parameter{
+		query("house_id", "integer", ""),
+		query("from", "string", "First check in date, YYYY-MM-DD. Defaults to 12 months before to."),
+		query("to", "string", "Last check in date, YYYY-MM-DD. Defaults to today."),
+	}
+	exportOptions = []Parameter{
+		queryEnum("format", "Defaults to csv.", "csv", "xlsx"),
+		query("columns", "string", "Comma separated columns, all of them by default."),
+		query("tz", "string", "IANA time zone of the timestamps, defaults to UTC."),
+		queryEnum("async", "Always generate the export in the background. Large exports always are.", "true", "false"),
+	}
+)
+
+func params(groups ...[]Parameter) []Parameter {
+	var all []Parameter
+	for _, group := range groups {
+		all = append(all, group...)
+	}
+	return all
+}
+
+func operations(s schemas) []operation {
+	const (
+		get   = http.MethodGet
+		post  = http.MethodPost
+		put   = http.MethodPut
+		patch = http.MethodPatch
+		del   = http.MethodDelete
+	)
+
+	user := s.of(usersdto.UserResponse{})
+	house := s.of(models.House{})
+	transaction := s.of(models.Transaction{})
+	purged := &Schema{Type: "object", Properties: map[string]*Schema{"id": {Type: "integer"}}}
+	exportFile := file("text/csv", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	exportJob := s.of(exportdto.ExportJobResponse{})
+	review := s.of(reviewdto.ReviewResponse{})
+	wishlist := s.of(wishlistdto.WishlistResponse{})
+	amenity := s.of(amenitydto.AmenityResponse{})
+	pricing := s.of(pricingdto.PricingResponse{})
+	promo := s.of(promodto.PromoCodeResponse{})
+
+	return []operation{
+		{method: post, path: "/sign-up", id: "signUp", tag: "auth", summary: "Create an account",
+			body: jsonBody(s.of(authdto.SignUpRequest{})), data: s.of(authdto.SignUpResponse{}),
+			errors: []int{400, 422}},
+		{method: post, path: "/sign-in", id: "signIn", tag: "auth", summary: "Get a token",
+			body: jsonBody(s.of(authdto.SignInRequest{})), data: s.of(authdto.SignInResponse{}),
+			errors: []int{400, 401, 422}},
+		{method: get, path: "/check-auth", id: "checkAuth", tag: "auth", summary: "Describe the signed in user", auth: true,
+			data: s.of(authdto.CheckAuthResponse{}), errors: []int{404}},
+
+		{method: get, path: "/users", id: "findUsers", tag: "users", summary: "List users",
+			data: arrayOf(s.of(models.User{}))},
+		{method: get, path: "/user/{id}", id: "getUser", tag: "users", summary: "Get a user",
+			data: user, errors: []int{404}},
+		{method: patch, path: "/user/{id}", id: "updateUser", tag: "users", summary: "Update the signed in user, admins any user, empty fields are left as is", auth: true,
+			body: jsonBody(s.of(usersdto.RequestUser{})), data: user, errors: []int{400, 403, 404}},
+		{method: del, path: "/user/{id}", id: "deleteUser", tag: "users", summary: "Soft delete the signed in user, admins any user", auth: true,
+			data: user, errors: []int{403, 404}},
+
+		{method: get, path: "/houses", id: "findHouses", tag: "houses", summary: "List houses", optionalAuth: true,
+			params: params(houseSearch, houseFilters, pagination), data: arrayOf(house), errors: []int{400}},
+		{method: get, path: "/house/{id}", id: "getHouse", tag: "houses", summary: "Get a house", optionalAuth: true,
+			data: s.of(housesdto.ResponseHouse{}), errors: []int{404}},
+		{method: post, path: "/house", id: "createHouse", tag: "houses", summary: "List a house", auth: true,
+			body: multipartBody(houseForm(true)), data: house, errors: []int{400, 413, 422}},
+		{method: patch, path: "/house/{id}", id: "updateHouse", tag: "houses", summary: "Update a house of the signed in owner, empty fields are left as is", auth: true,
+			body: multipartBody(houseForm(false)), data: house, errors: []int{400, 403, 404, 413, 422}},
+		{method: del, path: "/house/{id}", id: "deleteHouse", tag: "houses", summary: "Soft delete a house of the signed in owner without upcoming paid bookings", auth: true,
+			data: house, errors: []int{403, 404, 409}},
+		{method: post, path: "/houses/import", id: "importHouses", tag: "houses", summary: "Create houses from a CSV or JSON file, owners and admins only", auth: true,
+			params: []Parameter{
+				queryEnum("mode", "dry-run only validates the rows, the default.", "dry-run", "commit"),
+				query("batch_size", "integer", "Rows created per database transaction."),
+			},
+			body: multipartBody(&Schema{
+				Type:     "object",
+				Required: []string{"file"},
+				Properties: map[string]*Schema{
+					"file":   {Type: "string", Format: "binary", Description: "The .csv or .json file of houses."},
+					"images": {Type: "string", Format: "binary", Description: "A zip archive of the images the rows refer to by name. Rows may also give a public http(s) URL of an image."},
+				},
+			}),
+			data: s.of(housesdto.ImportResponse{}), also: map[int]*Schema{201: s.of(housesdto.ImportResponse{})}, errors: []int{400, 403}},
+
+		{method: get, path: "/amenities", id: "findAmenities", tag: "amenities", summary: "List the amenities catalog",
+			data: arrayOf(amenity)},
+		{method: post, path: "/admin/amenity", id: "createAmenity", tag: "amenities", summary: "Add an amenity to the catalog", auth: true,
+			body: jsonBody(s.of(amenitydto.AmenityRequest{})), data: amenity, errors: []int{400, 403, 409, 422}},
+		{method: patch, path: "/admin/amenity/{id}", id: "updateAmenity", tag: "amenities", summary: "Update an amenity, empty fields are left as is", auth: true,
+			body: jsonBody(s.of(amenitydto.UpdateAmenityRequest{})), data: amenity, errors: []int{400, 403, 404, 422}},
+		{method: del, path: "/admin/amenity/{id}", id: "deleteAmenity", tag: "amenities", summary: "Remove an amenity no house has from the catalog", auth: true,
+			data: purged, errors: []int{403, 404, 409}},
+
+		{method: get, path: "/house/{id}/pricing", id: "getPricing", tag: "pricing", summary: "Get the pricing rules and stay discounts of a house",
+			data: pricing, errors: []int{404}},
+		{method: put, path: "/house/{id}/pricing", id: "updatePricing", tag: "pricing", summary: "Replace the pricing rules and stay discounts of a house", auth: true,
+			body: jsonBody(s.of(pricingdto.PricingRequest{})), data: pricing, errors: []int{400, 403, 404, 422}},
+		{method: get, path: "/house/{id}/quote", id: "quoteStay", tag: "pricing", summary: "Price a stay, 422 when it is shorter than the minimum stay or the promo code doesn't apply", optionalAuth: true,
+			params: []Parameter{
+				required(query("check_in", "string", "First night, YYYY-MM-DD.")),
+				required(query("check_out", "string", "Day of departure, YYYY-MM-DD.")),
+				query("promo_code", "string", "Promo code to take off the total, its limit per user is checked for signed in users."),
+			},
+			data: s.of(pricingdto.QuoteResponse{}), errors: []int{400, 404, 422}},
+		{method: get, path: "/house/{id}/calendar", id: "getPriceCalendar", tag: "pricing", summary: "List the nightly prices of a house",
+			params: []Parameter{
+				query("from", "string", "First night, YYYY-MM-DD, today by default."),
+				query("to", "string", "Last night included, YYYY-MM-DD, 30 days after from by default, at most a year after it."),
+			},
+			data: arrayOf(s.of(pricingdto.NightResponse{})), errors: []int{400, 404}},
+
+		{method: get, path: "/admin/promos", id: "findPromoCodes", tag: "promos", summary: "List the promo codes with their uses", auth: true,
+			data: arrayOf(promo), errors: []int{403}},
+		{method: post, path: "/admin/promo", id: "createPromoCode", tag: "promos", summary: "Create a promo code", auth: true,
+			body: jsonBody(s.of(promodto.PromoCodeRequest{})), data: promo, errors: []int{400, 403, 409, 422}},
+		{method: patch, path: "/admin/promo/{id}", id: "updatePromoCode", tag: "promos", summary: "Update the description, end date or limits of a promo code, empty fields are left as is", auth: true,
+			body: jsonBody(s.of(promodto.UpdatePromoCodeRequest{})), data: promo, errors: []int{400, 403, 404, 422}},
+		{method: del, path: "/admin/promo/{id}", id: "deletePromoCode", tag: "promos", summary: "End a promo code", auth: true,
+			data: purged, errors: []int{403, 404}},
+
+		{method: get, path: "/transactions", id: "findTransactions", tag: "transactions", summary: "List the bookings of the signed in tenant, of the houses of the signed in owner, or all of them for admins", auth: true,
+			params: params(transactionFilters, pagination), data: arrayOf(transaction), errors: []int{400}},
+		{method: get, path: "/transaction/{id}", id: "getTransaction", tag: "transactions", summary: "Get a transaction of the tenant or the owner of the house", auth: true,
+			data: s.of(transactiondto.ResponseTransaction{}), errors: []int{403, 404}},
+		{method: post, path: "/transaction", id: "createTransaction", tag: "transactions", summary: "Book a house for the signed in user and start its payment", auth: true,
+			body: jsonBody(s.of(transactiondto.RequestTransaction{})),
+			data: &Schema{
+				Type:        "object",
+				Description: "The Midtrans Snap payment.",
+				Properties: map[string]*Schema{
+					"token":        {Type: "string"},
+					"redirect_url": {Type: "string"},
+				},
+			},
+			errors: []int{400, 404, 409, 422, 502}},
+		{method: del, path: "/transaction/{id}", id: "deleteTransaction", tag: "transactions", summary: "Soft delete a transaction of the signed in tenant", auth: true,
+			data: transaction, errors: []int{403, 404}},
+		{method: get, path: "/transaction/{id}/invoice.pdf", id: "getInvoice", tag: "transactions", summary: "Download the invoice of a paid transaction", auth: true,
+			content: file("application/pdf"), errors: []int{403, 404}},
+		{method: post, path: "/notification", id: "paymentNotification", tag: "transactions", summary: "Midtrans payment notification webhook, paid bookings ignore later notifications",
+			body: jsonBody(&Schema{
+				Type:     "object",
+				Required: []string{"transaction_status", "order_id", "status_code", "gross_amount", "signature_key"},
+				Properties: map[string]*Schema{
+					"transaction_status": {Type: "string", Enum: []string{"capture", "settlement", "pending", "deny", "cancel", "expire"}},
+					"fraud_status":       {Type: "string", Enum: []string{"accept", "challenge", "deny"}},
+					"order_id":           {Type: "string"},
+					"payment_type":       {Type: "string"},
+					"status_code":        {Type: "string"},
+					"gross_amount":       {Type: "string"},
+					"signature_key":      {Type: "string", Description: "SHA-512 of order_id, status_code, gross_amount and the server key."},
+				},
+			}),
+			errors: []int{400, 403, 404}},
+
+		{method: get, path: "/house/{id}/reviews", id: "findHouseReviews", tag: "reviews", summary: "List the visible reviews of a house, newest first",
+			params: pagination, data: arrayOf(review), errors: []int{400, 404}},
+		{method: post, path: "/transaction/{id}/review", id: "createReview", tag: "reviews", summary: "Review a paid stay after check-out, once", auth: true,
+			body: jsonBody(s.of(reviewdto.ReviewRequest{})), data: review, errors: []int{400, 403, 404, 409, 422}},
+		{method: post, path: "/review/{id}/reply", id: "replyReview", tag: "reviews", summary: "Reply to a review of your house", auth: true,
+			body: jsonBody(s.of(reviewdto.ReplyRequest{})), data: review, errors: []int{400, 403, 404, 422}},
+		{method: get, path: "/admin/reviews/hidden", id: "findHiddenReviews", tag: "reviews", summary: "List the hidden reviews", auth: true,
+			params: pagination, data: arrayOf(review), errors: []int{400, 403}},
+		{method: post, path: "/admin/review/{id}/hide", id: "hideReview", tag: "reviews", summary: "Hide an abusive review", auth: true,
+			body: jsonBody(s.of(reviewdto.HideRequest{})), data: review, errors: []int{400, 403, 404, 422}},
+		{method: post, path: "/admin/review/{id}/unhide", id: "unhideReview", tag: "reviews", summary: "Show a hidden review again", auth: true,
+			data: review, errors: []int{403, 404}},
+
+		{method: get, path: "/me/wishlists", id: "findMyWishlists", tag: "wishlists", summary: "List your wishlists and their houses", auth: true,
+			data: arrayOf(wishlist)},
+		{method: post, path: "/wishlist", id: "createWishlist", tag: "wishlists", summary: "Create a wishlist", auth: true,
+			body: jsonBody(s.of(wishlistdto.WishlistRequest{})), data: wishlist, errors: []int{400, 422}},
+		{method: patch, path: "/wishlist/{id}", id: "renameWishlist", tag: "wishlists", summary: "Rename a wishlist", auth: true,
+			body: jsonBody(s.of(wishlistdto.WishlistRequest{})), data: wishlist, errors: []int{400, 403, 404, 422}},
+		{method: del, path: "/wishlist/{id}", id: "deleteWishlist", tag: "wishlists", summary: "Delete a wishlist", auth: true,
+			data: purged, errors: []int{403, 404}},
+		{method: put, path: "/wishlist/{id}/house/{houseId}", id: "addWishlistHouse", tag: "wishlists", summary: "Add a house to a wishlist", auth: true,
+			data: wishlist, errors: []int{403, 404}},
+		{method: del, path: "/wishlist/{id}/house/{houseId}", id: "removeWishlistHouse", tag: "wishlists", summary: "Remove a house from a wishlist", auth: true,
+			data: wishlist, errors: []int{403, 404}},
+
+		{method: get, path: "/reports/revenue", id: "revenueReport", tag: "reports", summary: "What the owners earned from paid bookings per month, after the fees and taxes", auth: true,
+			params: reportFilters, data: arrayOf(s.of(reportdto.RevenueResponse{})), errors: []int{400, 403}},
+		{method: get, path: "/reports/occupancy", id: "occupancyReport", tag: "reports", summary: "Booked nights per house", auth: true,
+			params: reportFilters, data: arrayOf(s.of(reportdto.OccupancyResponse{})), errors: []int{400, 403}},
+		{method: get, path: "/reports/length-of-stay", id: "lengthOfStayReport", tag: "reports", summary: "Average nights per booking", auth: true,
+			params: reportFilters, data: s.of(reportdto.LengthOfStaySummary{}), errors: []int{400, 403}},
+		{method: get, path: "/reports/conversion", id: "conversionReport", tag: "reports", summary: "Bookings per payment status", auth: true,
+			params: reportFilters, data: s.of(reportdto.ConversionResponse{}), errors: []int{400, 403}},
+		{method: get, path: "/reports/cancellations", id: "cancellationsReport", tag: "reports", summary: "Failed bookings per month", auth: true,
+			params: reportFilters, data: arrayOf(s.of(reportdto.CancellationResponse{})), errors: []int{400, 403}},
+
+		{method: get, path: "/houses/export", id: "exportHouses", tag: "exports", summary: "Export houses", auth: true,
+			params: params(houseFilters, exportOptions), content: exportFile, also: map[int]*Schema{202: exportJob}, errors: []int{400, 403}},
+		{method: get, path: "/transactions/export", id: "exportTransactions", tag: "exports", summary: "Export transactions", auth: true,
+			params: params(transactionFilters, exportOptions), content: exportFile, also: map[int]*Schema{202: exportJob}, errors: []int{400, 403}},
+		{method: get, path: "/exports/{id}", id: "getExportJob", tag: "exports", summary: "Get a background export", auth: true,
+			data: exportJob, errors: []int{403, 404}},
+		{method: get, path: "/exports/{id}/download", id: "downloadExport", tag: "exports", summary: "Download a finished background export", auth: true,
+			content: exportFile, errors: []int{403, 404, 409}},
+
+		{method: post, path: "/admin/house/{id}/restore", id: "restoreHouse", tag: "admin", summary: "Restore a deleted house", auth: true,
+			data: s.of(housesdto.ResponseHouse{}), errors: []int{403, 404}},
+		{method: del, path: "/admin/house/{id}/purge", id: "purgeHouse", tag: "admin", summary: "Permanently delete a house never booked", auth: true,
+			data: purged, errors: []int{403, 409}},
+		{method: post, path: "/admin/user/{id}/restore", id: "restoreUser", tag: "admin", summary: "Restore a deleted user", auth: true,
+			data: user, errors: []int{403, 404}},
+		{method: del, path: "/admin/user/{id}/purge", id: "purgeUser", tag: "admin", summary: "Permanently delete a user without bookings", auth: true,
+			data: purged, errors: []int{403, 409}},
+		{method: post, path: "/admin/transaction/{id}/restore", id: "restoreTransaction", tag: "admin", summary: "Restore a deleted transaction", auth: true,
+			data: s.of(transactiondto.ResponseTransaction{}), errors: []int{403, 404}},
+		{method: del, path: "/admin/transaction/{id}/purge", id: "purgeTransaction", tag: "admin", summary: "Permanently delete a transaction without an invoice, and its review", auth: true,
+			data: purged, errors: []int{403, 409}},
+
+		{method: get, path: "/openapi.json", id: "getOpenAPI", tag: "docs", summary: "This document",
+			content: map[string]MediaType{"application/json": {Schema: &Schema{Type: "object"}}}},
+		{method: get, path: "/docs", id: "getDocs", tag: "docs", summary: "Interactive documentation",
+			content: map[string]MediaType{"text/html": {Schema: &Schema{Type: "string"}}}},
+	}
+}
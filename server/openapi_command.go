@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"housy/app"
+	"housy/openapi"
+	"housy/routes"
+	"io"
+)
+
+// openapiCommand runs the openapi subcommand:
+//
+//	openapi         prints the spec
+//	openapi check   fails when the routes and the spec disagree
+//
+// go test runs the same check without a database, see routes/openapi_test.go.
+func openapiCommand(a *app.App, args []string, out io.Writer) error {
+	doc := openapi.Spec()
+
+	if len(args) == 0 {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(doc)
+	}
+
+	if args[0] != "check" {
+		return fmt.Errorf("unknown openapi command %q, expected check", args[0])
+	}
+
+	// the routes are only walked, their background workers mustn't run
+	if err := a.StopWorkers(context.Background()); err != nil {
+		return err
+	}
+
+	drift, err := openapi.Check(routes.Router(a), doc)
+	if err != nil {
+		return err
+	}
+	for _, line := range drift {
+		fmt.Fprintln(out, line)
+	}
+	if len(drift) > 0 {
+		return fmt.Errorf("the routes and the openapi spec differ in %d operations", len(drift))
+	}
+
+	fmt.Fprintf(out, "%d operations documented\n", len(doc.Operations()))
+	return nil
+}
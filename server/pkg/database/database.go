@@ -0,0 +1,146 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+const (
+	MySQL    = "mysql"
+	Postgres = "postgres"
+	SQLite   = "sqlite"
+)
+
+// Config describes the database connection. For SQLite, Name is the path of
+// the database file, or ":memory:" for a throwaway in-memory database.
+type Config struct {
+	Driver   string
+	Host     string
+	Port     string
+	User     string
+	Password string
+	Name     string
+	Charset  string
+	SSLMode  string
+
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+func (c Config) withDefaults() Config {
+	if c.Driver == "" {
+		c.Driver = MySQL
+	}
+	if c.Charset == "" {
+		c.Charset = "utf8mb4"
+	}
+	if c.SSLMode == "" {
+		c.SSLMode = "disable"
+	}
+	if c.Port == "" {
+		switch c.Driver {
+		case MySQL:
+			c.Port = "3306"
+		case Postgres:
+			c.Port = "5432"
+		}
+	}
+	return c
+}
+
+// DSN builds the data source name of the configured driver.
+func (c Config) DSN() (string, error) {
+	c = c.withDefaults()
+
+	switch c.Driver {
+	case MySQL:
+		dsn := mysqldriver.NewConfig()
+		dsn.User = c.User
+		dsn.Passwd = c.Password
+		dsn.Net = "tcp"
+		dsn.Addr = net.JoinHostPort(c.Host, c.Port)
+		dsn.DBName = c.Name
+		dsn.ParseTime = true
+		dsn.Loc = time.Local
+		dsn.Params = map[string]string{"charset": c.Charset}
+		return dsn.FormatDSN(), nil
+	case Postgres:
+		dsn := url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(c.User, c.Password),
+			Host:     net.JoinHostPort(c.Host, c.Port),
+			Path:     "/" + c.Name,
+			RawQuery: url.Values{"sslmode": {c.SSLMode}}.Encode(),
+		}
+		return dsn.String(), nil
+	case SQLite:
+		if c.Name == "" {
+			return "", errors.New("DB_NAME must be the sqlite file path or :memory:")
+		}
+		return c.Name + "?_pragma=foreign_keys(1)", nil
+	}
+
+	return "", fmt.Errorf("unsupported database driver %q, use mysql, postgres or sqlite", c.Driver)
+}
+
+// Open connects to the configured database and applies the pool settings.
+func Open(c Config) (*gorm.DB, error) {
+	c = c.withDefaults()
+
+	dsn, err := c.DSN()
+	if err != nil {
+		return nil, err
+	}
+
+	var dialector gorm.Dialector
+	switch c.Driver {
+	case MySQL:
+		dialector = mysql.Open(dsn)
+	case Postgres:
+		dialector = postgres.Open(dsn)
+	case SQLite:
+		dialector = sqlite.Open(dsn)
+		if c.Name == ":memory:" {
+			// every connection to :memory: is a separate database
+			c.MaxOpenConns = 1
+		}
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Use(tracing{system: c.Driver}); err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	if c.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(c.MaxOpenConns)
+	}
+	if c.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(c.MaxIdleConns)
+	}
+	if c.ConnMaxLifetime > 0 {
+		sqlDB.SetConnMaxLifetime(c.ConnMaxLifetime)
+	}
+	if c.ConnMaxIdleTime > 0 {
+		sqlDB.SetConnMaxIdleTime(c.ConnMaxIdleTime)
+	}
+
+	return db, nil
+}
@@ -0,0 +1,80 @@
+package database
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDSN(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   string
+	}{
+		{"mysql", Config{Host: "db", User: "housy", Password: "secret", Name: "housy"}, "housy:secret@tcp(db:3306)/housy?"},
+		{"postgres", Config{Driver: Postgres, Host: "db", User: "housy", Password: "secret", Name: "housy"}, "postgres://housy:secret@db:5432/housy?sslmode=disable"},
+		{"sqlite", Config{Driver: SQLite, Name: ":memory:"}, ":memory:?_pragma=foreign_keys(1)"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dsn, err := test.config.DSN()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(dsn, test.want) {
+				t.Errorf("DSN() = %q, want it to start with %q", dsn, test.want)
+			}
+		})
+	}
+}
+
+func TestDSNErrors(t *testing.T) {
+	if _, err := (Config{Driver: SQLite}).DSN(); err == nil {
+		t.Error("a sqlite config without a file path was accepted")
+	}
+	if _, err := (Config{Driver: "oracle"}).DSN(); err == nil {
+		t.Error("an unsupported driver was accepted")
+	}
+}
+
+func TestOpenMemorySQLiteUsesOneConnection(t *testing.T) {
+	db, err := Open(Config{Driver: SQLite, Name: ":memory:", MaxOpenConns: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDB.Close()
+
+	if got := sqlDB.Stats().MaxOpenConnections; got != 1 {
+		t.Fatalf("MaxOpenConnections = %d, want 1", got)
+	}
+
+	// with a pool, the table would only exist on the connection creating it
+	if err := db.Exec("CREATE TABLE things (id integer)").Error; err != nil {
+		t.Fatal(err)
+	}
+	if !db.Migrator().HasTable("things") {
+		t.Fatal("the table created on the in-memory database is gone")
+	}
+}
+
+func TestOpenSQLiteFileKeepsPoolSettings(t *testing.T) {
+	db, err := Open(Config{Driver: SQLite, Name: filepath.Join(t.TempDir(), "housy.db"), MaxOpenConns: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDB.Close()
+
+	if got := sqlDB.Stats().MaxOpenConnections; got != 4 {
+		t.Fatalf("MaxOpenConnections = %d, want 4", got)
+	}
+}
@@ -0,0 +1,75 @@
+package database
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const spanKey = "housy:span"
+
+// tracing is a gorm plugin starting a span for every statement, as a child
+// of the span in the statement context. Only the SQL with placeholders is
+// recorded, never the values.
+type tracing struct {
+	system string
+}
+
+func (tracing) Name() string {
+	return "housy:tracing"
+}
+
+func (p tracing) Initialize(db *gorm.DB) error {
+	tracer := otel.Tracer("housy/pkg/database")
+
+	before := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			ctx, span := tracer.Start(tx.Statement.Context, "db."+operation, trace.WithSpanKind(trace.SpanKindClient))
+			tx.Statement.Context = ctx
+			tx.InstanceSet(spanKey, span)
+		}
+	}
+
+	after := func(tx *gorm.DB) {
+		value, ok := tx.InstanceGet(spanKey)
+		if !ok {
+			return
+		}
+		span := value.(trace.Span)
+		defer span.End()
+
+		span.SetAttributes(
+			attribute.String("db.system", p.system),
+			attribute.String("db.statement", tx.Statement.SQL.String()),
+			attribute.String("db.sql.table", tx.Statement.Table),
+			attribute.Int64("db.rows_affected", tx.Statement.RowsAffected),
+		)
+		if tx.Error != nil && tx.Error != gorm.ErrRecordNotFound {
+			span.RecordError(tx.Error)
+			span.SetStatus(codes.Error, tx.Error.Error())
+		}
+	}
+
+	for _, err := range []error{
+		db.Callback().Create().Before("gorm:create").Register("tracing:before_create", before("create")),
+		db.Callback().Create().After("gorm:create").Register("tracing:after_create", after),
+		db.Callback().Query().Before("gorm:query").Register("tracing:before_query", before("query")),
+		db.Callback().Query().After("gorm:query").Register("tracing:after_query", after),
+		db.Callback().Update().Before("gorm:update").Register("tracing:before_update", before("update")),
+		db.Callback().Update().After("gorm:update").Register("tracing:after_update", after),
+		db.Callback().Delete().Before("gorm:delete").Register("tracing:before_delete", before("delete")),
+		db.Callback().Delete().After("gorm:delete").Register("tracing:after_delete", after),
+		db.Callback().Row().Before("gorm:row").Register("tracing:before_row", before("row")),
+		db.Callback().Row().After("gorm:row").Register("tracing:after_row", after),
+		db.Callback().Raw().Before("gorm:raw").Register("tracing:before_raw", before("raw")),
+		db.Callback().Raw().After("gorm:raw").Register("tracing:after_raw", after),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// Writer writes a table one row at a time. Close must be called to flush
+// the output.
+type Writer interface {
+	Write(row []string) error
+	Close() error
+}
+
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return &csvWriter{csv.NewWriter(w)}, nil
+	case FormatXLSX:
+		return newXLSXWriter(w)
+	}
+	return nil, fmt.Errorf("unsupported export format %q", format)
+}
+
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvWriter) Write(row []string) error {
+	if err := c.w.Write(row); err != nil {
+		return err
+	}
+	// flush every row so the response streams instead of buffering
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type xlsxWriter struct {
+	out    io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+func newXLSXWriter(w io.Writer) (*xlsxWriter, error) {
+	file := excelize.NewFile()
+	stream, err := file.NewStreamWriter("Sheet1")
+	if err != nil {
+		return nil, err
+	}
+	return &xlsxWriter{out: w, file: file, stream: stream}, nil
+}
+
+func (x *xlsxWriter) Write(row []string) error {
+	x.row++
+	cell, err := excelize.CoordinatesToCellName(1, x.row)
+	if err != nil {
+		return err
+	}
+
+	values := make([]interface{}, len(row))
+	for i, value := range row {
+		values[i] = value
+	}
+	return x.stream.SetRow(cell, values)
+}
+
+func (x *xlsxWriter) Close() error {
+	defer x.file.Close()
+	if err := x.stream.Flush(); err != nil {
+		return err
+	}
+	return x.file.Write(x.out)
+}
@@ -0,0 +1,123 @@
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"housy/models"
+	"strconv"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+)
+
+type LineItem struct {
+	Description string
+	Quantity    int
+	UnitPrice   int
+	Amount      int
+}
+
+// LineItems lists what the tenant paid for in a transaction. Bookings made
+// before they were itemized have a single line for their total.
+func LineItems(transaction models.Transaction) []LineItem {
+	if len(transaction.Items) > 0 {
+		items := make([]LineItem, 0, len(transaction.Items))
+		for _, item := range transaction.Items {
+			items = append(items, LineItem{Description: item.Description, Quantity: item.Quantity, UnitPrice: item.UnitPrice, Amount: item.Amount})
+		}
+		return items
+	}
+
+	description := fmt.Sprintf("%s (%s) %s - %s", transaction.House.Name, transaction.House.TypeRent, transaction.CheckIn, transaction.CheckOut)
+
+	return []LineItem{
+		{Description: description, Quantity: 1, UnitPrice: transaction.Total, Amount: transaction.Total},
+	}
+}
+
+// Generate renders the invoice of a transaction as a PDF document.
+func Generate(invoice models.Invoice, transaction models.Transaction) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle("Invoice "+invoice.Number, false)
+	pdf.SetAuthor("Housy", false)
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 20)
+	pdf.CellFormat(0, 12, "Housy", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.CellFormat(0, 8, "INVOICE / RECEIPT", "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	pdf.SetFont("Helvetica", "", 10)
+	details := [][2]string{
+		{"Invoice Number", invoice.Number},
+		{"Issued At", invoice.IssuedAt.Format("02 January 2006")},
+		{"Transaction ID", strconv.Itoa(transaction.ID)},
+		{"Billed To", transaction.User.Fullname + " <" + transaction.User.Email + ">"},
+		{"House", transaction.House.Name},
+		{"Address", transaction.House.Address + ", " + transaction.House.CityName},
+		{"Check In", transaction.CheckIn},
+		{"Check Out", transaction.CheckOut},
+		{"Payment Method", paymentMethod(invoice.PaymentMethod)},
+		{"Status", transaction.StatusPayment},
+	}
+	for _, detail := range details {
+		pdf.CellFormat(40, 6, detail[0], "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 6, ": "+detail[1], "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(6)
+
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.SetFillColor(240, 240, 240)
+	pdf.CellFormat(95, 8, "Description", "1", 0, "L", true, 0, "")
+	pdf.CellFormat(15, 8, "Qty", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(40, 8, "Unit Price", "1", 0, "R", true, 0, "")
+	pdf.CellFormat(40, 8, "Amount", "1", 1, "R", true, 0, "")
+
+	pdf.SetFont("Helvetica", "", 10)
+	total := 0
+	for _, item := range LineItems(transaction) {
+		pdf.CellFormat(95, 8, item.Description, "1", 0, "L", false, 0, "")
+		pdf.CellFormat(15, 8, strconv.Itoa(item.Quantity), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(40, 8, Rupiah(item.UnitPrice), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(40, 8, Rupiah(item.Amount), "1", 1, "R", false, 0, "")
+		total += item.Amount
+	}
+
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(150, 8, "Total", "1", 0, "R", false, 0, "")
+	pdf.CellFormat(40, 8, Rupiah(total), "1", 1, "R", false, 0, "")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Rupiah formats an amount like "Rp 1.500.000".
+func Rupiah(amount int) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := strconv.Itoa(amount)
+	var groups []string
+	for len(digits) > 3 {
+		groups = append([]string{digits[len(digits)-3:]}, groups...)
+		digits = digits[:len(digits)-3]
+	}
+	groups = append([]string{digits}, groups...)
+
+	return sign + "Rp " + strings.Join(groups, ".")
+}
+
+func paymentMethod(method string) string {
+	if method == "" {
+		return "-"
+	}
+	return strings.ReplaceAll(method, "_", " ")
+}
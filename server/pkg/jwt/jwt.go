@@ -2,16 +2,14 @@ package jwtToken
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/golang-jwt/jwt/v4"
 )
 
-var SecretKey = os.Getenv("SECRET_KEY")
-
-func GenerateToken(claims *jwt.MapClaims) (string, error) {
+// GenerateToken signs the claims with the secret key of the config.
+func GenerateToken(claims *jwt.MapClaims, secretKey string) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	webtoken, err := token.SignedString([]byte(SecretKey))
+	webtoken, err := token.SignedString([]byte(secretKey))
 	if err != nil {
 		return "", err
 	}
@@ -19,12 +17,12 @@ func GenerateToken(claims *jwt.MapClaims) (string, error) {
 	return webtoken, nil
 }
 
-func VerifyToken(tokenString string) (*jwt.Token, error) {
+func VerifyToken(tokenString string, secretKey string) (*jwt.Token, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		if _, isValid := token.Method.(*jwt.SigningMethodHMAC); !isValid {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte(SecretKey), nil
+		return []byte(secretKey), nil
 	})
 
 	if err != nil {
@@ -33,8 +31,8 @@ func VerifyToken(tokenString string) (*jwt.Token, error) {
 	return token, nil
 }
 
-func DecodeToken(tokenString string) (jwt.MapClaims, error) {
-	token, err := VerifyToken(tokenString)
+func DecodeToken(tokenString string, secretKey string) (jwt.MapClaims, error) {
+	token, err := VerifyToken(tokenString, secretKey)
 	if err != nil {
 		return nil, err
 	}
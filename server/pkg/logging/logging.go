@@ -0,0 +1,116 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const redacted = "[REDACTED]"
+
+// Config chooses the level (debug, info, warn or error) and the format
+// (json or text) of the logs.
+type Config struct {
+	Level  string
+	Format string
+}
+
+// New returns a logger that adds the request ID of the context to every
+// record and redacts secrets.
+func New(w io.Writer, cfg Config) *slog.Logger {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		level = slog.LevelInfo
+	}
+
+	options := &slog.HandlerOptions{Level: level, ReplaceAttr: redact}
+
+	var handler slog.Handler
+	if cfg.Format == "text" {
+		handler = slog.NewTextHandler(w, options)
+	} else {
+		handler = slog.NewJSONHandler(w, options)
+	}
+
+	return slog.New(contextHandler{handler})
+}
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context carrying the request ID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID of the context, or "" outside a request.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// contextHandler adds the request_id and trace_id attributes of the context.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.HasTraceID() {
+		record.AddAttrs(slog.String("trace_id", span.TraceID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+var sensitiveKeys = []string{"password", "token", "secret", "authorization", "server_key", "client_key", "signature_key"}
+
+// sensitiveValues matches secrets inside free text, such as an error
+// message quoting a request body or a header.
+var sensitiveValues = regexp.MustCompile(`(?i)(bearer\s+|"?(?:password|token|secret|server_key|signature_key)"?\s*[:=]\s*"?)[^\s",&]+`)
+
+// IsSensitive reports whether a key, e.g. a form field or query parameter,
+// holds a secret.
+func IsSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact hides the secrets found in free text.
+func Redact(value string) string {
+	return sensitiveValues.ReplaceAllString(value, "${1}"+redacted)
+}
+
+func redact(groups []string, attr slog.Attr) slog.Attr {
+	if IsSensitive(attr.Key) {
+		return slog.String(attr.Key, redacted)
+	}
+
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		attr.Value = slog.StringValue(Redact(attr.Value.String()))
+	case slog.KindAny:
+		if err, ok := attr.Value.Any().(error); ok {
+			attr.Value = slog.StringValue(Redact(err.Error()))
+		}
+	}
+
+	return attr
+}
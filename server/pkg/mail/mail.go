@@ -0,0 +1,87 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gopkg.in/gomail.v2"
+)
+
+type Attachment struct {
+	Name    string
+	Content []byte
+}
+
+type Message struct {
+	To          string
+	Subject     string
+	HTML        string
+	Attachments []Attachment
+}
+
+// Mailer sends emails. Tests can swap the SMTP mailer for a fake.
+type Mailer interface {
+	Send(ctx context.Context, message Message) error
+}
+
+type Config struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+type smtpMailer struct {
+	config Config
+}
+
+func NewSMTPMailer(config Config) Mailer {
+	return &smtpMailer{config}
+}
+
+func (m *smtpMailer) Send(ctx context.Context, message Message) (err error) {
+	_, span := otel.Tracer("housy/pkg/mail").Start(ctx, "smtp.Send", trace.WithSpanKind(trace.SpanKindClient))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+	span.SetAttributes(attribute.String("smtp.host", m.config.Host), attribute.Int("mail.attachments", len(message.Attachments)))
+
+	mailer := gomail.NewMessage()
+	mailer.SetHeader("From", m.config.From)
+	mailer.SetHeader("To", message.To)
+	mailer.SetHeader("Subject", message.Subject)
+	mailer.SetBody("text/html", message.HTML)
+
+	for _, attachment := range message.Attachments {
+		content := attachment.Content
+		mailer.Attach(attachment.Name, gomail.SetCopyFunc(func(w io.Writer) error {
+			_, err := w.Write(content)
+			return err
+		}))
+	}
+
+	dialer := gomail.NewDialer(m.config.Host, m.config.Port, m.config.Username, m.config.Password)
+	return dialer.DialAndSend(mailer)
+}
+
+// Check verifies the SMTP settings are complete. It doesn't connect, so a
+// slow mail server can't fail the readiness probe.
+func (m *smtpMailer) Check() error {
+	if m.config.Host == "" || m.config.Port == 0 {
+		return errors.New("smtp host and port are not set")
+	}
+	if m.config.Username == "" || m.config.Password == "" {
+		return errors.New("smtp credentials are not set")
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry holds every Housy metric, served by Handler.
+var Registry = prometheus.NewRegistry()
+
+var (
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "housy_http_request_duration_seconds",
+		Help:    "Latency of HTTP requests by route template, method and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	BookingsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "housy_bookings_created_total",
+		Help: "Bookings created.",
+	})
+
+	PaymentOutcomes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "housy_payment_notifications_total",
+		Help: "Payment notifications received by resulting transaction status.",
+	}, []string{"status"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		requestDuration,
+		BookingsCreated,
+		PaymentOutcomes,
+	)
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// RegisterDB exposes the connection pool stats of the database.
+func RegisterDB(db *sql.DB, name string) {
+	register(collectors.NewDBStatsCollector(db, name))
+}
+
+// RegisterQueue exposes the number of jobs waiting in a queue.
+func RegisterQueue(name string, depth func() int) {
+	register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name:        "housy_job_queue_depth",
+		Help:        "Jobs waiting in a background queue.",
+		ConstLabels: prometheus.Labels{"queue": name},
+	}, func() float64 {
+		return float64(depth())
+	}))
+}
+
+// register replaces a collector of the same metrics registered before,
+// which happens when the HTTP handler is built more than once in the same
+// process. The metrics then describe the latest handler and its database.
+func register(collector prometheus.Collector) {
+	Registry.Unregister(collector)
+	Registry.MustRegister(collector)
+}
+
+// Instrument records the latency of the requests of a mux router, labelled
+// by route template so IDs in paths don't create a series each.
+func Instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(recorder, r)
+
+		route := "unknown"
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+		requestDuration.WithLabelValues(route, r.Method, strconv.Itoa(recorder.status)).Observe(time.Since(start).Seconds())
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,27 @@
+package metrics
+
+import "testing"
+
+func TestRegisterQueueReplacesPreviousQueue(t *testing.T) {
+	RegisterQueue("test", func() int { return 1 })
+	RegisterQueue("test", func() int { return 2 })
+
+	families, err := Registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() != "housy_job_queue_depth" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			if metric.GetLabel()[0].GetValue() == "test" {
+				if got := metric.GetGauge().GetValue(); got != 2 {
+					t.Fatalf("queue depth = %v, want 2", got)
+				}
+				return
+			}
+		}
+	}
+	t.Fatal("the queue depth of test isn't registered")
+}
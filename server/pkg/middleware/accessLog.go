@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"housy/pkg/logging"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// AccessLog writes one record per request with its status and latency.
+func AccessLog(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+			next.ServeHTTP(recorder, r)
+
+			level := slog.LevelInfo
+			if recorder.status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+
+			logger.LogAttrs(r.Context(), level, "request",
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.String("query", redactQuery(r.URL.Query())),
+				slog.Int("status", recorder.status),
+				slog.Int("bytes", recorder.bytes),
+				slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+				slog.String("remote_addr", r.RemoteAddr),
+				slog.String("user_agent", r.UserAgent()),
+			)
+		})
+	}
+}
+
+func redactQuery(query url.Values) string {
+	for key := range query {
+		if logging.IsSensitive(key) {
+			query.Set(key, "REDACTED")
+		}
+	}
+	return query.Encode()
+}
+
+// statusRecorder remembers the status code and size of the response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+// Flush lets streamed responses, such as exports, reach the client.
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
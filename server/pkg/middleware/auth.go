@@ -1,45 +1,53 @@
 package middleware
 
 import (
-  "context"
-  dto "housy/dto/result"
-  jwtToken "housy/pkg/jwt"
-  "encoding/json"
-  "net/http"
-  "strings"
+	"context"
+	"encoding/json"
+	dto "housy/dto/result"
+	jwtToken "housy/pkg/jwt"
+	"net/http"
+	"strings"
 )
 
-type Result struct {
-  Code    int         `json:"code"`
-  Data    interface{} `json:"data"`
-  Message string      `json:"message"`
+// Auth rejects requests without a token signed with secretKey, the claims
+// of the token are put in the context as userInfo.
+func Auth(next http.HandlerFunc, secretKey string) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		parts := strings.Fields(r.Header.Get("Authorization"))
+		if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+			writeError(w, http.StatusUnauthorized, "a bearer token is required")
+			return
+		}
+
+		claims, err := jwtToken.DecodeToken(parts[1], secretKey)
+
+		if err != nil {
+			writeError(w, http.StatusUnauthorized, "invalid or expired token")
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), "userInfo", claims)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
 }
 
-func Auth(next http.HandlerFunc) http.HandlerFunc {
-  return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-    w.Header().Set("Content-Type", "application/json")
-
-    token := r.Header.Get("Authorization")
-
-    if token == "" {
-      w.WriteHeader(http.StatusUnauthorized)
-      response := dto.ErrorResult{Code: http.StatusBadRequest, Message: "unauthorized"}
-      json.NewEncoder(w).Encode(response)
-      return
-    }
-
-    token = strings.Split(token, " ")[1]
-    claims, err := jwtToken.DecodeToken(token)
-
-    if err != nil {
-      w.WriteHeader(http.StatusUnauthorized)
-      response := Result{Code: http.StatusUnauthorized, Message: "unauthorized"}
-      json.NewEncoder(w).Encode(response)
-      return
-    }
-
-    ctx := context.WithValue(r.Context(), "userInfo", claims)
-    r = r.WithContext(ctx)
-    next.ServeHTTP(w, r.WithContext(ctx))
-  })
-}
\ No newline at end of file
+// OptionalAuth is Auth for public endpoints whose responses depend on the
+// caller. Requests without a token go through anonymously, a token that
+// doesn't verify is still rejected.
+func OptionalAuth(next http.HandlerFunc, secretKey string) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		Auth(next, secretKey).ServeHTTP(w, r)
+	})
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(dto.NewError(status, message))
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"housy/pkg/logging"
+	"net/http"
+	"regexp"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID keeps IDs sent by clients or proxies short and printable.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID reuses the X-Request-ID header of the request, or generates a
+// new ID, and sets it on the response and the request context.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(logging.WithRequestID(r.Context(), id)))
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -2,44 +2,32 @@ package middleware
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"io/ioutil"
+	"housy/pkg/storage"
+	"log/slog"
 	"net/http"
 )
 
-func UploadFile(next http.HandlerFunc, formImage string) http.HandlerFunc {
+func UploadFile(next http.HandlerFunc, formImage string, store storage.Storage) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		file, _, err := r.FormFile(formImage)
 		if err != nil {
-			fmt.Println(err)
-			json.NewEncoder(w).Encode("Error Retrieving the File")
+			slog.WarnContext(r.Context(), "upload: retrieving the file failed", "field", formImage, "error", err)
+			writeError(w, http.StatusBadRequest, "the "+formImage+" file is required")
 			return
 		}
 		defer file.Close()
 		const MAX_UPLOAD_SIZE = 10 << 20
 		r.ParseMultipartForm(MAX_UPLOAD_SIZE)
 		if r.ContentLength > MAX_UPLOAD_SIZE {
-			w.WriteHeader(http.StatusBadRequest)
-			response := Result{Code: http.StatusBadRequest, Message: "Max sizes are 10MB"}
-			json.NewEncoder(w).Encode(response)
+			writeError(w, http.StatusRequestEntityTooLarge, "max size is 10MB")
 			return
 		}
-		tempFile, err := ioutil.TempFile("uploads", "image-*.png")
+		filename, err := store.Save(file, "image-*.png")
 		if err != nil {
-			fmt.Println(err)
-			fmt.Println("path upload error")
-			json.NewEncoder(w).Encode(err)
+			slog.ErrorContext(r.Context(), "upload: saving the file failed", "error", err)
+			writeError(w, http.StatusInternalServerError, "internal server error")
 			return
 		}
-		defer tempFile.Close()
-		fileBytes, err := ioutil.ReadAll(file)
-		if err != nil {
-			fmt.Println(err)
-		}
-		tempFile.Write(fileBytes)
-		data := tempFile.Name()
-		filename := data[8:]
 		ctx := context.WithValue(r.Context(), "dataFile", filename)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
@@ -1,30 +0,0 @@
-package mysql
-
-import (
-	"fmt"
-	"os"
-
-	"gorm.io/driver/mysql"
-	"gorm.io/gorm"
-)
-
-var DB *gorm.DB
-
-func DatabaseInit() {
-	var DB_HOST = os.Getenv("DB_HOST")
-	var DB_USER = os.Getenv("DB_USER")
-	var DB_PASSWORD = os.Getenv("DB_PASSWORD")
-	var DB_NAME = os.Getenv("DB_NAME")
-	var DB_PORT = os.Getenv("DB_PORT")
-	var err error
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s", DB_USER, DB_PASSWORD, DB_HOST, DB_PORT, DB_NAME)
-	DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
-	// dsn := "root:root@tcp(127.0.0.1:3306)/db_housy_mux?charset=utf8mb4&parseTime=True&loc=Local"
-	// DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
-
-	if err != nil {
-		panic(err)
-	}
-
-	fmt.Println("Connected to Database")
-}
@@ -0,0 +1,91 @@
+package payment
+
+import (
+	"context"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/midtrans/midtrans-go"
+	"github.com/midtrans/midtrans-go/snap"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Gateway creates payments for bookings. Tests can swap Midtrans for a fake.
+type Gateway interface {
+	CreateTransaction(ctx context.Context, request *snap.Request) (*snap.Response, error)
+}
+
+type Config struct {
+	ServerKey  string
+	ClientKey  string
+	Production bool
+}
+
+type midtransGateway struct {
+	client      snap.Client
+	environment midtrans.EnvironmentType
+}
+
+func NewMidtrans(config Config) Gateway {
+	environment := midtrans.Sandbox
+	if config.Production {
+		environment = midtrans.Production
+	}
+
+	var client snap.Client
+	client.New(config.ServerKey, environment)
+
+	return &midtransGateway{client, environment}
+}
+
+func (g *midtransGateway) CreateTransaction(ctx context.Context, request *snap.Request) (*snap.Response, error) {
+	ctx, span := otel.Tracer("housy/pkg/payment").Start(ctx, "midtrans.snap.CreateTransaction", trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+	span.SetAttributes(attribute.String("midtrans.order_id", request.TransactionDetails.OrderID))
+
+	// the midtrans client drops the context of its requests, so each call
+	// gets a client whose transport puts it back
+	client := g.client
+	client.HttpClient = &midtrans.HttpClientImplementation{
+		HttpClient: &http.Client{
+			Timeout:   midtrans.DefaultGoHttpClient.Timeout,
+			Transport: contextTransport{ctx, otelhttp.NewTransport(http.DefaultTransport)},
+		},
+		Logger: midtrans.GetDefaultLogger(g.environment),
+	}
+
+	response, err := client.CreateTransaction(request)
+	// err is a *midtrans.Error, returning it as is would make a nil pointer
+	// a non-nil error
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Message)
+		return response, err
+	}
+
+	return response, nil
+}
+
+// VerifySignature reports whether signature is the signature_key Midtrans
+// puts in the notifications of an order, the SHA-512 of the order id, the
+// status code, the gross amount and the server key.
+func VerifySignature(serverKey, orderId, statusCode, grossAmount, signature string) bool {
+	sum := sha512.Sum512([]byte(orderId + statusCode + grossAmount + serverKey))
+	want := hex.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(want), []byte(signature)) == 1
+}
+
+type contextTransport struct {
+	ctx  context.Context
+	next http.RoundTripper
+}
+
+func (t contextTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	return t.next.RoundTrip(r.WithContext(t.ctx))
+}
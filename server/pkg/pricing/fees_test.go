@@ -0,0 +1,82 @@
+package pricing
+
+import (
+	"housy/models"
+	"testing"
+)
+
+func TestQuoteFees(t *testing.T) {
+	tests := []struct {
+		name        string
+		price       int
+		nights      int
+		cleaningFee int
+		deposit     int
+		fees        Fees
+		promo       int
+		serviceFee  int
+		tax         int
+		total       int
+		hostFee     int
+		ownerPayout int
+	}{
+		// 12.5 of service fee rounds up, 28.93 of tax rounds up
+		{name: "rounding up", price: 100, nights: 2, cleaningFee: 50, fees: Fees{ServicePercent: 5, TaxPercent: 11},
+			serviceFee: 13, tax: 29, total: 292, ownerPayout: 250},
+		// 5.05 of service fee and 3.03 of host fee round down
+		{name: "rounding down", price: 101, nights: 1, fees: Fees{ServicePercent: 5, HostPercent: 3, TaxPercent: 11},
+			serviceFee: 5, tax: 12, total: 118, hostFee: 3, ownerPayout: 98},
+		{name: "the service fee is taxed", price: 200, nights: 1, fees: Fees{ServicePercent: 10, TaxPercent: 10},
+			serviceFee: 20, tax: 22, total: 242, ownerPayout: 200},
+		{name: "the deposit isn't taxed", price: 100, nights: 3, deposit: 500, fees: Fees{HostPercent: 10, TaxPercent: 10},
+			tax: 30, total: 830, hostFee: 30, ownerPayout: 270},
+		{name: "the cleaning fee pays the host fee", price: 100, nights: 1, cleaningFee: 100, fees: Fees{HostPercent: 10},
+			total: 200, hostFee: 20, ownerPayout: 180},
+		// the platform pays for the promo code, the owner is paid in full
+		{name: "promo code", price: 100, nights: 2, cleaningFee: 50, fees: Fees{ServicePercent: 5, HostPercent: 3, TaxPercent: 11}, promo: 50,
+			serviceFee: 10, tax: 23, total: 233, hostFee: 8, ownerPayout: 242},
+		{name: "no fees", price: 100, nights: 2, deposit: 100,
+			total: 300, ownerPayout: 200},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := Pricing{
+				House: models.House{Price: test.price, TypeRent: "day", CleaningFee: test.cleaningFee, Deposit: test.deposit},
+				Fees:  test.fees,
+			}
+			checkIn := date(t, "2027-03-01")
+			quote := p.Quote(checkIn, checkIn.AddDate(0, 0, test.nights))
+			if test.promo > 0 {
+				var err error
+				quote, err = p.ApplyPromo(quote, models.PromoCode{Code: "PROMO", Kind: models.PromoFixed, Amount: test.promo}, checkIn)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if quote.ServiceFee != test.serviceFee || quote.Tax != test.tax || quote.Total != test.total {
+				t.Errorf("service fee %d, tax %d, total %d, want %d, %d, %d", quote.ServiceFee, quote.Tax, quote.Total, test.serviceFee, test.tax, test.total)
+			}
+			if quote.HostFee != test.hostFee || quote.OwnerPayout != test.ownerPayout {
+				t.Errorf("host fee %d, owner payout %d, want %d, %d", quote.HostFee, quote.OwnerPayout, test.hostFee, test.ownerPayout)
+			}
+
+			// every rupiah of the total goes to the owner, the platform, the
+			// tax office or back to the tenant, less what the platform pays
+			// for the promo code
+			parts := quote.OwnerPayout + quote.HostFee + quote.ServiceFee + quote.Tax + quote.Deposit - quote.PromoDiscount
+			if parts != quote.Total {
+				t.Errorf("the payout and the fees add up to %d, want the total %d", parts, quote.Total)
+			}
+
+			items := 0
+			for _, item := range quote.Items() {
+				items += item.Amount
+			}
+			if items != quote.Total {
+				t.Errorf("the items add up to %d, want the total %d", items, quote.Total)
+			}
+		})
+	}
+}
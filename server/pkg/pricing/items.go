@@ -0,0 +1,63 @@
+package pricing
+
+import (
+	"fmt"
+	"housy/models"
+	"strconv"
+)
+
+// Kinds of the line items of a booking.
+const (
+	ItemRent        = "rent"
+	ItemDiscount    = "discount"
+	ItemPromo       = "promo"
+	ItemCleaningFee = "cleaning_fee"
+	ItemServiceFee  = "service_fee"
+	ItemTax         = "tax"
+	ItemDeposit     = "deposit"
+)
+
+// Items itemizes the quote, the amounts add up to its total. The nights are
+// grouped by rule and price, in the order of the stay, and the discounts
+// are negative. Items of zero are left out.
+func (q Quote) Items() []models.TransactionItem {
+	var items []models.TransactionItem
+
+	for _, night := range q.Nights {
+		description := "Nights at the base rate"
+		if night.Rule != "" {
+			description = "Nights at " + night.Rule
+		}
+
+		grouped := false
+		for i := range items {
+			if items[i].UnitPrice == night.Price && items[i].Description == description {
+				items[i].Quantity++
+				items[i].Amount += night.Price
+				grouped = true
+				break
+			}
+		}
+		if !grouped {
+			items = append(items, models.TransactionItem{Kind: ItemRent, Description: description, Quantity: 1, UnitPrice: night.Price, Amount: night.Price})
+		}
+	}
+
+	add := func(kind, description string, amount int) {
+		if amount != 0 {
+			items = append(items, models.TransactionItem{Kind: kind, Description: description, Quantity: 1, UnitPrice: amount, Amount: amount})
+		}
+	}
+	add(ItemDiscount, fmt.Sprintf("Stay discount %d%%", q.DiscountPercent), -q.Discount)
+	add(ItemPromo, "Promo code "+q.PromoCode, -q.PromoDiscount)
+	add(ItemCleaningFee, "Cleaning fee", q.CleaningFee)
+	add(ItemServiceFee, "Service fee "+formatPercent(q.fees.ServicePercent), q.ServiceFee)
+	add(ItemTax, q.fees.TaxName+" "+formatPercent(q.fees.TaxPercent), q.Tax)
+	add(ItemDeposit, "Refundable deposit", q.Deposit)
+
+	return items
+}
+
+func formatPercent(percent float64) string {
+	return strconv.FormatFloat(percent, 'f', -1, 64) + "%"
+}
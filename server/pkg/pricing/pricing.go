@@ -0,0 +1,184 @@
+package pricing
+
+import (
+	"housy/models"
+	"math"
+	"strings"
+	"time"
+)
+
+// DateLayout is the layout of the dates of stays and pricing rules.
+const DateLayout = "2006-01-02"
+
+// Weekdays are the names of the days used by PricingRule.Weekdays, from
+// Sunday like time.Weekday.
+var Weekdays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// Fees are the percents the platform adds to and withholds from bookings.
+type Fees struct {
+	ServicePercent float64
+	HostPercent    float64
+	TaxPercent     float64
+	TaxName        string
+}
+
+// Pricing prices the stays of a house from its price, its pricing rules and
+// discounts, its cleaning fee and deposit and the fees of the platform.
+type Pricing struct {
+	House     models.House
+	Rules     []models.PricingRule
+	Discounts []models.StayDiscount
+	Fees      Fees
+}
+
+// Night is the price of the night starting on Date. Rule names the pricing
+// rule that set the price, empty for the base price. MinNights is the
+// minimum stay of a check-in on Date.
+type Night struct {
+	Date      time.Time
+	Price     int
+	Rule      string
+	MinNights int
+}
+
+// Quote is the price of a stay. The rent, the sum of its nights less the
+// stay discount and the discount of the promo code if any, is charged with
+// the cleaning fee, the service fee, the tax and the deposit. OwnerPayout
+// is what the owner gets: the rent before the promo code, which the
+// platform pays for, and the cleaning fee, less the host fee.
+type Quote struct {
+	CheckIn         time.Time
+	CheckOut        time.Time
+	Nights          []Night
+	MinNights       int
+	Subtotal        int
+	DiscountPercent int
+	Discount        int
+	PromoCode       string
+	PromoDiscount   int
+	CleaningFee     int
+	ServiceFee      int
+	Tax             int
+	Deposit         int
+	Total           int
+	HostFee         int
+	OwnerPayout     int
+	fees            Fees
+}
+
+// BaseNightly is the price of a night no rule prices. The price of monthly
+// and yearly rentals is spread over 30 and 365 nights.
+func (p Pricing) BaseNightly() int {
+	switch strings.ToLower(p.House.TypeRent) {
+	case "month":
+		return int(math.Round(float64(p.House.Price) / 30))
+	case "year":
+		return int(math.Round(float64(p.House.Price) / 365))
+	}
+	return p.House.Price
+}
+
+// Night prices the night starting on date. Of the rules covering it, the
+// most specific one with a price wins: dates and weekdays, then dates only,
+// then weekdays only, then neither, the latest rule first on a tie. The
+// minimum stay is the largest of the rules covering it.
+func (p Pricing) Night(date time.Time) Night {
+	night := Night{Date: date, Price: p.BaseNightly()}
+
+	best := -1
+	for _, rule := range p.Rules {
+		if !covers(rule, date) {
+			continue
+		}
+		if rule.MinNights > night.MinNights {
+			night.MinNights = rule.MinNights
+		}
+		if rule.Price > 0 && specificity(rule) >= best {
+			best = specificity(rule)
+			night.Price = rule.Price
+			night.Rule = rule.Name
+		}
+	}
+	return night
+}
+
+// Quote prices the stay from checkIn to checkOut, checkOut excluded. The
+// largest discount whose minimum the stay reaches applies.
+func (p Pricing) Quote(checkIn, checkOut time.Time) Quote {
+	quote := Quote{CheckIn: checkIn, CheckOut: checkOut}
+
+	for date := checkIn; date.Before(checkOut); date = date.AddDate(0, 0, 1) {
+		night := p.Night(date)
+		if date.Equal(checkIn) {
+			quote.MinNights = night.MinNights
+		}
+		quote.Nights = append(quote.Nights, night)
+		quote.Subtotal += night.Price
+	}
+
+	for _, discount := range p.Discounts {
+		if len(quote.Nights) >= discount.MinNights && discount.Percent > quote.DiscountPercent {
+			quote.DiscountPercent = discount.Percent
+		}
+	}
+	quote.Discount = quote.Subtotal * quote.DiscountPercent / 100
+	quote.CleaningFee = p.House.CleaningFee
+	quote.Deposit = p.House.Deposit
+	quote.fees = p.Fees
+
+	return quote.withFees()
+}
+
+// Rent is the price of the nights less the discounts.
+func (q Quote) Rent() int {
+	return q.Subtotal - q.Discount - q.PromoDiscount
+}
+
+// withFees computes the fees and the total from the rent. Fees are rounded
+// to the rupiah.
+func (q Quote) withFees() Quote {
+	charged := q.Rent() + q.CleaningFee
+	q.ServiceFee = percentOf(charged, q.fees.ServicePercent)
+	q.Tax = percentOf(charged+q.ServiceFee, q.fees.TaxPercent)
+	q.Total = charged + q.ServiceFee + q.Tax + q.Deposit
+
+	earned := q.Subtotal - q.Discount + q.CleaningFee
+	q.HostFee = percentOf(earned, q.fees.HostPercent)
+	q.OwnerPayout = earned - q.HostFee
+	return q
+}
+
+func percentOf(amount int, percent float64) int {
+	return int(math.Round(float64(amount) * percent / 100))
+}
+
+func covers(rule models.PricingRule, date time.Time) bool {
+	day := date.Format(DateLayout)
+	if rule.StartDate != "" && day < rule.StartDate {
+		return false
+	}
+	if rule.EndDate != "" && day > rule.EndDate {
+		return false
+	}
+	if rule.Weekdays != "" {
+		weekday := Weekdays[date.Weekday()]
+		for _, name := range strings.Split(rule.Weekdays, ",") {
+			if name == weekday {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func specificity(rule models.PricingRule) int {
+	specificity := 0
+	if rule.StartDate != "" || rule.EndDate != "" {
+		specificity += 2
+	}
+	if rule.Weekdays != "" {
+		specificity++
+	}
+	return specificity
+}
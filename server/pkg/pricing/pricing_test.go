@@ -0,0 +1,154 @@
+package pricing
+
+import (
+	"housy/models"
+	"testing"
+	"time"
+)
+
+func date(t *testing.T, value string) time.Time {
+	t.Helper()
+
+	day, err := time.Parse(DateLayout, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return day
+}
+
+func TestNightRulePrecedence(t *testing.T) {
+	// 2027-03-01 is a Monday
+	p := Pricing{
+		House: models.House{Price: 100, TypeRent: "day"},
+		Rules: []models.PricingRule{
+			{Name: "everyday", Price: 110, MinNights: 2},
+			{Name: "weekend", Weekdays: "fri,sat", Price: 150},
+			{Name: "festival", StartDate: "2027-03-05", EndDate: "2027-03-10", Price: 200, MinNights: 3},
+			{Name: "festival saturday", StartDate: "2027-03-05", EndDate: "2027-03-10", Weekdays: "sat", Price: 250},
+			{Name: "holiday minimum", StartDate: "2027-03-20", EndDate: "2027-03-21", MinNights: 5},
+			{Name: "early weekend", Weekdays: "fri,sat", Price: 140},
+		},
+	}
+
+	tests := []struct {
+		date      string
+		price     int
+		rule      string
+		minNights int
+	}{
+		{"2027-03-01", 110, "everyday", 2},
+		// the later of two rules with weekdays only wins
+		{"2027-03-12", 140, "early weekend", 2},
+		// dates beat weekdays
+		{"2027-03-05", 200, "festival", 3},
+		// dates and weekdays beat dates
+		{"2027-03-06", 250, "festival saturday", 3},
+		// the end date is included
+		{"2027-03-10", 200, "festival", 3},
+		{"2027-03-11", 110, "everyday", 2},
+		// a rule without a price only sets the minimum stay
+		{"2027-03-21", 110, "everyday", 5},
+	}
+
+	for _, test := range tests {
+		t.Run(test.date, func(t *testing.T) {
+			night := p.Night(date(t, test.date))
+			if night.Price != test.price || night.Rule != test.rule || night.MinNights != test.minNights {
+				t.Errorf("Night() = %d by %q with a minimum of %d, want %d by %q with a minimum of %d",
+					night.Price, night.Rule, night.MinNights, test.price, test.rule, test.minNights)
+			}
+		})
+	}
+}
+
+func TestBaseNightly(t *testing.T) {
+	tests := []struct {
+		typeRent string
+		price    int
+		want     int
+	}{
+		{"day", 100, 100},
+		{"month", 3000, 100},
+		{"Month", 3010, 100},
+		{"year", 36500, 100},
+	}
+
+	for _, test := range tests {
+		p := Pricing{House: models.House{Price: test.price, TypeRent: test.typeRent}}
+		if got := p.BaseNightly(); got != test.want {
+			t.Errorf("BaseNightly() of %d a %s = %d, want %d", test.price, test.typeRent, got, test.want)
+		}
+	}
+}
+
+func TestQuoteNights(t *testing.T) {
+	p := Pricing{
+		House: models.House{Price: 100, TypeRent: "day"},
+		Rules: []models.PricingRule{
+			{Name: "festival", StartDate: "2027-03-05", EndDate: "2027-03-10", Price: 200, MinNights: 3},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		checkIn   string
+		checkOut  string
+		nights    int
+		subtotal  int
+		minNights int
+	}{
+		{"the check out night isn't charged", "2027-03-01", "2027-03-02", 1, 100, 0},
+		{"no nights", "2027-03-01", "2027-03-01", 0, 0, 0},
+		{"into the rule", "2027-03-03", "2027-03-06", 3, 400, 0},
+		{"out of the rule", "2027-03-09", "2027-03-12", 3, 500, 3},
+		{"over the new year", "2026-12-30", "2027-01-02", 3, 300, 0},
+		{"over the end of february", "2027-02-27", "2027-03-02", 3, 300, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			quote := p.Quote(date(t, test.checkIn), date(t, test.checkOut))
+			if len(quote.Nights) != test.nights || quote.Subtotal != test.subtotal {
+				t.Errorf("got %d nights for %d, want %d for %d", len(quote.Nights), quote.Subtotal, test.nights, test.subtotal)
+			}
+			// the minimum stay is the one of the check in night
+			if quote.MinNights != test.minNights {
+				t.Errorf("MinNights = %d, want %d", quote.MinNights, test.minNights)
+			}
+		})
+	}
+}
+
+func TestQuoteStayDiscounts(t *testing.T) {
+	p := Pricing{
+		House: models.House{Price: 100, TypeRent: "day"},
+		Discounts: []models.StayDiscount{
+			{MinNights: 7, Percent: 10},
+			{MinNights: 28, Percent: 25},
+			{MinNights: 7, Percent: 5},
+		},
+	}
+
+	tests := []struct {
+		nights   int
+		percent  int
+		discount int
+	}{
+		{6, 0, 0},
+		{7, 10, 70},
+		{27, 10, 270},
+		{28, 25, 700},
+		{40, 25, 1000},
+	}
+
+	for _, test := range tests {
+		checkIn := date(t, "2027-03-01")
+		quote := p.Quote(checkIn, checkIn.AddDate(0, 0, test.nights))
+		if quote.DiscountPercent != test.percent || quote.Discount != test.discount {
+			t.Errorf("%d nights: got %d%% off, %d, want %d%% off, %d", test.nights, quote.DiscountPercent, quote.Discount, test.percent, test.discount)
+		}
+		if rent := quote.Rent(); rent != test.nights*100-test.discount {
+			t.Errorf("%d nights: Rent() = %d, want %d", test.nights, rent, test.nights*100-test.discount)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"housy/models"
+	"strings"
+	"time"
+)
+
+// ApplyPromo takes the promo code off the rent of the quote of a stay booked
+// on day, and computes the fees again. The error says why the code doesn't
+// apply to the stay, its usage limits are left to the caller. Percent
+// discounts and the minimum spend apply to the rent after the stay
+// discount.
+func (p Pricing) ApplyPromo(quote Quote, promo models.PromoCode, day time.Time) (Quote, error) {
+	today := day.Format(DateLayout)
+	if promo.ValidFrom != "" && today < promo.ValidFrom {
+		return quote, errors.New("is not valid yet")
+	}
+	if promo.ValidUntil != "" && today > promo.ValidUntil {
+		return quote, errors.New("has expired")
+	}
+	if promo.HouseId != 0 && promo.HouseId != p.House.ID {
+		return quote, errors.New("is not valid for this house")
+	}
+	if promo.CityName != "" && !strings.EqualFold(promo.CityName, p.House.CityName) {
+		return quote, errors.New("is only valid in " + promo.CityName)
+	}
+	rent := quote.Rent()
+	if rent < promo.MinSpend {
+		return quote, fmt.Errorf("needs a total of at least %d", promo.MinSpend)
+	}
+
+	discount := promo.Amount
+	if promo.Kind == models.PromoPercent {
+		discount = rent * promo.Amount / 100
+		if promo.MaxDiscount > 0 && discount > promo.MaxDiscount {
+			discount = promo.MaxDiscount
+		}
+	}
+	if discount > rent {
+		discount = rent
+	}
+
+	quote.PromoCode = promo.Code
+	quote.PromoDiscount = discount
+	return quote.withFees(), nil
+}
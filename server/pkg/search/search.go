@@ -0,0 +1,237 @@
+package search
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/custom"
+	"github.com/blevesearch/bleve/v2/analysis/token/lowercase"
+	"github.com/blevesearch/bleve/v2/analysis/tokenizer/unicode"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/search/highlight/highlighter/html"
+	"github.com/blevesearch/bleve/v2/search/query"
+)
+
+// Document is the text of a house the index searches.
+type Document struct {
+	ID          int
+	Name        string
+	Description string
+	Address     string
+	CityName    string
+}
+
+// Hit is a house matching a search. Highlights holds, by field, a snippet
+// of the field escaped as HTML, with the matching words in <mark> tags.
+type Hit struct {
+	ID         int
+	Score      float64
+	Highlights map[string]string
+}
+
+// Index is the full-text index of the houses. Indexing a document that is
+// already indexed replaces it.
+type Index interface {
+	Index(docs ...Document) error
+	Delete(ids ...int) error
+	Search(text string, limit int) ([]Hit, error)
+	IDs() ([]int, error)
+	Close() error
+}
+
+// fields are the indexed fields, named like the JSON of a house, with the
+// boost of their matches.
+var fields = []struct {
+	name  string
+	boost float64
+}{
+	{"name", 3},
+	{"cityname", 2},
+	{"address", 1},
+	{"description", 1},
+}
+
+// analyzerName splits text on word boundaries and lowercases it. Listings
+// mix Indonesian and English, so there is no stemming nor stop words.
+const analyzerName = "housy_text"
+
+type bleveIndex struct {
+	index bleve.Index
+}
+
+// Open opens the index in dir, creating it when it doesn't exist. The
+// directory is locked while the index is open, opening it a second time
+// fails after a few seconds.
+func Open(dir string) (Index, error) {
+	index, err := bleve.OpenUsing(dir, map[string]interface{}{"bolt_timeout": "5s"})
+	if errors.Is(err, bleve.ErrorIndexPathDoesNotExist) {
+		if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+			return nil, err
+		}
+		index, err = bleve.New(dir, indexMapping())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &bleveIndex{index}, nil
+}
+
+// OpenMemory returns an empty index kept in memory, for tests.
+func OpenMemory() (Index, error) {
+	index, err := bleve.NewMemOnly(indexMapping())
+	if err != nil {
+		return nil, err
+	}
+
+	return &bleveIndex{index}, nil
+}
+
+func indexMapping() mapping.IndexMapping {
+	indexMapping := bleve.NewIndexMapping()
+	indexMapping.AddCustomAnalyzer(analyzerName, map[string]interface{}{
+		"type":          custom.Name,
+		"tokenizer":     unicode.Name,
+		"token_filters": []string{lowercase.Name},
+	})
+
+	text := bleve.NewTextFieldMapping()
+	text.Analyzer = analyzerName
+
+	house := bleve.NewDocumentStaticMapping()
+	for _, field := range fields {
+		house.AddFieldMappingsAt(field.name, text)
+	}
+
+	indexMapping.DefaultMapping = house
+	indexMapping.DefaultAnalyzer = analyzerName
+	return indexMapping
+}
+
+func (i *bleveIndex) Index(docs ...Document) error {
+	batch := i.index.NewBatch()
+	for _, doc := range docs {
+		err := batch.Index(strconv.Itoa(doc.ID), map[string]interface{}{
+			"name":        doc.Name,
+			"cityname":    doc.CityName,
+			"address":     doc.Address,
+			"description": doc.Description,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return i.index.Batch(batch)
+}
+
+func (i *bleveIndex) Delete(ids ...int) error {
+	batch := i.index.NewBatch()
+	for _, id := range ids {
+		batch.Delete(strconv.Itoa(id))
+	}
+	return i.index.Batch(batch)
+}
+
+// Search returns the limit best matches of the words of text, best first.
+// A house matches on any of the words, the more it has the better. Words of
+// four letters or more also match with a typo, eight or more with two, and
+// rank below the exact matches.
+func (i *bleveIndex) Search(text string, limit int) ([]Hit, error) {
+	analyzer := i.index.Mapping().AnalyzerNamed(analyzerName)
+
+	var words []query.Query
+	seen := make(map[string]bool)
+	for _, token := range analyzer.Analyze([]byte(text)) {
+		term := string(token.Term)
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+
+		var matches []query.Query
+		for _, field := range fields {
+			exact := bleve.NewTermQuery(term)
+			exact.SetField(field.name)
+			exact.SetBoost(2 * field.boost)
+			matches = append(matches, exact)
+
+			if fuzziness := typos(term); fuzziness > 0 {
+				fuzzy := bleve.NewFuzzyQuery(term)
+				fuzzy.SetField(field.name)
+				fuzzy.SetFuzziness(fuzziness)
+				fuzzy.SetBoost(field.boost)
+				matches = append(matches, fuzzy)
+			}
+		}
+		words = append(words, bleve.NewDisjunctionQuery(matches...))
+	}
+	if len(words) == 0 {
+		return []Hit{}, nil
+	}
+
+	request := bleve.NewSearchRequestOptions(bleve.NewDisjunctionQuery(words...), limit, 0, false)
+	request.Highlight = bleve.NewHighlightWithStyle(html.Name)
+
+	result, err := i.index.Search(request)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]Hit, 0, len(result.Hits))
+	for _, match := range result.Hits {
+		id, err := strconv.Atoi(match.ID)
+		if err != nil {
+			continue
+		}
+
+		highlights := make(map[string]string)
+		for field, fragments := range match.Fragments {
+			if len(fragments) > 0 {
+				highlights[field] = fragments[0]
+			}
+		}
+		hits = append(hits, Hit{ID: id, Score: match.Score, Highlights: highlights})
+	}
+	return hits, nil
+}
+
+// typos is the number of typos a word may have and still match.
+func typos(term string) int {
+	switch length := utf8.RuneCountInString(term); {
+	case length >= 8:
+		return 2
+	case length >= 4:
+		return 1
+	}
+	return 0
+}
+
+// IDs returns the ids of every indexed house.
+func (i *bleveIndex) IDs() ([]int, error) {
+	count, err := i.index.DocCount()
+	if err != nil {
+		return nil, err
+	}
+
+	request := bleve.NewSearchRequestOptions(bleve.NewMatchAllQuery(), int(count), 0, false)
+	result, err := i.index.Search(request)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(result.Hits))
+	for _, match := range result.Hits {
+		if id, err := strconv.Atoi(match.ID); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (i *bleveIndex) Close() error {
+	return i.index.Close()
+}
@@ -0,0 +1,192 @@
+package search
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// openTestIndex opens an index in a temporary directory with the houses of
+// the tests. The caller closes it.
+func openTestIndex(t *testing.T) (Index, string) {
+	t.Helper()
+
+	dir := filepath.Join(t.TempDir(), "houses.bleve")
+	index, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = index.Index(
+		Document{ID: 1, Name: "Villa Bandung", CityName: "Bandung", Address: "Jl. Dago", Description: "Quiet villa with a garden"},
+		Document{ID: 2, Name: "Kost Dago", CityName: "Bandung", Address: "Jl. Dago", Description: "Rooms near the villa district"},
+		Document{ID: 3, Name: "Rumah Kuta", CityName: "Bali", Address: "Jl. Pantai Kuta", Description: "Walk to the <b>beach</b> & spa"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return index, dir
+}
+
+func ids(hits []Hit) []int {
+	found := make([]int, 0, len(hits))
+	for _, hit := range hits {
+		found = append(found, hit.ID)
+	}
+	return found
+}
+
+func sameIDs(got, want []int) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSearchTypos(t *testing.T) {
+	index, _ := openTestIndex(t)
+	defer index.Close()
+
+	tests := []struct {
+		text string
+		want []int
+	}{
+		{"kuta", []int{3}},
+		{"KUTA", []int{3}},
+		// four letters or more match with a typo
+		{"kita", []int{3}},
+		// eight letters or more match with two
+		{"bandunggg", []int{1, 2}},
+		// shorter words must match exactly
+		{"spo", []int{}},
+		{"spa", []int{3}},
+		{"", []int{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			hits, err := index.Search(test.text, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// only the matches count here, the ranking is tested below
+			found := ids(hits)
+			sort.Ints(found)
+			if !sameIDs(found, test.want) {
+				t.Errorf("Search(%q) = %v, want %v", test.text, found, test.want)
+			}
+		})
+	}
+}
+
+func TestSearchRanking(t *testing.T) {
+	index, _ := openTestIndex(t)
+	defer index.Close()
+
+	tests := []struct {
+		text string
+		want []int
+		why  string
+	}{
+		{"villa", []int{1, 2}, "a match in the name beats one in the description"},
+		{"dago", []int{2, 1}, "a match in the name beats one in the address"},
+		{"bandung villa", []int{1, 2}, "more matching words rank higher"},
+		{"kuts bali", []int{3}, "a typo still matches"},
+	}
+
+	for _, test := range tests {
+		hits, err := index.Search(test.text, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found := ids(hits); !sameIDs(found, test.want) {
+			t.Errorf("Search(%q) = %v, want %v: %s", test.text, found, test.want, test.why)
+		}
+	}
+
+	// the exact word ranks above the same word with a typo
+	if err := index.Index(Document{ID: 4, Name: "Villa Kute"}, Document{ID: 5, Name: "Villa Kuta"}); err != nil {
+		t.Fatal(err)
+	}
+	hits, err := index.Search("kuta", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scores := make(map[int]float64)
+	for _, hit := range hits {
+		scores[hit.ID] = hit.Score
+	}
+	if scores[4] == 0 || scores[5] <= scores[4] {
+		t.Errorf("Search(kuta) scores %v, want Kuta above Kute", scores)
+	}
+}
+
+func TestSearchHighlightsAreEscaped(t *testing.T) {
+	index, _ := openTestIndex(t)
+	defer index.Close()
+
+	hits, err := index.Search("beach", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) != 1 {
+		t.Fatalf("got %d hits, want 1", len(hits))
+	}
+	highlight := hits[0].Highlights["description"]
+	if !strings.Contains(highlight, "<mark>beach</mark>") {
+		t.Errorf("highlight %q doesn't mark the match", highlight)
+	}
+	if strings.Contains(highlight, "<b>") || !strings.Contains(highlight, "&amp;") {
+		t.Errorf("highlight %q isn't escaped", highlight)
+	}
+}
+
+func TestIndexDeleteAndReopen(t *testing.T) {
+	index, dir := openTestIndex(t)
+
+	if err := index.Delete(2); err != nil {
+		t.Fatal(err)
+	}
+	hits, err := index.Search("dago", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found := ids(hits); !sameIDs(found, []int{1}) {
+		t.Errorf("Search(dago) = %v after deleting 2, want [1]", found)
+	}
+
+	// indexing a house again replaces it
+	if err := index.Index(Document{ID: 1, Name: "Villa Lembang", CityName: "Bandung"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := index.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	index, err = Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer index.Close()
+
+	all, err := index.IDs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 {
+		t.Errorf("IDs() = %v after reopening, want 1 and 3", all)
+	}
+	hits, err = index.Search("dago", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) != 0 {
+		t.Errorf("Search(dago) = %v, want the replaced house gone", ids(hits))
+	}
+}
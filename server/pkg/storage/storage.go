@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Storage keeps uploaded files. Files are referred to by the name Save
+// returns, which is what gets stored in the database.
+type Storage interface {
+	Save(r io.Reader, pattern string) (string, error)
+	Remove(name string) error
+	URL(name string) string
+}
+
+type local struct {
+	dir     string
+	baseURL string
+}
+
+// NewLocal stores files in dir, served to clients under baseURL.
+func NewLocal(dir string, baseURL string) Storage {
+	return &local{dir, baseURL}
+}
+
+func (s *local) Save(r io.Reader, pattern string) (string, error) {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return "", err
+	}
+
+	file, err := ioutil.TempFile(s.dir, pattern)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, r); err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+
+	return filepath.Base(file.Name()), nil
+}
+
+func (s *local) Remove(name string) error {
+	return os.Remove(filepath.Join(s.dir, filepath.Base(name)))
+}
+
+func (s *local) URL(name string) string {
+	return s.baseURL + name
+}
+
+// Check verifies the upload directory exists, or can be created, and is
+// writable.
+func (s *local) Check() error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+
+	file, err := ioutil.TempFile(s.dir, ".check-*")
+	if err != nil {
+		return err
+	}
+	file.Close()
+	return os.Remove(file.Name())
+}
@@ -0,0 +1,106 @@
+package tlscert
+
+import (
+	"context"
+	"crypto/tls"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Reloader serves a certificate and key pair from disk and swaps it when the
+// files change, so renewed certificates are picked up without a restart.
+type Reloader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+func NewReloader(certFile, keyFile string) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload reads the pair again. The current certificate is kept when the new
+// one can't be loaded, e.g. while the files are half written.
+func (r *Reloader) Reload() error {
+	modTime, err := r.lastModified()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.modTime = modTime
+	r.mu.Unlock()
+
+	return nil
+}
+
+// GetCertificate is meant for tls.Config.GetCertificate.
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.cert, nil
+}
+
+// Watch checks the files every interval and reloads them when they changed,
+// until ctx is done.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		modTime, err := r.lastModified()
+		if err != nil {
+			slog.Error("tls certificate check failed", "error", err)
+			continue
+		}
+
+		r.mu.RLock()
+		changed := modTime.After(r.modTime)
+		r.mu.RUnlock()
+
+		if changed {
+			if err := r.Reload(); err != nil {
+				slog.Error("tls certificate reload failed", "error", err)
+				continue
+			}
+			slog.Info("tls certificate reloaded")
+		}
+	}
+}
+
+// lastModified is the latest modification time of the certificate and key.
+func (r *Reloader) lastModified() (time.Time, error) {
+	var latest time.Time
+	for _, name := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(name)
+		if err != nil {
+			return latest, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
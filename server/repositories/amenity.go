@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"housy/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrAmenityInUse is returned when deleting an amenity houses still have.
+var ErrAmenityInUse = errors.New("amenity is used by houses and can't be deleted")
+
+type AmenityRepository interface {
+	FindAmenities(ctx context.Context) ([]models.Amenity, error)
+	FindAmenitiesByCode(ctx context.Context, codes []string) ([]models.Amenity, error)
+	GetAmenity(ctx context.Context, ID int) (models.Amenity, error)
+	CreateAmenity(ctx context.Context, amenity models.Amenity) (models.Amenity, error)
+	UpdateAmenity(ctx context.Context, amenity models.Amenity) (models.Amenity, error)
+	DeleteAmenity(ctx context.Context, ID int) error
+}
+
+type amenityRepository struct {
+	repository
+}
+
+func RepositoryAmenity(db *gorm.DB) *amenityRepository {
+	return &amenityRepository{repository{db}}
+}
+
+// orderAmenities sorts amenities the way the catalog is shown, also used
+// when preloading the amenities of houses.
+func orderAmenities(db *gorm.DB) *gorm.DB {
+	return db.Order("category, label")
+}
+
+func (r *amenityRepository) FindAmenities(ctx context.Context) ([]models.Amenity, error) {
+	var amenities []models.Amenity
+	err := r.conn(ctx).Scopes(orderAmenities).Find(&amenities).Error
+
+	return amenities, err
+}
+
+func (r *amenityRepository) FindAmenitiesByCode(ctx context.Context, codes []string) ([]models.Amenity, error) {
+	var amenities []models.Amenity
+	err := r.conn(ctx).Where("code IN ?", codes).Scopes(orderAmenities).Find(&amenities).Error
+
+	return amenities, err
+}
+
+func (r *amenityRepository) GetAmenity(ctx context.Context, ID int) (models.Amenity, error) {
+	var amenity models.Amenity
+	err := r.conn(ctx).First(&amenity, ID).Error
+
+	return amenity, err
+}
+
+func (r *amenityRepository) CreateAmenity(ctx context.Context, amenity models.Amenity) (models.Amenity, error) {
+	err := r.conn(ctx).Create(&amenity).Error
+
+	return amenity, err
+}
+
+func (r *amenityRepository) UpdateAmenity(ctx context.Context, amenity models.Amenity) (models.Amenity, error) {
+	err := r.conn(ctx).Save(&amenity).Error
+
+	return amenity, err
+}
+
+// DeleteAmenity removes an amenity from the catalog, amenities of any house,
+// deleted houses included, are refused.
+func (r *amenityRepository) DeleteAmenity(ctx context.Context, ID int) error {
+	var count int64
+	if err := r.conn(ctx).Model(&models.HouseAmenity{}).Where("amenity_id = ?", ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrAmenityInUse
+	}
+
+	return r.conn(ctx).Delete(&models.Amenity{}, ID).Error
+}
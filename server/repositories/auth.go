@@ -1,33 +1,38 @@
 package repositories
 
 import (
+	"context"
 	"housy/models"
 
 	"gorm.io/gorm"
 )
 
 type AuthRepository interface {
-	SignUp(user models.User) (models.User, error)
-	SignIn(username string) (models.User, error)
-	Getuser(ID int) (models.User, error)
+	SignUp(ctx context.Context, user models.User) (models.User, error)
+	SignIn(ctx context.Context, username string) (models.User, error)
+	Getuser(ctx context.Context, ID int) (models.User, error)
 }
 
-func RepositoryAuth(db *gorm.DB) *repository {
-	return &repository{db}
+type authRepository struct {
+	repository
 }
-func (r *repository) SignUp(user models.User) (models.User, error) {
-	err := r.db.Create(&user).Error
+
+func RepositoryAuth(db *gorm.DB) *authRepository {
+	return &authRepository{repository{db}}
+}
+func (r *authRepository) SignUp(ctx context.Context, user models.User) (models.User, error) {
+	err := r.conn(ctx).Create(&user).Error
 
 	return user, err
 }
-func (r *repository) SignIn(username string) (models.User, error) {
+func (r *authRepository) SignIn(ctx context.Context, username string) (models.User, error) {
 	var user models.User
-	err := r.db.Where("username = ?", username).First(&user).Error
+	err := r.conn(ctx).Where("username = ?", username).First(&user).Error
 
 	return user, err
 }
-func (r *repository) Getuser(ID int) (models.User, error) {
+func (r *authRepository) Getuser(ctx context.Context, ID int) (models.User, error) {
 	var user models.User
-	err := r.db.First(&user, ID).Error
+	err := r.conn(ctx).First(&user, ID).Error
 	return user, err
 }
@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+// The check in and check out dates are stored as YYYY-MM-DD strings. These
+// helpers build the date expressions each supported database understands.
+
+// daysBetween returns the SQL for the number of days from start to end. The
+// end expression comes first on every database, which matters when both
+// contain placeholders.
+func (r *repository) daysBetween(start, end string) string {
+	switch r.db.Dialector.Name() {
+	case "postgres":
+		return fmt.Sprintf("(CAST(%s AS date) - CAST(%s AS date))", end, start)
+	case "sqlite":
+		return fmt.Sprintf("CAST(julianday(%s) - julianday(%s) AS integer)", end, start)
+	}
+	return fmt.Sprintf("DATEDIFF(%s, %s)", end, start)
+}
+
+// least returns the SQL for the smaller of two values.
+func (r *repository) least(a, b string) string {
+	if r.db.Dialector.Name() == "sqlite" {
+		return fmt.Sprintf("MIN(%s, %s)", a, b)
+	}
+	return fmt.Sprintf("LEAST(%s, %s)", a, b)
+}
+
+// greatest returns the SQL for the larger of two values.
+func (r *repository) greatest(a, b string) string {
+	if r.db.Dialector.Name() == "sqlite" {
+		return fmt.Sprintf("MAX(%s, %s)", a, b)
+	}
+	return fmt.Sprintf("GREATEST(%s, %s)", a, b)
+}
+
+// isDuplicateKey reports whether err is the violation of a unique index.
+func (r *repository) isDuplicateKey(err error) bool {
+	if translator, ok := r.db.Dialector.(gorm.ErrorTranslator); ok && errors.Is(translator.Translate(err), gorm.ErrDuplicatedKey) {
+		return true
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == 1062
+	}
+
+	// SQLITE_CONSTRAINT_UNIQUE and SQLITE_CONSTRAINT_PRIMARYKEY
+	var sqliteErr interface{ Code() int }
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code() == 2067 || sqliteErr.Code() == 1555
+	}
+
+	return false
+}
@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"context"
+	"housy/models"
+
+	"gorm.io/gorm"
+)
+
+type ExportJobRepository interface {
+	CreateExportJob(ctx context.Context, job models.ExportJob) (models.ExportJob, error)
+	GetExportJob(ctx context.Context, ID int) (models.ExportJob, error)
+	UpdateExportJob(ctx context.Context, job models.ExportJob) (models.ExportJob, error)
+	FindUnfinishedExportJobs(ctx context.Context) ([]models.ExportJob, error)
+}
+
+type exportJobRepository struct {
+	repository
+}
+
+func RepositoryExportJob(db *gorm.DB) *exportJobRepository {
+	return &exportJobRepository{repository{db}}
+}
+
+func (r *exportJobRepository) CreateExportJob(ctx context.Context, job models.ExportJob) (models.ExportJob, error) {
+	err := r.conn(ctx).Create(&job).Error
+
+	return job, err
+}
+
+func (r *exportJobRepository) GetExportJob(ctx context.Context, ID int) (models.ExportJob, error) {
+	var job models.ExportJob
+	err := r.conn(ctx).First(&job, ID).Error
+
+	return job, err
+}
+
+func (r *exportJobRepository) UpdateExportJob(ctx context.Context, job models.ExportJob) (models.ExportJob, error) {
+	err := r.conn(ctx).Save(&job).Error
+
+	return job, err
+}
+
+// FindUnfinishedExportJobs returns the jobs still pending or running, oldest
+// first.
+func (r *exportJobRepository) FindUnfinishedExportJobs(ctx context.Context) ([]models.ExportJob, error) {
+	var jobs []models.ExportJob
+	err := r.conn(ctx).Where("status IN ?", []string{"pending", "running"}).Order("id").Find(&jobs).Error
+
+	return jobs, err
+}
@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"housy/models"
+	"testing"
+)
+
+func TestWithinMatchesDistanceKm(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	houses := RepositoryHouse(db)
+
+	// a grid around Oslo, far enough north for a degree of longitude to be
+	// half a degree of latitude
+	near := GeoPoint{Lat: 59.91, Lng: 10.75}
+	var grid []models.House
+	for i := -6; i <= 6; i++ {
+		for j := -6; j <= 6; j++ {
+			lat, lng := near.Lat+float64(i)*0.1, near.Lng+float64(j)*0.2
+			house, err := houses.CreateHouse(ctx, models.House{Name: fmt.Sprintf("House %d %d", i, j), Price: 100, TypeRent: "day", Latitude: &lat, Longitude: &lng})
+			if err != nil {
+				t.Fatal(err)
+			}
+			grid = append(grid, house)
+		}
+	}
+
+	filter := HouseFilter{Near: &near, RadiusKm: 45}
+	found, err := houses.FindHouses(ctx, filter, Page{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	count, err := houses.CountHouses(ctx, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := make(map[int]bool)
+	for _, house := range found {
+		ids[house.ID] = true
+	}
+	want := 0
+	for _, house := range grid {
+		distance := near.DistanceKm(*house.Latitude, *house.Longitude)
+		if inside := distance <= filter.RadiusKm; inside != ids[house.ID] {
+			t.Errorf("%s is %.2f km away, found %v", house.Name, distance, ids[house.ID])
+		}
+		if distance <= filter.RadiusKm {
+			want++
+		}
+	}
+	if len(found) != want || count != int64(want) {
+		t.Errorf("found %d houses and counted %d, want %d", len(found), count, want)
+	}
+}
+
+func TestCrossesAntimeridian(t *testing.T) {
+	tests := []struct {
+		point  GeoPoint
+		radius float64
+		want   bool
+	}{
+		{GeoPoint{Lat: -6.2, Lng: 106.8}, 500, false},
+		{GeoPoint{Lat: -17.7, Lng: 178.4}, 100, false},
+		{GeoPoint{Lat: -17.7, Lng: 178.4}, 300, true},
+		{GeoPoint{Lat: 65, Lng: -179}, 50, true},
+		{GeoPoint{Lat: 90, Lng: 0}, 1, true},
+	}
+
+	for _, test := range tests {
+		if got := test.point.CrossesAntimeridian(test.radius); got != test.want {
+			t.Errorf("%+v CrossesAntimeridian(%v) = %v, want %v", test.point, test.radius, got, test.want)
+		}
+	}
+}
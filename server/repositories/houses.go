@@ -1,51 +1,241 @@
 package repositories
 
 import (
+	"context"
 	"housy/models"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// HouseSortDistance sorts the house list nearest to HouseFilter.Near first.
+const HouseSortDistance = "distance"
+
+// HouseFilter holds the optional filters of the house list and exports.
+// Houses must have every amenity code in Amenities. RadiusKm only applies
+// with Near. Matching, when not nil, keeps only the houses with these ids,
+// such as the hits of a text search. Sort is empty for the order of
+// Matching, or the id order without it, or HouseSortDistance.
+type HouseFilter struct {
+	CityName  string
+	TypeRent  string
+	MinPrice  int
+	MaxPrice  int
+	Bedroom   int
+	Bathroom  int
+	Amenities []string
+	Near      *GeoPoint
+	RadiusKm  float64
+	Bounds    *GeoBounds
+	Matching  []int
+	Sort      string
+}
+
 type HouseRepository interface {
-	FindHouses() ([]models.House, error)
-	GetHouse(ID int) (models.House, error)
-	CreateHouse(House models.House) (models.House, error)
-	UpdateHouse(House models.House) (models.House, error)
-	DeleteHouse(House models.House) (models.House, error)
+	FindHouses(ctx context.Context, filter HouseFilter, page Page) ([]models.House, error)
+	CountHouses(ctx context.Context, filter HouseFilter) (int64, error)
+	EachHouse(ctx context.Context, filter HouseFilter, fn func(house models.House) error) error
+	GetHouse(ctx context.Context, ID int) (models.House, error)
+	CreateHouse(ctx context.Context, House models.House) (models.House, error)
+	CreateHouses(ctx context.Context, houses []models.House) ([]models.House, error)
+	UpdateHouse(ctx context.Context, House models.House) (models.House, error)
+	DeleteHouse(ctx context.Context, House models.House) (models.House, error)
+	HasUpcomingBookings(ctx context.Context, ID int) (bool, error)
+	RestoreHouse(ctx context.Context, ID int) (models.House, error)
+	PurgeHouse(ctx context.Context, ID int) error
+}
+
+type houseRepository struct {
+	repository
+}
+
+func RepositoryHouse(db *gorm.DB) *houseRepository {
+	return &houseRepository{repository{db}}
+}
+
+func (f HouseFilter) scope(db *gorm.DB) *gorm.DB {
+	if f.CityName != "" {
+		db = db.Where("city_name LIKE ?", "%"+f.CityName+"%")
+	}
+	if f.TypeRent != "" {
+		db = db.Where("type_rent = ?", f.TypeRent)
+	}
+	if f.MinPrice != 0 {
+		db = db.Where("price >= ?", f.MinPrice)
+	}
+	if f.MaxPrice != 0 {
+		db = db.Where("price <= ?", f.MaxPrice)
+	}
+	if f.Bedroom != 0 {
+		db = db.Where("bedroom >= ?", f.Bedroom)
+	}
+	if f.Bathroom != 0 {
+		db = db.Where("bathroom >= ?", f.Bathroom)
+	}
+	if len(f.Amenities) > 0 {
+		codes := make(map[string]bool)
+		for _, code := range f.Amenities {
+			codes[code] = true
+		}
+		matching := db.Session(&gorm.Session{NewDB: true}).Model(&models.HouseAmenity{}).
+			Select("house_amenities.house_id").
+			Joins("JOIN amenities ON amenities.id = house_amenities.amenity_id").
+			Where("amenities.code IN ?", f.Amenities).
+			Group("house_amenities.house_id").
+			Having("COUNT(DISTINCT amenities.code) = ?", len(codes))
+		db = db.Where("id IN (?)", matching)
+	}
+	if f.Near != nil && f.RadiusKm > 0 {
+		db = db.Scopes(f.Near.within(f.RadiusKm))
+	}
+	if f.Bounds != nil {
+		db = db.Scopes(f.Bounds.scope)
+	}
+	if f.Matching != nil {
+		db = db.Where("id IN ?", f.Matching)
+	}
+	return db
+}
+
+func (f HouseFilter) order(db *gorm.DB) *gorm.DB {
+	if f.Sort == HouseSortDistance && f.Near != nil {
+		return f.Near.byDistance(db)
+	}
+	if f.Sort == "" && len(f.Matching) > 0 {
+		sql := "CASE id" + strings.Repeat(" WHEN ? THEN ?", len(f.Matching)) + " END, id"
+		vars := make([]interface{}, 0, 2*len(f.Matching))
+		for rank, id := range f.Matching {
+			vars = append(vars, id, rank)
+		}
+		return db.Clauses(clause.OrderBy{Expression: clause.Expr{SQL: sql, Vars: vars, WithoutParentheses: true}})
+	}
+	return db.Order("id")
 }
 
-func RepositoryHouse(db *gorm.DB) *repository {
-	return &repository{db}
+// withAmenities preloads the amenities of houses.
+func withAmenities(db *gorm.DB) *gorm.DB {
+	return db.Preload("Amenities", orderAmenities)
 }
 
-func (r *repository) FindHouses() ([]models.House, error) {
+func (r *houseRepository) FindHouses(ctx context.Context, filter HouseFilter, page Page) ([]models.House, error) {
 	var houses []models.House
-	err := r.db.Find(&houses).Error
+	err := r.conn(ctx).Scopes(withAmenities, filter.scope, filter.order, page.scope).Find(&houses).Error
 
 	return houses, err
 }
 
-func (r *repository) GetHouse(ID int) (models.House, error) {
+func (r *houseRepository) CountHouses(ctx context.Context, filter HouseFilter) (int64, error) {
+	var count int64
+	err := r.conn(ctx).Model(&models.House{}).Scopes(filter.scope).Count(&count).Error
+
+	return count, err
+}
+
+// EachHouse calls fn for every matching house, loading them in batches so
+// large exports don't hold every row in memory.
+func (r *houseRepository) EachHouse(ctx context.Context, filter HouseFilter, fn func(house models.House) error) error {
+	var houses []models.House
+	return r.conn(ctx).Scopes(withAmenities, filter.scope).FindInBatches(&houses, 500, func(tx *gorm.DB, batch int) error {
+		for _, house := range houses {
+			if err := fn(house); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
+
+func (r *houseRepository) GetHouse(ctx context.Context, ID int) (models.House, error) {
 	var house models.House
-	err := r.db.First(&house, ID).Error
+	err := r.conn(ctx).Scopes(withAmenities).First(&house, ID).Error
 
 	return house, err
 }
 
-func (r *repository) CreateHouse(house models.House) (models.House, error) {
-	err := r.db.Create(&house).Error // Using Create method
+func (r *houseRepository) CreateHouse(ctx context.Context, house models.House) (models.House, error) {
+	err := r.conn(ctx).Omit("Amenities.*").Create(&house).Error // Using Create method
 
 	return house, err
 }
 
-func (r *repository) UpdateHouse(house models.House) (models.House, error) {
-	err := r.db.Save(&house).Error
+// CreateHouses inserts the houses in a single database transaction, either
+// all of them are created or none.
+func (r *houseRepository) CreateHouses(ctx context.Context, houses []models.House) ([]models.House, error) {
+	err := r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.Omit("Amenities.*").Create(&houses).Error
+	})
+
+	return houses, err
+}
+
+// UpdateHouse saves the listing and replaces its amenities with
+// house.Amenities. The rating and review count are left out, they are
+// maintained by the reviews.
+func (r *houseRepository) UpdateHouse(ctx context.Context, house models.House) (models.House, error) {
+	err := r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("rating", "review_count", "Amenities").Save(&house).Error; err != nil {
+			return err
+		}
+		return tx.Model(&house).Omit("Amenities.*").Association("Amenities").Replace(house.Amenities)
+	})
 
 	return house, err
 }
 
-func (r *repository) DeleteHouse(house models.House) (models.House, error) {
-	err := r.db.Delete(&house).Error
+func (r *houseRepository) DeleteHouse(ctx context.Context, house models.House) (models.House, error) {
+	err := r.conn(ctx).Delete(&house).Error
 
 	return house, err
 }
+
+// HasUpcomingBookings reports whether the house has paid bookings that
+// haven't checked out yet.
+func (r *houseRepository) HasUpcomingBookings(ctx context.Context, ID int) (bool, error) {
+	var count int64
+	err := r.conn(ctx).Model(&models.Transaction{}).
+		Where("house_id = ? AND status_payment = ? AND check_out >= ?", ID, "success", time.Now().Format("2006-01-02")).
+		Count(&count).Error
+
+	return count > 0, err
+}
+
+func (r *houseRepository) RestoreHouse(ctx context.Context, ID int) (models.House, error) {
+	var house models.House
+	err := r.conn(ctx).Unscoped().Model(&house).Where("id = ?", ID).Update("deleted_at", nil).Error
+	if err != nil {
+		return house, err
+	}
+
+	return r.GetHouse(ctx, ID)
+}
+
+// PurgeHouse permanently deletes a house, houses that were ever booked are
+// part of the financial records and are refused. The house is taken out of
+// the wishlists it is in and its pricing is deleted.
+func (r *houseRepository) PurgeHouse(ctx context.Context, ID int) error {
+	var count int64
+	if err := r.conn(ctx).Unscoped().Model(&models.Transaction{}).Where("house_id = ?", ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrHasTransactions
+	}
+
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("house_id = ?", ID).Delete(&models.WishlistHouse{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("house_id = ?", ID).Delete(&models.HouseAmenity{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("house_id = ?", ID).Delete(&models.PricingRule{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("house_id = ?", ID).Delete(&models.StayDiscount{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&models.House{}, ID).Error
+	})
+}
@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"housy/models"
+
+	"gorm.io/gorm"
+)
+
+type InvoiceRepository interface {
+	CreateInvoice(ctx context.Context, invoice models.Invoice) (models.Invoice, error)
+	GetInvoiceByTransaction(ctx context.Context, TransactionId int) (models.Invoice, error)
+}
+
+type invoiceRepository struct {
+	repository
+}
+
+func RepositoryInvoice(db *gorm.DB) *invoiceRepository {
+	return &invoiceRepository{repository{db}}
+}
+
+// CreateInvoice stores the invoice and numbers it from its auto increment ID,
+// so invoice numbers are sequential. An existing invoice for the same
+// transaction is returned as is.
+func (r *invoiceRepository) CreateInvoice(ctx context.Context, invoice models.Invoice) (models.Invoice, error) {
+	err := r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing models.Invoice
+		result := tx.Where("transaction_id = ?", invoice.TransactionId).Limit(1).Find(&existing)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			invoice = existing
+			return nil
+		}
+
+		invoice.Number = fmt.Sprintf("PENDING/%d", invoice.TransactionId)
+		if err := tx.Create(&invoice).Error; err != nil {
+			return err
+		}
+
+		invoice.Number = fmt.Sprintf("INV/%d/%06d", invoice.IssuedAt.Year(), invoice.ID)
+		return tx.Model(&invoice).Update("number", invoice.Number).Error
+	})
+
+	return invoice, err
+}
+
+func (r *invoiceRepository) GetInvoiceByTransaction(ctx context.Context, TransactionId int) (models.Invoice, error) {
+	var invoice models.Invoice
+	err := r.conn(ctx).Preload("Transaction", unscoped).Preload("Transaction.House", unscoped).Preload("Transaction.User", unscoped).Preload("Transaction.Items", inOrder).First(&invoice, "transaction_id = ?", TransactionId).Error
+
+	return invoice, err
+}
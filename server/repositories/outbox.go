@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"context"
+	"housy/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type OutboxRepository interface {
+	AddOutboxEvent(ctx context.Context, event models.OutboxEvent) (models.OutboxEvent, error)
+	FindDueOutboxEvents(ctx context.Context, topic string, limit int) ([]models.OutboxEvent, error)
+	UpdateOutboxEvent(ctx context.Context, event models.OutboxEvent) (models.OutboxEvent, error)
+	CountPendingOutboxEvents(ctx context.Context, topic string) (int64, error)
+}
+
+type outboxRepository struct {
+	repository
+}
+
+func RepositoryOutbox(db *gorm.DB) *outboxRepository {
+	return &outboxRepository{repository{db}}
+}
+
+// AddOutboxEvent records the event as pending. Call it through a unit of
+// work so the event is only delivered when the change it belongs to commits.
+func (r *outboxRepository) AddOutboxEvent(ctx context.Context, event models.OutboxEvent) (models.OutboxEvent, error) {
+	event.Status = "pending"
+	if event.AvailableAt.IsZero() {
+		event.AvailableAt = time.Now()
+	}
+	err := r.conn(ctx).Create(&event).Error
+
+	return event, err
+}
+
+// FindDueOutboxEvents returns the pending events of the topic whose retry
+// time has come, oldest first.
+func (r *outboxRepository) FindDueOutboxEvents(ctx context.Context, topic string, limit int) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	err := r.conn(ctx).Where("topic = ? AND status = ? AND available_at <= ?", topic, "pending", time.Now()).Order("id").Limit(limit).Find(&events).Error
+
+	return events, err
+}
+
+func (r *outboxRepository) UpdateOutboxEvent(ctx context.Context, event models.OutboxEvent) (models.OutboxEvent, error) {
+	err := r.conn(ctx).Save(&event).Error
+
+	return event, err
+}
+
+func (r *outboxRepository) CountPendingOutboxEvents(ctx context.Context, topic string) (int64, error) {
+	var count int64
+	err := r.conn(ctx).Model(&models.OutboxEvent{}).Where("topic = ? AND status = ?", topic, "pending").Count(&count).Error
+
+	return count, err
+}
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+	"housy/models"
+
+	"gorm.io/gorm"
+)
+
+type PricingRepository interface {
+	FindPricingRules(ctx context.Context, houseId int) ([]models.PricingRule, error)
+	FindStayDiscounts(ctx context.Context, houseId int) ([]models.StayDiscount, error)
+	ReplacePricing(ctx context.Context, houseId int, rules []models.PricingRule, discounts []models.StayDiscount) error
+}
+
+type pricingRepository struct {
+	repository
+}
+
+func RepositoryPricing(db *gorm.DB) *pricingRepository {
+	return &pricingRepository{repository{db}}
+}
+
+func (r *pricingRepository) FindPricingRules(ctx context.Context, houseId int) ([]models.PricingRule, error) {
+	var rules []models.PricingRule
+	err := r.conn(ctx).Where("house_id = ?", houseId).Order("id").Find(&rules).Error
+
+	return rules, err
+}
+
+// FindStayDiscounts returns the discounts of the house, shortest stays
+// first.
+func (r *pricingRepository) FindStayDiscounts(ctx context.Context, houseId int) ([]models.StayDiscount, error) {
+	var discounts []models.StayDiscount
+	err := r.conn(ctx).Where("house_id = ?", houseId).Order("min_nights").Find(&discounts).Error
+
+	return discounts, err
+}
+
+// ReplacePricing replaces the rules and discounts of the house in a single
+// database transaction.
+func (r *pricingRepository) ReplacePricing(ctx context.Context, houseId int, rules []models.PricingRule, discounts []models.StayDiscount) error {
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("house_id = ?", houseId).Delete(&models.PricingRule{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("house_id = ?", houseId).Delete(&models.StayDiscount{}).Error; err != nil {
+			return err
+		}
+
+		for i := range rules {
+			rules[i].HouseId = houseId
+		}
+		for i := range discounts {
+			discounts[i].HouseId = houseId
+		}
+		if len(rules) > 0 {
+			if err := tx.Create(&rules).Error; err != nil {
+				return err
+			}
+		}
+		if len(discounts) > 0 {
+			return tx.Create(&discounts).Error
+		}
+		return nil
+	})
+}
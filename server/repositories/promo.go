@@ -0,0 +1,163 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"housy/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrPromoUsedUp is returned when redeeming a promo code that reached its
+// limit of uses, overall or for the user.
+var ErrPromoUsedUp = errors.New("promo code has been used up")
+
+type PromoRepository interface {
+	FindPromoCodes(ctx context.Context) ([]models.PromoCode, error)
+	GetPromoCode(ctx context.Context, ID int) (models.PromoCode, error)
+	GetPromoCodeByCode(ctx context.Context, code string) (models.PromoCode, error)
+	PromoCodeExists(ctx context.Context, code string) (bool, error)
+	CreatePromoCode(ctx context.Context, promo models.PromoCode) (models.PromoCode, error)
+	UpdatePromoCode(ctx context.Context, promo models.PromoCode) (models.PromoCode, error)
+	DeletePromoCode(ctx context.Context, ID int) error
+	CountUserRedemptions(ctx context.Context, promoCodeId int, userId int) (int, error)
+	RedeemPromoCode(ctx context.Context, promo models.PromoCode, redemption models.PromoRedemption) error
+	ReleasePromoRedemption(ctx context.Context, transactionId int) error
+}
+
+type promoRepository struct {
+	repository
+}
+
+func RepositoryPromo(db *gorm.DB) *promoRepository {
+	return &promoRepository{repository{db}}
+}
+
+func (r *promoRepository) FindPromoCodes(ctx context.Context) ([]models.PromoCode, error) {
+	var promos []models.PromoCode
+	err := r.conn(ctx).Order("id").Find(&promos).Error
+
+	return promos, err
+}
+
+func (r *promoRepository) GetPromoCode(ctx context.Context, ID int) (models.PromoCode, error) {
+	var promo models.PromoCode
+	err := r.conn(ctx).First(&promo, ID).Error
+
+	return promo, err
+}
+
+// GetPromoCodeByCode finds a promo code by its code, stored in uppercase.
+func (r *promoRepository) GetPromoCodeByCode(ctx context.Context, code string) (models.PromoCode, error) {
+	var promo models.PromoCode
+	err := r.conn(ctx).First(&promo, "code = ?", code).Error
+
+	return promo, err
+}
+
+// PromoCodeExists tells whether a promo code, deleted or not, has the code.
+// Codes aren't reused, the redemptions of a deleted code keep referring to
+// it.
+func (r *promoRepository) PromoCodeExists(ctx context.Context, code string) (bool, error) {
+	var count int64
+	err := r.conn(ctx).Unscoped().Model(&models.PromoCode{}).Where("code = ?", code).Count(&count).Error
+
+	return count > 0, err
+}
+
+func (r *promoRepository) CreatePromoCode(ctx context.Context, promo models.PromoCode) (models.PromoCode, error) {
+	err := r.conn(ctx).Create(&promo).Error
+
+	return promo, err
+}
+
+// UpdatePromoCode saves the promo code but its uses, which only
+// RedeemPromoCode and ReleasePromoRedemption change.
+func (r *promoRepository) UpdatePromoCode(ctx context.Context, promo models.PromoCode) (models.PromoCode, error) {
+	if err := r.conn(ctx).Omit("uses").Save(&promo).Error; err != nil {
+		return promo, err
+	}
+
+	return r.GetPromoCode(ctx, promo.ID)
+}
+
+// DeletePromoCode soft deletes the promo code, it can't be redeemed anymore.
+func (r *promoRepository) DeletePromoCode(ctx context.Context, ID int) error {
+	return r.conn(ctx).Delete(&models.PromoCode{}, ID).Error
+}
+
+// CountUserRedemptions returns how many bookings of the user redeemed the
+// promo code.
+func (r *promoRepository) CountUserRedemptions(ctx context.Context, promoCodeId int, userId int) (int, error) {
+	var usage models.PromoUsage
+	err := r.conn(ctx).Where("promo_code_id = ? AND user_id = ?", promoCodeId, userId).Limit(1).Find(&usage).Error
+
+	return usage.Uses, err
+}
+
+// RedeemPromoCode records the redemption and counts it against the limits
+// of the promo code, or returns ErrPromoUsedUp. The counters are increased
+// by conditional updates, so concurrent redemptions can't go over a limit:
+// the database serializes the updates of a row and checks the condition
+// against its latest value.
+func (r *promoRepository) RedeemPromoCode(ctx context.Context, promo models.PromoCode, redemption models.PromoRedemption) error {
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.PromoCode{}).
+			Where("id = ? AND (max_uses = 0 OR uses < max_uses)", promo.ID).
+			UpdateColumn("uses", gorm.Expr("uses + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrPromoUsedUp
+		}
+
+		usage := models.PromoUsage{PromoCodeId: promo.ID, UserId: redemption.UserId}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&usage).Error; err != nil {
+			return err
+		}
+		result = tx.Model(&models.PromoUsage{}).
+			Where("promo_code_id = ? AND user_id = ?", promo.ID, redemption.UserId).
+			Where("? = 0 OR uses < ?", promo.MaxUsesPerUser, promo.MaxUsesPerUser).
+			UpdateColumn("uses", gorm.Expr("uses + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrPromoUsedUp
+		}
+
+		redemption.PromoCodeId = promo.ID
+		return tx.Create(&redemption).Error
+	})
+}
+
+// ReleasePromoRedemption deletes the redemption of the transaction, if any,
+// and gives the use back to the promo code and the user.
+func (r *promoRepository) ReleasePromoRedemption(ctx context.Context, transactionId int) error {
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		var redemption models.PromoRedemption
+		if err := tx.Where("transaction_id = ?", transactionId).Limit(1).Find(&redemption).Error; err != nil {
+			return err
+		}
+		if redemption.ID == 0 {
+			return nil
+		}
+
+		if err := tx.Delete(&redemption).Error; err != nil {
+			return err
+		}
+
+		err := tx.Unscoped().Model(&models.PromoCode{}).
+			Where("id = ? AND uses > 0", redemption.PromoCodeId).
+			UpdateColumn("uses", gorm.Expr("uses - 1")).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&models.PromoUsage{}).
+			Where("promo_code_id = ? AND user_id = ? AND uses > 0", redemption.PromoCodeId, redemption.UserId).
+			UpdateColumn("uses", gorm.Expr("uses - 1")).Error
+	})
+}
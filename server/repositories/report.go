@@ -0,0 +1,137 @@
+package repositories
+
+import (
+	"context"
+	reportdto "housy/dto/report"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReportFilter narrows reports down to a date range of check in dates, a
+// single house or the houses of one owner. Zero values are ignored.
+type ReportFilter struct {
+	OwnerId int
+	HouseId int
+	From    string
+	To      string
+}
+
+type ReportRepository interface {
+	RevenueByMonth(ctx context.Context, filter ReportFilter) ([]reportdto.RevenueResponse, error)
+	Occupancy(ctx context.Context, filter ReportFilter) ([]reportdto.OccupancyResponse, error)
+	LengthOfStay(ctx context.Context, filter ReportFilter) ([]reportdto.LengthOfStayResponse, error)
+	CountByStatus(ctx context.Context, filter ReportFilter) ([]reportdto.StatusCount, error)
+	Cancellations(ctx context.Context, filter ReportFilter) ([]reportdto.CancellationResponse, error)
+}
+
+type reportRepository struct {
+	repository
+}
+
+func RepositoryReport(db *gorm.DB) *reportRepository {
+	return &reportRepository{repository{db}}
+}
+
+func (f ReportFilter) houses(db *gorm.DB) *gorm.DB {
+	if f.OwnerId != 0 {
+		db = db.Where("houses.owner_id = ?", f.OwnerId)
+	}
+	if f.HouseId != 0 {
+		db = db.Where("houses.id = ?", f.HouseId)
+	}
+	return db
+}
+
+func (f ReportFilter) transactions(db *gorm.DB) *gorm.DB {
+	db = f.houses(db.Joins("JOIN houses ON houses.id = transactions.house_id")).
+		Where("transactions.deleted_at IS NULL")
+	if f.From != "" {
+		db = db.Where("transactions.check_in >= ?", f.From)
+	}
+	if f.To != "" {
+		db = db.Where("transactions.check_in <= ?", f.To)
+	}
+	return db
+}
+
+// RevenueByMonth adds up what the owners earn from the paid bookings of every
+// month. The fees, the taxes and the deposits in the totals aren't theirs.
+func (r *reportRepository) RevenueByMonth(ctx context.Context, filter ReportFilter) ([]reportdto.RevenueResponse, error) {
+	var revenue []reportdto.RevenueResponse
+	err := r.conn(ctx).Table("transactions").
+		Select("SUBSTR(transactions.check_in, 1, 7) AS month, SUM(transactions.owner_payout) AS revenue, COUNT(*) AS bookings").
+		Scopes(filter.transactions).
+		Where("transactions.status_payment = ?", "success").
+		Group("SUBSTR(transactions.check_in, 1, 7)").
+		Order("month").
+		Scan(&revenue).Error
+
+	return revenue, err
+}
+
+// Occupancy counts the paid nights of every house that fall inside the
+// filter range, which must have both From and To set.
+func (r *reportRepository) Occupancy(ctx context.Context, filter ReportFilter) ([]reportdto.OccupancyResponse, error) {
+	to, err := time.Parse("2006-01-02", filter.To)
+	if err != nil {
+		return nil, err
+	}
+	end := to.AddDate(0, 0, 1).Format("2006-01-02")
+
+	var occupancy []reportdto.OccupancyResponse
+	err = r.conn(ctx).Table("houses").
+		Select("houses.id AS house_id, houses.name AS house_name, "+
+			"COALESCE(SUM("+r.daysBetween(r.greatest("transactions.check_in", "?"), r.least("transactions.check_out", "?"))+"), 0) AS booked_nights",
+			end, filter.From).
+		Joins("LEFT JOIN transactions ON transactions.house_id = houses.id AND transactions.status_payment = ? "+
+			"AND transactions.check_in < ? AND transactions.check_out > ? AND transactions.deleted_at IS NULL",
+			"success", end, filter.From).
+		Scopes(filter.houses).
+		Where("houses.deleted_at IS NULL").
+		Group("houses.id, houses.name").
+		Order("houses.id").
+		Scan(&occupancy).Error
+
+	return occupancy, err
+}
+
+func (r *reportRepository) LengthOfStay(ctx context.Context, filter ReportFilter) ([]reportdto.LengthOfStayResponse, error) {
+	var stays []reportdto.LengthOfStayResponse
+	err := r.conn(ctx).Table("transactions").
+		Select("houses.id AS house_id, houses.name AS house_name, COUNT(*) AS bookings, "+
+			"AVG("+r.daysBetween("transactions.check_in", "transactions.check_out")+") AS average_nights").
+		Scopes(filter.transactions).
+		Where("transactions.status_payment = ?", "success").
+		Group("houses.id, houses.name").
+		Order("houses.id").
+		Scan(&stays).Error
+
+	return stays, err
+}
+
+func (r *reportRepository) CountByStatus(ctx context.Context, filter ReportFilter) ([]reportdto.StatusCount, error) {
+	var counts []reportdto.StatusCount
+	err := r.conn(ctx).Table("transactions").
+		Select("transactions.status_payment AS status_payment, COUNT(*) AS total").
+		Scopes(filter.transactions).
+		Group("transactions.status_payment").
+		Scan(&counts).Error
+
+	return counts, err
+}
+
+// Cancellations counts the failed bookings of every month, with what the
+// owners would have earned from them.
+func (r *reportRepository) Cancellations(ctx context.Context, filter ReportFilter) ([]reportdto.CancellationResponse, error) {
+	var cancellations []reportdto.CancellationResponse
+	err := r.conn(ctx).Table("transactions").
+		Select("SUBSTR(transactions.check_in, 1, 7) AS month, COUNT(*) AS cancellations, SUM(transactions.owner_payout) AS lost_revenue").
+		Scopes(filter.transactions).
+		Where("transactions.status_payment = ?", "failed").
+		Group("SUBSTR(transactions.check_in, 1, 7)").
+		Order("month").
+		Scan(&cancellations).Error
+
+	return cancellations, err
+}
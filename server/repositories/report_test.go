@@ -0,0 +1,196 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	reportdto "housy/dto/report"
+	"housy/models"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestRevenueCountsOwnerPayouts(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	transaction := seedBooking(t, db)
+	if err := db.Model(&models.Transaction{}).Where("id = ?", transaction.ID).Update("status_payment", "success").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	revenue, err := RepositoryReport(db).RevenueByMonth(ctx, ReportFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the booking totals 260 with the service fee, the owner gets 250
+	if len(revenue) != 1 || revenue[0].Revenue != 250 {
+		t.Fatalf("revenue = %+v, want 250 in 2026-12", revenue)
+	}
+}
+
+// seedReport books the houses of two owners over December and January.
+// The houses of the first owner are returned first.
+func seedReport(t *testing.T, db *gorm.DB) (ownerA, ownerB int, houses []models.House) {
+	t.Helper()
+	ctx := context.Background()
+
+	users := RepositoryUser(db)
+	a, err := users.CreateUser(ctx, models.User{Username: "a", ListAsRole: "owner"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := users.CreateUser(ctx, models.User{Username: "b", ListAsRole: "owner"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tenant, err := users.CreateUser(ctx, models.User{Username: "tenant", ListAsRole: "tenant"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, owner := range []int{a.ID, a.ID, b.ID} {
+		house, err := RepositoryHouse(db).CreateHouse(ctx, models.House{Name: fmt.Sprintf("House %d", i+1), Price: 100, TypeRent: "day", OwnerId: owner})
+		if err != nil {
+			t.Fatal(err)
+		}
+		houses = append(houses, house)
+	}
+
+	bookings := []struct {
+		house    int
+		checkIn  string
+		checkOut string
+		status   string
+		payout   int
+		deleted  bool
+	}{
+		{0, "2026-12-01", "2026-12-03", "success", 200, false},
+		{0, "2026-12-30", "2027-01-02", "success", 300, false},
+		{1, "2027-01-10", "2027-01-15", "success", 500, false},
+		{2, "2027-01-05", "2027-01-06", "success", 100, false},
+		{0, "2027-01-20", "2027-01-22", "failed", 200, false},
+		{1, "2027-01-25", "2027-01-26", "pending", 100, false},
+		{0, "2026-12-10", "2026-12-11", "success", 999, true},
+	}
+	for _, booking := range bookings {
+		transaction := models.Transaction{
+			CheckIn:       booking.checkIn,
+			CheckOut:      booking.checkOut,
+			HouseId:       houses[booking.house].ID,
+			UserId:        tenant.ID,
+			Total:         booking.payout + 50,
+			OwnerPayout:   booking.payout,
+			StatusPayment: booking.status,
+		}
+		if err := db.Create(&transaction).Error; err != nil {
+			t.Fatal(err)
+		}
+		if booking.deleted {
+			if err := db.Delete(&transaction).Error; err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return a.ID, b.ID, houses
+}
+
+func TestRevenueByMonth(t *testing.T) {
+	db := openTestDB(t)
+	ownerA, ownerB, houses := seedReport(t, db)
+	repo := RepositoryReport(db)
+
+	tests := []struct {
+		name   string
+		filter ReportFilter
+		want   []reportdto.RevenueResponse
+	}{
+		// a stay counts in the month of its check in, deleted bookings don't
+		{"all", ReportFilter{}, []reportdto.RevenueResponse{{Month: "2026-12", Revenue: 500, Bookings: 2}, {Month: "2027-01", Revenue: 600, Bookings: 2}}},
+		{"owner", ReportFilter{OwnerId: ownerA}, []reportdto.RevenueResponse{{Month: "2026-12", Revenue: 500, Bookings: 2}, {Month: "2027-01", Revenue: 500, Bookings: 1}}},
+		{"other owner", ReportFilter{OwnerId: ownerB}, []reportdto.RevenueResponse{{Month: "2027-01", Revenue: 100, Bookings: 1}}},
+		{"house and range", ReportFilter{HouseId: houses[0].ID, From: "2026-12-15", To: "2027-01-31"}, []reportdto.RevenueResponse{{Month: "2026-12", Revenue: 300, Bookings: 1}}},
+		{"empty range", ReportFilter{From: "2027-02-01"}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			revenue, err := repo.RevenueByMonth(context.Background(), test.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(revenue) != len(test.want) {
+				t.Fatalf("got %+v, want %+v", revenue, test.want)
+			}
+			for i := range revenue {
+				if revenue[i] != test.want[i] {
+					t.Errorf("month %d = %+v, want %+v", i, revenue[i], test.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestOccupancyClipsStaysToTheRange(t *testing.T) {
+	db := openTestDB(t)
+	ownerA, _, houses := seedReport(t, db)
+
+	occupancy, err := RepositoryReport(db).Occupancy(context.Background(), ReportFilter{OwnerId: ownerA, From: "2026-12-01", To: "2026-12-31"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the stay over the new year has 2 of its 3 nights in December, the
+	// second house has no December stays and is listed with none
+	want := map[int]int{houses[0].ID: 4, houses[1].ID: 0}
+	if len(occupancy) != len(want) {
+		t.Fatalf("got %+v, want the 2 houses of the owner", occupancy)
+	}
+	for _, house := range occupancy {
+		if house.BookedNights != want[house.HouseId] {
+			t.Errorf("house %d has %d booked nights, want %d", house.HouseId, house.BookedNights, want[house.HouseId])
+		}
+	}
+}
+
+func TestLengthOfStayAndStatuses(t *testing.T) {
+	db := openTestDB(t)
+	ownerA, _, houses := seedReport(t, db)
+	ctx := context.Background()
+	repo := RepositoryReport(db)
+
+	stays, err := repo.LengthOfStay(ctx, ReportFilter{OwnerId: ownerA})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[int]reportdto.LengthOfStayResponse{
+		houses[0].ID: {Bookings: 2, AverageNights: 2.5},
+		houses[1].ID: {Bookings: 1, AverageNights: 5},
+	}
+	if len(stays) != len(want) {
+		t.Fatalf("got %+v, want 2 houses", stays)
+	}
+	for _, stay := range stays {
+		if stay.Bookings != want[stay.HouseId].Bookings || stay.AverageNights != want[stay.HouseId].AverageNights {
+			t.Errorf("house %d: %d bookings of %v nights, want %d of %v", stay.HouseId, stay.Bookings, stay.AverageNights, want[stay.HouseId].Bookings, want[stay.HouseId].AverageNights)
+		}
+	}
+
+	counts, err := repo.CountByStatus(ctx, ReportFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	statuses := make(map[string]int)
+	for _, count := range counts {
+		statuses[count.StatusPayment] = count.Total
+	}
+	if statuses["success"] != 4 || statuses["failed"] != 1 || statuses["pending"] != 1 {
+		t.Errorf("counts by status = %v, want 4 success, 1 failed and 1 pending", statuses)
+	}
+
+	cancellations, err := repo.Cancellations(ctx, ReportFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cancellations) != 1 || cancellations[0] != (reportdto.CancellationResponse{Month: "2027-01", Cancellations: 1, LostRevenue: 200}) {
+		t.Errorf("cancellations = %+v, want 1 in 2027-01 losing 200", cancellations)
+	}
+}
@@ -1,7 +1,105 @@
 package repositories
 
-import "gorm.io/gorm"
+import (
+	"context"
+	"errors"
 
+	"gorm.io/gorm"
+)
+
+// ErrHasTransactions is returned when purging a record that transactions
+// still refer to.
+var ErrHasTransactions = errors.New("record has transactions and can't be purged")
+
+// repository is embedded by every repository. db is either the connection
+// pool or the transaction of a unit of work.
 type repository struct {
 	db *gorm.DB
 }
+
+// conn returns the database bound to ctx, so queries are cancelled with the
+// request or worker that runs them.
+func (r *repository) conn(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+// unscoped is used to preload soft deleted associations, so bookings keep
+// showing the house and tenant they were made for.
+func unscoped(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
+
+// inOrder preloads the rows of a has many association in the order they
+// were created, such as the line items of a transaction.
+func inOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("id")
+}
+
+// Repositories holds every repository over the same connection.
+type Repositories struct {
+	Auth         AuthRepository
+	Users        UserRepository
+	Houses       HouseRepository
+	Transactions TransactionRepository
+	Invoices     InvoiceRepository
+	ExportJobs   ExportJobRepository
+	Reports      ReportRepository
+	Outbox       OutboxRepository
+	Reviews      ReviewRepository
+	Wishlists    WishlistRepository
+	Amenities    AmenityRepository
+	Pricing      PricingRepository
+	Promos       PromoRepository
+}
+
+func NewRepositories(db *gorm.DB) Repositories {
+	return Repositories{
+		Auth:         RepositoryAuth(db),
+		Users:        RepositoryUser(db),
+		Houses:       RepositoryHouse(db),
+		Transactions: RepositoryTransaction(db),
+		Invoices:     RepositoryInvoice(db),
+		ExportJobs:   RepositoryExportJob(db),
+		Reports:      RepositoryReport(db),
+		Outbox:       RepositoryOutbox(db),
+		Reviews:      RepositoryReview(db),
+		Wishlists:    RepositoryWishlist(db),
+		Amenities:    RepositoryAmenity(db),
+		Pricing:      RepositoryPricing(db),
+		Promos:       RepositoryPromo(db),
+	}
+}
+
+// UnitOfWork groups writes to several repositories atomically.
+type UnitOfWork interface {
+	// WithTx runs fn in a database transaction. The transaction commits when
+	// fn returns nil and rolls back when it returns an error or panics.
+	WithTx(ctx context.Context, fn func(tx Repositories) error) error
+}
+
+type unitOfWork struct {
+	db *gorm.DB
+}
+
+func NewUnitOfWork(db *gorm.DB) UnitOfWork {
+	return &unitOfWork{db}
+}
+
+func (u *unitOfWork) WithTx(ctx context.Context, fn func(tx Repositories) error) error {
+	return u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(NewRepositories(tx))
+	})
+}
+
+// Page limits a list to one page. The zero Page is the whole list.
+type Page struct {
+	Limit  int
+	Offset int
+}
+
+func (p Page) scope(db *gorm.DB) *gorm.DB {
+	if p.Limit > 0 {
+		db = db.Limit(p.Limit).Offset(p.Offset)
+	}
+	return db
+}
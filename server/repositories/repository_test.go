@@ -0,0 +1,221 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	migrate "housy/database"
+	"housy/models"
+	"housy/pkg/database"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// openTestDB returns a migrated in-memory SQLite database.
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := database.Open(database.Config{Driver: database.SQLite, Name: ":memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	if err := migrate.Up(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// seedBooking creates a tenant, the house of an owner and a booking of it.
+func seedBooking(t *testing.T, db *gorm.DB) models.Transaction {
+	t.Helper()
+	ctx := context.Background()
+
+	owner, err := RepositoryUser(db).CreateUser(ctx, models.User{Fullname: "Owner", Username: "owner", ListAsRole: "owner"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tenant, err := RepositoryUser(db).CreateUser(ctx, models.User{Fullname: "Tenant", Username: "tenant", ListAsRole: "tenant"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	house, err := RepositoryHouse(db).CreateHouse(ctx, models.House{Name: "Monas", CityName: "Jakarta", Price: 100, TypeRent: "day", OwnerId: owner.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transaction, err := RepositoryTransaction(db).CreateTransaction(ctx, models.Transaction{
+		ID:            1,
+		CheckIn:       "2026-12-01",
+		CheckOut:      "2026-12-03",
+		HouseId:       house.ID,
+		UserId:        tenant.ID,
+		Total:         260,
+		OwnerPayout:   250,
+		StatusPayment: "pending",
+		Items: []models.TransactionItem{
+			{Kind: "rent", Description: "Nights at the base rate", Quantity: 2, UnitPrice: 100, Amount: 200},
+			{Kind: "cleaning_fee", Description: "Cleaning fee", Quantity: 1, UnitPrice: 50, Amount: 50},
+			{Kind: "service_fee", Description: "Service fee 4%", Quantity: 1, UnitPrice: 10, Amount: 10},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return transaction
+}
+
+func TestDialectHelpers(t *testing.T) {
+	r := &repository{openTestDB(t)}
+
+	tests := []struct {
+		expr string
+		want int
+	}{
+		{r.daysBetween("'2026-12-30'", "'2027-01-02'"), 3},
+		{r.least("4", "7"), 4},
+		{r.greatest("4", "7"), 7},
+	}
+
+	for _, test := range tests {
+		var got int
+		if err := r.db.Raw("SELECT " + test.expr).Scan(&got).Error; err != nil {
+			t.Fatalf("%s: %v", test.expr, err)
+		}
+		if got != test.want {
+			t.Errorf("%s = %d, want %d", test.expr, got, test.want)
+		}
+	}
+}
+
+func TestTransactionItemsRoundTrip(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	repo := RepositoryTransaction(db)
+	created := seedBooking(t, db)
+
+	transaction, err := repo.GetTransaction(ctx, created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if transaction.House.Name != "Monas" || transaction.User.Username != "tenant" {
+		t.Errorf("the house and tenant weren't preloaded: %+v", transaction)
+	}
+	kinds := []string{"rent", "cleaning_fee", "service_fee"}
+	if len(transaction.Items) != len(kinds) {
+		t.Fatalf("got %d items, want %d", len(transaction.Items), len(kinds))
+	}
+	sum := 0
+	for i, item := range transaction.Items {
+		if item.Kind != kinds[i] {
+			t.Errorf("item %d is %s, want %s", i, item.Kind, kinds[i])
+		}
+		sum += item.Amount
+	}
+	if sum != transaction.Total {
+		t.Errorf("the items add up to %d, want the total %d", sum, transaction.Total)
+	}
+
+	if err := repo.PurgeTransaction(ctx, created.ID); err != nil {
+		t.Fatal(err)
+	}
+	var items int64
+	db.Model(&models.TransactionItem{}).Where("transaction_id = ?", created.ID).Count(&items)
+	if items != 0 {
+		t.Errorf("%d items are left after purging the transaction", items)
+	}
+}
+
+func TestCreateReviewTwice(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	transaction := seedBooking(t, db)
+	repo := RepositoryReview(db)
+
+	review := models.Review{TransactionId: transaction.ID, HouseId: transaction.HouseId, UserId: transaction.UserId, Rating: 4}
+	if _, err := repo.CreateReview(ctx, review); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.CreateReview(ctx, review); !errors.Is(err, ErrReviewExists) {
+		t.Fatalf("got %v, want ErrReviewExists", err)
+	}
+}
+
+func TestRedeemPromoCodeLimits(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	transaction := seedBooking(t, db)
+	repo := RepositoryPromo(db)
+
+	promo, err := repo.CreatePromoCode(ctx, models.PromoCode{Code: "ONCE", Kind: models.PromoFixed, Amount: 10, MaxUses: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	redemption := models.PromoRedemption{UserId: transaction.UserId, TransactionId: transaction.ID, Discount: 10}
+	if err := repo.RedeemPromoCode(ctx, promo, redemption); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.RedeemPromoCode(ctx, promo, redemption); !errors.Is(err, ErrPromoUsedUp) {
+		t.Fatalf("got %v, want ErrPromoUsedUp", err)
+	}
+
+	if err := repo.ReleasePromoRedemption(ctx, transaction.ID); err != nil {
+		t.Fatal(err)
+	}
+	promo, err = repo.GetPromoCode(ctx, promo.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if promo.Uses != 0 {
+		t.Fatalf("the promo code has %d uses after the release, want 0", promo.Uses)
+	}
+	if err := repo.RedeemPromoCode(ctx, promo, redemption); err != nil {
+		t.Fatalf("redeeming the released use: %v", err)
+	}
+}
+
+func TestWithTxRollsBack(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	failure := errors.New("failure")
+
+	err := NewUnitOfWork(db).WithTx(ctx, func(tx Repositories) error {
+		if _, err := tx.Users.CreateUser(ctx, models.User{Username: "ghost"}); err != nil {
+			return err
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("got %v, want the error of fn", err)
+	}
+
+	var users int64
+	db.Model(&models.User{}).Where("username = ?", "ghost").Count(&users)
+	if users != 0 {
+		t.Fatal("the user created in the rolled back transaction was kept")
+	}
+}
+
+func TestPurgeKeepsInvoicedTransactions(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	transaction := seedBooking(t, db)
+
+	invoice, err := RepositoryInvoice(db).CreateInvoice(ctx, models.Invoice{TransactionId: transaction.ID, PaymentMethod: "bank_transfer", IssuedAt: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RepositoryTransaction(db).PurgeTransaction(ctx, transaction.ID); !errors.Is(err, ErrHasInvoice) {
+		t.Fatalf("got %v, want ErrHasInvoice", err)
+	}
+	if _, err := RepositoryInvoice(db).GetInvoiceByTransaction(ctx, transaction.ID); err != nil {
+		t.Fatalf("invoice %s is gone: %v", invoice.Number, err)
+	}
+}
@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"housy/models"
+	"math"
+
+	"gorm.io/gorm"
+)
+
+// ErrReviewExists is returned when the stay already has a review.
+var ErrReviewExists = errors.New("the stay was already reviewed")
+
+type ReviewRepository interface {
+	FindHouseReviews(ctx context.Context, houseId int, page Page) ([]models.Review, error)
+	CountHouseReviews(ctx context.Context, houseId int) (int64, error)
+	FindHiddenReviews(ctx context.Context, page Page) ([]models.Review, error)
+	CountHiddenReviews(ctx context.Context) (int64, error)
+	GetReview(ctx context.Context, ID int) (models.Review, error)
+	HasReview(ctx context.Context, transactionId int) (bool, error)
+	CreateReview(ctx context.Context, review models.Review) (models.Review, error)
+	UpdateReview(ctx context.Context, review models.Review) (models.Review, error)
+	UpdateHouseRating(ctx context.Context, houseId int) error
+}
+
+type reviewRepository struct {
+	repository
+}
+
+func RepositoryReview(db *gorm.DB) *reviewRepository {
+	return &reviewRepository{repository{db}}
+}
+
+// FindHouseReviews returns the visible reviews of the house, newest first.
+func (r *reviewRepository) FindHouseReviews(ctx context.Context, houseId int, page Page) ([]models.Review, error) {
+	var reviews []models.Review
+	err := r.conn(ctx).Preload("User", unscoped).Where("house_id = ? AND hidden = ?", houseId, false).
+		Scopes(page.scope).Order("id DESC").Find(&reviews).Error
+
+	return reviews, err
+}
+
+func (r *reviewRepository) CountHouseReviews(ctx context.Context, houseId int) (int64, error) {
+	var count int64
+	err := r.conn(ctx).Model(&models.Review{}).Where("house_id = ? AND hidden = ?", houseId, false).Count(&count).Error
+
+	return count, err
+}
+
+// FindHiddenReviews returns the reviews hidden by moderators, most recently
+// hidden first.
+func (r *reviewRepository) FindHiddenReviews(ctx context.Context, page Page) ([]models.Review, error) {
+	var reviews []models.Review
+	err := r.conn(ctx).Preload("User", unscoped).Where("hidden = ?", true).
+		Scopes(page.scope).Order("updated_at DESC, id DESC").Find(&reviews).Error
+
+	return reviews, err
+}
+
+func (r *reviewRepository) CountHiddenReviews(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.conn(ctx).Model(&models.Review{}).Where("hidden = ?", true).Count(&count).Error
+
+	return count, err
+}
+
+func (r *reviewRepository) GetReview(ctx context.Context, ID int) (models.Review, error) {
+	var review models.Review
+	err := r.conn(ctx).Preload("User", unscoped).First(&review, ID).Error
+
+	return review, err
+}
+
+// HasReview reports whether the stay was already reviewed.
+func (r *reviewRepository) HasReview(ctx context.Context, transactionId int) (bool, error) {
+	var count int64
+	err := r.conn(ctx).Model(&models.Review{}).Where("transaction_id = ?", transactionId).Count(&count).Error
+
+	return count > 0, err
+}
+
+// CreateReview returns ErrReviewExists when another review of the stay was
+// created since HasReview was checked.
+func (r *reviewRepository) CreateReview(ctx context.Context, review models.Review) (models.Review, error) {
+	err := r.conn(ctx).Omit("User").Create(&review).Error
+	if r.isDuplicateKey(err) {
+		return review, ErrReviewExists
+	}
+
+	return review, err
+}
+
+func (r *reviewRepository) UpdateReview(ctx context.Context, review models.Review) (models.Review, error) {
+	err := r.conn(ctx).Omit("User").Save(&review).Error
+
+	return review, err
+}
+
+// UpdateHouseRating recomputes the rating and review count of the house from
+// its visible reviews. Call it through a unit of work, in the transaction
+// that changed the reviews.
+func (r *reviewRepository) UpdateHouseRating(ctx context.Context, houseId int) error {
+	var aggregate struct {
+		Rating float64
+		Count  int
+	}
+	err := r.conn(ctx).Model(&models.Review{}).Select("COALESCE(AVG(rating), 0) AS rating, COUNT(*) AS count").
+		Where("house_id = ? AND hidden = ?", houseId, false).Scan(&aggregate).Error
+	if err != nil {
+		return err
+	}
+
+	return r.conn(ctx).Unscoped().Model(&models.House{}).Where("id = ?", houseId).
+		UpdateColumns(map[string]interface{}{"rating": math.Round(aggregate.Rating*100) / 100, "review_count": aggregate.Count}).Error
+}
@@ -1,63 +1,174 @@
 package repositories
 
 import (
+	"context"
+	"errors"
 	"housy/models"
 
 	"gorm.io/gorm"
 )
 
+// ErrHasInvoice is returned when purging a paid transaction. Its invoice is
+// kept, so the invoice numbers have no gaps.
+var ErrHasInvoice = errors.New("transaction has an invoice and can't be purged")
+
+// TransactionFilter holds the optional filters of the transaction list and
+// exports. From and To are compared against the check in date. OwnerId keeps
+// the bookings of the houses of that owner.
+type TransactionFilter struct {
+	StatusPayment string
+	HouseId       int
+	UserId        int
+	OwnerId       int
+	From          string
+	To            string
+}
+
 type TransactionRepository interface {
-	FindTransaction() ([]models.Transaction, error)
-	GetTransaction(ID int) (models.Transaction, error)
-	GetOneTransaction(ID string) (models.Transaction, error)
-	CreateTransaction(Transaction models.Transaction) (models.Transaction, error)
-	UpdateTransaction(status string, ID string) error
-	DeleteTransaction(Transaction models.Transaction) (models.Transaction, error)
+	FindTransaction(ctx context.Context, filter TransactionFilter, page Page) ([]models.Transaction, error)
+	CountTransactions(ctx context.Context, filter TransactionFilter) (int64, error)
+	EachTransaction(ctx context.Context, filter TransactionFilter, fn func(transaction models.Transaction) error) error
+	GetTransaction(ctx context.Context, ID int) (models.Transaction, error)
+	GetOneTransaction(ctx context.Context, ID string) (models.Transaction, error)
+	CreateTransaction(ctx context.Context, Transaction models.Transaction) (models.Transaction, error)
+	UpdateTransaction(ctx context.Context, status string, ID string) error
+	DeleteTransaction(ctx context.Context, Transaction models.Transaction) (models.Transaction, error)
+	RestoreTransaction(ctx context.Context, ID int) (models.Transaction, error)
+	PurgeTransaction(ctx context.Context, ID int) error
 }
 
-func RepositoryTransaction(db *gorm.DB) *repository {
-	return &repository{db}
+type transactionRepository struct {
+	repository
 }
 
-func (r *repository) FindTransaction() ([]models.Transaction, error) {
+func RepositoryTransaction(db *gorm.DB) *transactionRepository {
+	return &transactionRepository{repository{db}}
+}
+
+func (f TransactionFilter) scope(db *gorm.DB) *gorm.DB {
+	if f.StatusPayment != "" {
+		db = db.Where("status_payment = ?", f.StatusPayment)
+	}
+	if f.HouseId != 0 {
+		db = db.Where("house_id = ?", f.HouseId)
+	}
+	if f.UserId != 0 {
+		db = db.Where("user_id = ?", f.UserId)
+	}
+	if f.OwnerId != 0 {
+		db = db.Where("house_id IN (SELECT id FROM houses WHERE owner_id = ?)", f.OwnerId)
+	}
+	if f.From != "" {
+		db = db.Where("check_in >= ?", f.From)
+	}
+	if f.To != "" {
+		db = db.Where("check_in <= ?", f.To)
+	}
+	return db
+}
+
+func (r *transactionRepository) FindTransaction(ctx context.Context, filter TransactionFilter, page Page) ([]models.Transaction, error) {
 	var transaction []models.Transaction
-	err := r.db.Preload("House").Preload("User").Find(&transaction).Error
+	err := r.conn(ctx).Preload("House", unscoped).Preload("User", unscoped).Preload("Items", inOrder).Scopes(filter.scope, page.scope).Order("id").Find(&transaction).Error
 
 	return transaction, err
 }
 
-func (r *repository) GetTransaction(ID int) (models.Transaction, error) {
+func (r *transactionRepository) CountTransactions(ctx context.Context, filter TransactionFilter) (int64, error) {
+	var count int64
+	err := r.conn(ctx).Model(&models.Transaction{}).Scopes(filter.scope).Count(&count).Error
+
+	return count, err
+}
+
+// EachTransaction calls fn for every matching transaction in batches.
+func (r *transactionRepository) EachTransaction(ctx context.Context, filter TransactionFilter, fn func(transaction models.Transaction) error) error {
+	var transactions []models.Transaction
+	return r.conn(ctx).Preload("House", unscoped).Preload("User", unscoped).Scopes(filter.scope).FindInBatches(&transactions, 500, func(tx *gorm.DB, batch int) error {
+		for _, transaction := range transactions {
+			if err := fn(transaction); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
+
+func (r *transactionRepository) GetTransaction(ctx context.Context, ID int) (models.Transaction, error) {
 	var transaction models.Transaction
-	err := r.db.Preload("House").Preload("User").First(&transaction, ID).Error
+	err := r.conn(ctx).Preload("House", unscoped).Preload("User", unscoped).Preload("Items", inOrder).First(&transaction, ID).Error
 
 	return transaction, err
 }
 
-func (r *repository) GetOneTransaction(ID string) (models.Transaction, error) {
+func (r *transactionRepository) GetOneTransaction(ctx context.Context, ID string) (models.Transaction, error) {
 	var transaction models.Transaction
-	err := r.db.Preload("House").Preload("User").First(&transaction, "id = ?", ID).Error
+	err := r.conn(ctx).Preload("House", unscoped).Preload("User", unscoped).Preload("Items", inOrder).First(&transaction, "id = ?", ID).Error
 
 	return transaction, err
 }
 
-func (r *repository) CreateTransaction(transaction models.Transaction) (models.Transaction, error) {
-	err := r.db.Create(&transaction).Error // Using Create method
+func (r *transactionRepository) CreateTransaction(ctx context.Context, transaction models.Transaction) (models.Transaction, error) {
+	err := r.conn(ctx).Create(&transaction).Error // Using Create method
 
 	return transaction, err
 }
 
-func (r *repository) UpdateTransaction(status string, ID string) error {
-	var transaction models.Transaction
-	r.db.Preload("House").Preload("User").First(&transaction, ID) // 112233
+func (r *transactionRepository) UpdateTransaction(ctx context.Context, status string, ID string) error {
+	return r.conn(ctx).Model(&models.Transaction{}).Where("id = ?", ID).Update("status_payment", status).Error
+}
 
-	transaction.StatusPayment = status
-	err := r.db.Save(&transaction).Error
+func (r *transactionRepository) DeleteTransaction(ctx context.Context, transaction models.Transaction) (models.Transaction, error) {
+	err := r.conn(ctx).Delete(&transaction).Error
 
-	return err
+	return transaction, err
 }
 
-func (r *repository) DeleteTransaction(transaction models.Transaction) (models.Transaction, error) {
-	err := r.db.Delete(&transaction).Error
+func (r *transactionRepository) RestoreTransaction(ctx context.Context, ID int) (models.Transaction, error) {
+	var transaction models.Transaction
+	err := r.conn(ctx).Unscoped().Model(&transaction).Where("id = ?", ID).Update("deleted_at", nil).Error
+	if err != nil {
+		return transaction, err
+	}
 
-	return transaction, err
+	return r.GetTransaction(ctx, ID)
+}
+
+// PurgeTransaction permanently deletes a transaction with its line items and
+// review. The rating of the house is recomputed without the review, and the
+// use of its promo code is given back. Invoiced transactions return
+// ErrHasInvoice.
+func (r *transactionRepository) PurgeTransaction(ctx context.Context, ID int) error {
+	var count int64
+	if err := r.conn(ctx).Model(&models.Invoice{}).Where("transaction_id = ?", ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrHasInvoice
+	}
+
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("transaction_id = ?", ID).Delete(&models.TransactionItem{}).Error; err != nil {
+			return err
+		}
+
+		var review models.Review
+		if err := tx.Where("transaction_id = ?", ID).Limit(1).Find(&review).Error; err != nil {
+			return err
+		}
+		if review.ID != 0 {
+			if err := tx.Delete(&review).Error; err != nil {
+				return err
+			}
+			if err := RepositoryReview(tx).UpdateHouseRating(ctx, review.HouseId); err != nil {
+				return err
+			}
+		}
+
+		if err := RepositoryPromo(tx).ReleasePromoRedemption(ctx, ID); err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Delete(&models.Transaction{}, ID).Error
+	})
 }
@@ -1,51 +1,91 @@
 package repositories
 
 import (
+	"context"
 	"housy/models"
 
 	"gorm.io/gorm"
 )
 
 type UserRepository interface {
-	FindUsers() ([]models.User, error)
-	GetUser(ID int) (models.User, error)
-	CreateUser(user models.User) (models.User, error)
-	UpdateUser(user models.User, ID int) (models.User, error)
-	DeleteUser(user models.User, ID int) (models.User, error)
+	FindUsers(ctx context.Context) ([]models.User, error)
+	GetUser(ctx context.Context, ID int) (models.User, error)
+	CreateUser(ctx context.Context, user models.User) (models.User, error)
+	UpdateUser(ctx context.Context, user models.User, ID int) (models.User, error)
+	DeleteUser(ctx context.Context, user models.User, ID int) (models.User, error)
+	RestoreUser(ctx context.Context, ID int) (models.User, error)
+	PurgeUser(ctx context.Context, ID int) error
 }
 
-func RepositoryUser(db *gorm.DB) *repository {
-	return &repository{db}
+type userRepository struct {
+	repository
 }
 
-func (r *repository) FindUsers() ([]models.User, error) {
+func RepositoryUser(db *gorm.DB) *userRepository {
+	return &userRepository{repository{db}}
+}
+
+func (r *userRepository) FindUsers(ctx context.Context) ([]models.User, error) {
 	var users []models.User
-	err := r.db.Find(&users).Error
+	err := r.conn(ctx).Find(&users).Error
 
 	return users, err
 }
 
-func (r *repository) GetUser(ID int) (models.User, error) {
+func (r *userRepository) GetUser(ctx context.Context, ID int) (models.User, error) {
 	var user models.User
-	err := r.db.First(&user, ID).Error
+	err := r.conn(ctx).First(&user, ID).Error
 
 	return user, err
 }
-func (r *repository) CreateUser(user models.User) (models.User, error) {
-	err := r.db.Create(&user).Error
+func (r *userRepository) CreateUser(ctx context.Context, user models.User) (models.User, error) {
+	err := r.conn(ctx).Create(&user).Error
 
 	return user, err
 }
 
-//Update
-func (r *repository) UpdateUser(user models.User, ID int) (models.User, error) {
-	err := r.db.Save(&user).Error
+// Update
+func (r *userRepository) UpdateUser(ctx context.Context, user models.User, ID int) (models.User, error) {
+	err := r.conn(ctx).Save(&user).Error
 
 	return user, err
 }
 
-func (r *repository) DeleteUser(user models.User, ID int) (models.User, error) {
-	err := r.db.Delete(&user).Error
+func (r *userRepository) DeleteUser(ctx context.Context, user models.User, ID int) (models.User, error) {
+	err := r.conn(ctx).Delete(&user).Error
 
 	return user, err
-}
\ No newline at end of file
+}
+func (r *userRepository) RestoreUser(ctx context.Context, ID int) (models.User, error) {
+	var user models.User
+	err := r.conn(ctx).Unscoped().Model(&user).Where("id = ?", ID).Update("deleted_at", nil).Error
+	if err != nil {
+		return user, err
+	}
+
+	return r.GetUser(ctx, ID)
+}
+
+// PurgeUser permanently deletes a user. Deleting a user cascades to their
+// transactions, so users with any booking history are refused. Their
+// wishlists are deleted with them.
+func (r *userRepository) PurgeUser(ctx context.Context, ID int) error {
+	var count int64
+	if err := r.conn(ctx).Unscoped().Model(&models.Transaction{}).Where("user_id = ?", ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrHasTransactions
+	}
+
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		wishlists := tx.Model(&models.Wishlist{}).Select("id").Where("user_id = ?", ID)
+		if err := tx.Where("wishlist_id IN (?)", wishlists).Delete(&models.WishlistHouse{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", ID).Delete(&models.Wishlist{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&models.User{}, ID).Error
+	})
+}
@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"context"
+	"housy/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WishlistRepository interface {
+	FindWishlists(ctx context.Context, userId int) ([]models.Wishlist, error)
+	GetWishlist(ctx context.Context, ID int) (models.Wishlist, error)
+	CreateWishlist(ctx context.Context, wishlist models.Wishlist) (models.Wishlist, error)
+	UpdateWishlist(ctx context.Context, wishlist models.Wishlist) (models.Wishlist, error)
+	DeleteWishlist(ctx context.Context, ID int) error
+	AddWishlistHouse(ctx context.Context, wishlistId int, houseId int) error
+	RemoveWishlistHouse(ctx context.Context, wishlistId int, houseId int) error
+	FavoritedHouses(ctx context.Context, userId int, houseIds []int) (map[int]bool, error)
+	CountFavorites(ctx context.Context, houseIds []int) (map[int]int, error)
+}
+
+type wishlistRepository struct {
+	repository
+}
+
+func RepositoryWishlist(db *gorm.DB) *wishlistRepository {
+	return &wishlistRepository{repository{db}}
+}
+
+// FindWishlists returns the wishlists of the user with their houses. Deleted
+// houses are left out.
+func (r *wishlistRepository) FindWishlists(ctx context.Context, userId int) ([]models.Wishlist, error) {
+	var wishlists []models.Wishlist
+	err := r.conn(ctx).Preload("Houses").Preload("Houses.Amenities", orderAmenities).Where("user_id = ?", userId).Order("id").Find(&wishlists).Error
+
+	return wishlists, err
+}
+
+func (r *wishlistRepository) GetWishlist(ctx context.Context, ID int) (models.Wishlist, error) {
+	var wishlist models.Wishlist
+	err := r.conn(ctx).Preload("Houses").Preload("Houses.Amenities", orderAmenities).First(&wishlist, ID).Error
+
+	return wishlist, err
+}
+
+func (r *wishlistRepository) CreateWishlist(ctx context.Context, wishlist models.Wishlist) (models.Wishlist, error) {
+	err := r.conn(ctx).Omit("Houses").Create(&wishlist).Error
+
+	return wishlist, err
+}
+
+func (r *wishlistRepository) UpdateWishlist(ctx context.Context, wishlist models.Wishlist) (models.Wishlist, error) {
+	err := r.conn(ctx).Omit("Houses").Save(&wishlist).Error
+
+	return wishlist, err
+}
+
+// DeleteWishlist deletes the wishlist and the houses in it.
+func (r *wishlistRepository) DeleteWishlist(ctx context.Context, ID int) error {
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("wishlist_id = ?", ID).Delete(&models.WishlistHouse{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Wishlist{}, ID).Error
+	})
+}
+
+// AddWishlistHouse adds the house to the wishlist, adding it twice is a no-op.
+func (r *wishlistRepository) AddWishlistHouse(ctx context.Context, wishlistId int, houseId int) error {
+	return r.conn(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.WishlistHouse{WishlistId: wishlistId, HouseId: houseId}).Error
+}
+
+func (r *wishlistRepository) RemoveWishlistHouse(ctx context.Context, wishlistId int, houseId int) error {
+	return r.conn(ctx).Where("wishlist_id = ? AND house_id = ?", wishlistId, houseId).Delete(&models.WishlistHouse{}).Error
+}
+
+// FavoritedHouses reports which of the houses are in a wishlist of the user.
+func (r *wishlistRepository) FavoritedHouses(ctx context.Context, userId int, houseIds []int) (map[int]bool, error) {
+	var ids []int
+	err := r.conn(ctx).Model(&models.WishlistHouse{}).
+		Joins("JOIN wishlists ON wishlists.id = wishlist_houses.wishlist_id").
+		Where("wishlists.user_id = ? AND wishlist_houses.house_id IN ?", userId, houseIds).
+		Distinct().Pluck("wishlist_houses.house_id", &ids).Error
+
+	favorited := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		favorited[id] = true
+	}
+	return favorited, err
+}
+
+// CountFavorites counts the users having each house in one of their
+// wishlists. Houses nobody favorited are missing from the map.
+func (r *wishlistRepository) CountFavorites(ctx context.Context, houseIds []int) (map[int]int, error) {
+	var rows []struct {
+		HouseId int
+		Count   int
+	}
+	err := r.conn(ctx).Model(&models.WishlistHouse{}).
+		Select("wishlist_houses.house_id AS house_id, COUNT(DISTINCT wishlists.user_id) AS count").
+		Joins("JOIN wishlists ON wishlists.id = wishlist_houses.wishlist_id").
+		Where("wishlist_houses.house_id IN ?", houseIds).
+		Group("wishlist_houses.house_id").Scan(&rows).Error
+
+	counts := make(map[int]int, len(rows))
+	for _, row := range rows {
+		counts[row.HouseId] = row.Count
+	}
+	return counts, err
+}
@@ -0,0 +1,97 @@
+package routes_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"housy/client"
+	housesdto "housy/dto/house"
+	transactiondto "housy/dto/transaction"
+	"housy/routes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOnlyOwnersAndTenantsReachTheirRecords(t *testing.T) {
+	a := newTestApp(t)
+	server := httptest.NewServer(routes.Handler(a))
+	defer server.Close()
+	ctx := context.Background()
+
+	owner := signUp(t, server.URL, "owner", "owner")
+	rival := signUp(t, server.URL, "rival", "owner")
+	tenant := signUp(t, server.URL, "tenant", "tenant")
+	stranger := signUp(t, server.URL, "stranger", "tenant")
+	anonymous := client.New(server.URL)
+
+	house, err := owner.CreateHouse(ctx, housesdto.HouseRequest{
+		Name:      "Monas",
+		CityName:  "Jakarta",
+		Address:   "Jl. Medan Merdeka",
+		Price:     100,
+		TypeRent:  "day",
+		Amenities: []string{"wifi"},
+		Bedroom:   1,
+		Bathroom:  1,
+	}, client.Image{Name: "monas.png", Content: bytes.NewReader([]byte("\x89PNG\r\n\x1a\n"))})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := tenant.CreateTransaction(ctx, transactiondto.RequestTransaction{CheckIn: "2027-03-01", CheckOut: "2027-03-03", HouseId: house.ID}); err != nil {
+		t.Fatal(err)
+	}
+	var id int
+	fmt.Sscan(a.Payment.(*fakePayment).requests[0].TransactionDetails.OrderID, &id)
+
+	wantStatus := func(what string, err error, status int) {
+		t.Helper()
+		var apiErr *client.Error
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != status {
+			t.Errorf("%s: got %v, want a %d", what, err, status)
+		}
+	}
+
+	_, err = anonymous.DeleteHouse(ctx, house.ID)
+	wantStatus("deleting a house anonymously", err, http.StatusUnauthorized)
+	_, err = rival.DeleteHouse(ctx, house.ID)
+	wantStatus("deleting the house of another owner", err, http.StatusForbidden)
+
+	_, err = anonymous.GetTransaction(ctx, id)
+	wantStatus("reading a booking anonymously", err, http.StatusUnauthorized)
+	_, err = stranger.GetTransaction(ctx, id)
+	wantStatus("reading the booking of another tenant", err, http.StatusForbidden)
+	_, err = rival.GetTransaction(ctx, id)
+	wantStatus("reading a booking of another owner", err, http.StatusForbidden)
+	if _, err := owner.GetTransaction(ctx, id); err != nil {
+		t.Errorf("the owner reading a booking of their house: %v", err)
+	}
+
+	for name, c := range map[string]*client.Client{"tenant": tenant, "owner": owner, "stranger": stranger, "rival": rival} {
+		transactions, err := c.FindTransactions(ctx, client.TransactionFilter{}).All()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := 0
+		if name == "tenant" || name == "owner" {
+			want = 1
+		}
+		if len(transactions) != want {
+			t.Errorf("the %s lists %d transactions, want %d", name, len(transactions), want)
+		}
+	}
+
+	_, err = stranger.DeleteTransaction(ctx, id)
+	wantStatus("deleting the booking of another tenant", err, http.StatusForbidden)
+	_, err = owner.DeleteTransaction(ctx, id)
+	wantStatus("the owner deleting a booking", err, http.StatusForbidden)
+	if _, err := tenant.DeleteTransaction(ctx, id); err != nil {
+		t.Errorf("the tenant deleting their booking: %v", err)
+	}
+
+	if _, err := owner.DeleteHouse(ctx, house.ID); err != nil {
+		t.Errorf("the owner deleting their house: %v", err)
+	}
+}
@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"housy/app"
+	"housy/handlers"
+	"housy/pkg/middleware"
+	"housy/repositories"
+
+	"github.com/gorilla/mux"
+)
+
+func AdminRoutes(r *mux.Router, a *app.App) {
+	houseRepository := repositories.RepositoryHouse(a.DB)
+	userRepository := repositories.RepositoryUser(a.DB)
+	transactionRepository := repositories.RepositoryTransaction(a.DB)
+	h := handlers.HandlerAdmin(houseRepository, userRepository, transactionRepository, a.Search)
+
+	r.HandleFunc("/admin/house/{id}/restore", middleware.Auth(h.RestoreHouse, a.Config.SecretKey)).Methods("POST")
+	r.HandleFunc("/admin/house/{id}/purge", middleware.Auth(h.PurgeHouse, a.Config.SecretKey)).Methods("DELETE")
+	r.HandleFunc("/admin/user/{id}/restore", middleware.Auth(h.RestoreUser, a.Config.SecretKey)).Methods("POST")
+	r.HandleFunc("/admin/user/{id}/purge", middleware.Auth(h.PurgeUser, a.Config.SecretKey)).Methods("DELETE")
+	r.HandleFunc("/admin/transaction/{id}/restore", middleware.Auth(h.RestoreTransaction, a.Config.SecretKey)).Methods("POST")
+	r.HandleFunc("/admin/transaction/{id}/purge", middleware.Auth(h.PurgeTransaction, a.Config.SecretKey)).Methods("DELETE")
+}
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"housy/app"
+	"housy/handlers"
+	"housy/pkg/middleware"
+	"housy/repositories"
+
+	"github.com/gorilla/mux"
+)
+
+func AmenityRoutes(r *mux.Router, a *app.App) {
+	amenityRepository := repositories.RepositoryAmenity(a.DB)
+	userRepository := repositories.RepositoryUser(a.DB)
+	h := handlers.HandlerAmenity(amenityRepository, userRepository)
+
+	r.HandleFunc("/amenities", h.FindAmenities).Methods("GET")
+	r.HandleFunc("/admin/amenity", middleware.Auth(h.CreateAmenity, a.Config.SecretKey)).Methods("POST")
+	r.HandleFunc("/admin/amenity/{id}", middleware.Auth(h.UpdateAmenity, a.Config.SecretKey)).Methods("PATCH")
+	r.HandleFunc("/admin/amenity/{id}", middleware.Auth(h.DeleteAmenity, a.Config.SecretKey)).Methods("DELETE")
+}
@@ -0,0 +1,302 @@
+package routes_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"housy/app"
+	"housy/client"
+	"housy/config"
+	migrate "housy/database"
+	authdto "housy/dto/auth"
+	housesdto "housy/dto/house"
+	transactiondto "housy/dto/transaction"
+	"housy/pkg/database"
+	"housy/pkg/mail"
+	"housy/pkg/search"
+	"housy/routes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/midtrans/midtrans-go/snap"
+)
+
+type fakeMailer struct {
+	mu   sync.Mutex
+	sent []mail.Message
+}
+
+func (m *fakeMailer) Send(ctx context.Context, message mail.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sent = append(m.sent, message)
+	return nil
+}
+
+func (m *fakeMailer) count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.sent)
+}
+
+type fakePayment struct {
+	mu       sync.Mutex
+	requests []*snap.Request
+}
+
+func (p *fakePayment) CreateTransaction(ctx context.Context, request *snap.Request) (*snap.Response, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.requests = append(p.requests, request)
+	return &snap.Response{Token: "snap-token", RedirectURL: "https://pay.example/" + request.TransactionDetails.OrderID}, nil
+}
+
+type fakeStorage struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+func (s *fakeStorage) Save(r io.Reader, pattern string) (string, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name := strings.Replace(pattern, "*", fmt.Sprint(len(s.files)+1), 1)
+	s.files[name] = content
+	return name, nil
+}
+
+func (s *fakeStorage) Remove(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.files, name)
+	return nil
+}
+
+func (s *fakeStorage) URL(name string) string {
+	return "/uploads/" + name
+}
+
+// newTestApp returns an app over a migrated in-memory database, with fakes
+// for the mails, the payments and the uploads.
+func newTestApp(t *testing.T) *app.App {
+	t.Helper()
+
+	cfg := config.Default()
+	cfg.SecretKey = "test-secret"
+	cfg.Payment.ServerKey = "test-server-key"
+	cfg.Fees.ServicePercent = 5
+	cfg.Fees.TaxPercent = 11
+
+	db, err := database.Open(database.Config{Driver: database.SQLite, Name: ":memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrate.Up(db); err != nil {
+		t.Fatal(err)
+	}
+	index, err := search.OpenMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := &app.App{
+		Config:  cfg,
+		DB:      db,
+		Mailer:  &fakeMailer{},
+		Payment: &fakePayment{},
+		Storage: &fakeStorage{files: map[string][]byte{}},
+		Search:  index,
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		a.StopWorkers(ctx)
+		a.Close()
+	})
+	return a
+}
+
+func signUp(t *testing.T, url, username, role string) *client.Client {
+	t.Helper()
+
+	_, err := client.New(url).SignUp(context.Background(), authdto.SignUpRequest{
+		Fullname:   username,
+		Username:   username,
+		Email:      username + "@example.com",
+		Password:   "password",
+		ListAsRole: role,
+		Phone:      "0812",
+		Address:    "Jakarta",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client.New(url, client.WithCredentials(username, "password", role))
+}
+
+// notify posts a Midtrans notification for the order, signed with key, and
+// returns the status of the response.
+func notify(t *testing.T, url, key, orderId, transactionStatus string, grossAmount int) int {
+	t.Helper()
+
+	gross := fmt.Sprintf("%d.00", grossAmount)
+	sum := sha512.Sum512([]byte(orderId + "200" + gross + key))
+	notification, _ := json.Marshal(map[string]string{
+		"transaction_status": transactionStatus,
+		"order_id":           orderId,
+		"payment_type":       "bank_transfer",
+		"status_code":        "200",
+		"gross_amount":       gross,
+		"signature_key":      hex.EncodeToString(sum[:]),
+	})
+	resp, err := http.Post(url+"/api/v1/notification", "application/json", bytes.NewReader(notification))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestBookingWithFakes(t *testing.T) {
+	a := newTestApp(t)
+	server := httptest.NewServer(routes.Handler(a))
+	defer server.Close()
+	ctx := context.Background()
+
+	owner := signUp(t, server.URL, "owner", "owner")
+	tenant := signUp(t, server.URL, "tenant", "tenant")
+
+	cleaningFee := 50
+	house, err := owner.CreateHouse(ctx, housesdto.HouseRequest{
+		Name:        "Monas",
+		CityName:    "Jakarta",
+		Address:     "Jl. Medan Merdeka",
+		Price:       100,
+		CleaningFee: &cleaningFee,
+		TypeRent:    "day",
+		Amenities:   []string{"wifi"},
+		Bedroom:     1,
+		Bathroom:    1,
+	}, client.Image{Name: "monas.png", Content: bytes.NewReader([]byte("\x89PNG\r\n\x1a\n"))})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	quote, err := tenant.Quote(ctx, house.ID, "2027-03-01", "2027-03-03", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2 nights and the cleaning fee, 250, with 13 of service fee and 29 of
+	// tax
+	if quote.Total != 292 {
+		t.Fatalf("quote total = %d, want 292", quote.Total)
+	}
+
+	// bookings are made by the signed in user only
+	anonymous := client.New(server.URL)
+	_, err = anonymous.CreateTransaction(ctx, transactiondto.RequestTransaction{CheckIn: "2027-03-01", CheckOut: "2027-03-03", HouseId: house.ID})
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("anonymous booking: got %v, want a 401", err)
+	}
+
+	payment, err := tenant.CreateTransaction(ctx, transactiondto.RequestTransaction{
+		CheckIn:  "2027-03-01",
+		CheckOut: "2027-03-03",
+		HouseId:  house.ID,
+		Total:    quote.Total,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payment.Token != "snap-token" {
+		t.Fatalf("payment token = %q, want the one of the fake gateway", payment.Token)
+	}
+
+	requests := a.Payment.(*fakePayment).requests
+	if len(requests) != 1 {
+		t.Fatalf("the gateway got %d payments, want 1", len(requests))
+	}
+	request := requests[0]
+	var items int64
+	for _, item := range *request.Items {
+		items += item.Price * int64(item.Qty)
+	}
+	if request.TransactionDetails.GrossAmt != int64(quote.Total) || items != request.TransactionDetails.GrossAmt {
+		t.Fatalf("gross amount %d and items %d, want both %d", request.TransactionDetails.GrossAmt, items, quote.Total)
+	}
+
+	var id int
+	fmt.Sscan(request.TransactionDetails.OrderID, &id)
+
+	// only the gateway marks a booking as paid
+	booked, err := tenant.GetTransaction(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if booked.StatusPayment != "pending" {
+		t.Fatalf("a new booking is %q, want pending", booked.StatusPayment)
+	}
+
+	orderId := request.TransactionDetails.OrderID
+	if status := notify(t, server.URL, "forged-key", orderId, "settlement", quote.Total); status != http.StatusForbidden {
+		t.Fatalf("a notification with a forged signature answered %d, want 403", status)
+	}
+	if status := notify(t, server.URL, "test-server-key", orderId, "settlement", quote.Total); status != http.StatusOK {
+		t.Fatalf("notification answered %d", status)
+	}
+
+	// a late expiry doesn't take the payment back
+	if status := notify(t, server.URL, "test-server-key", orderId, "expire", quote.Total); status != http.StatusOK {
+		t.Fatalf("the late notification answered %d", status)
+	}
+	paid, err := tenant.GetTransaction(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if paid.StatusPayment != "success" {
+		t.Fatalf("the paid booking is %q after a late expiry, want success", paid.StatusPayment)
+	}
+
+	invoice, err := tenant.GetInvoice(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(invoice, []byte("%PDF")) {
+		t.Fatal("the invoice isn't a PDF")
+	}
+
+	// a second booking in the same second gets its own order id
+	if _, err := tenant.CreateTransaction(ctx, transactiondto.RequestTransaction{CheckIn: "2027-04-01", CheckOut: "2027-04-03", HouseId: house.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if second := a.Payment.(*fakePayment).requests[1].TransactionDetails.OrderID; second == orderId {
+		t.Fatalf("both bookings have the order id %s", orderId)
+	}
+
+	mailer := a.Mailer.(*fakeMailer)
+	for deadline := time.Now().Add(5 * time.Second); mailer.count() == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("the payment mail wasn't sent")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
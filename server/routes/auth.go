@@ -1,19 +1,19 @@
 package routes
 
 import (
+	"housy/app"
 	"housy/handlers"
 	"housy/pkg/middleware"
-	"housy/pkg/mysql"
 	"housy/repositories"
 
 	"github.com/gorilla/mux"
 )
 
-func AuthRoutes(r *mux.Router) {
-	userRepository := repositories.RepositoryUser(mysql.DB)
-	h := handlers.HandlerAuth(userRepository)
+func AuthRoutes(r *mux.Router, a *app.App) {
+	authRepository := repositories.RepositoryAuth(a.DB)
+	h := handlers.HandlerAuth(authRepository, a.Config.SecretKey)
 
 	r.HandleFunc("/sign-up", h.SignUp).Methods("POST")
 	r.HandleFunc("/sign-in", h.SignIn).Methods("POST")
-	r.HandleFunc("/check-auth", middleware.Auth(h.CheckAuth)).Methods("GET")
+	r.HandleFunc("/check-auth", middleware.Auth(h.CheckAuth, a.Config.SecretKey)).Methods("GET")
 }
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"housy/app"
+	"housy/handlers"
+	"housy/openapi"
+
+	"github.com/gorilla/mux"
+)
+
+func DocsRoutes(r *mux.Router, a *app.App) {
+	h := handlers.HandlerDocs(openapi.Spec())
+
+	r.HandleFunc("/openapi.json", h.OpenAPI).Methods("GET")
+	r.HandleFunc("/docs", h.Docs).Methods("GET")
+}
@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"housy/app"
+	"housy/handlers"
+	"housy/pkg/metrics"
+	"housy/pkg/middleware"
+	"housy/repositories"
+
+	"github.com/gorilla/mux"
+)
+
+func ExportRoutes(r *mux.Router, a *app.App) {
+	houseRepository := repositories.RepositoryHouse(a.DB)
+	transactionRepository := repositories.RepositoryTransaction(a.DB)
+	exportJobRepository := repositories.RepositoryExportJob(a.DB)
+	userRepository := repositories.RepositoryUser(a.DB)
+	h := handlers.HandlerExport(houseRepository, transactionRepository, exportJobRepository, userRepository)
+	a.Go(h.RunJobs)
+	metrics.RegisterQueue("exports", h.QueueDepth)
+
+	r.HandleFunc("/houses/export", middleware.Auth(h.ExportHouses, a.Config.SecretKey)).Methods("GET")
+	r.HandleFunc("/transactions/export", middleware.Auth(h.ExportTransactions, a.Config.SecretKey)).Methods("GET")
+	r.HandleFunc("/exports/{id}", middleware.Auth(h.GetExportJob, a.Config.SecretKey)).Methods("GET")
+	r.HandleFunc("/exports/{id}/download", middleware.Auth(h.DownloadExport, a.Config.SecretKey)).Methods("GET")
+}
@@ -0,0 +1,44 @@
+package routes
+
+import (
+	"context"
+	"housy/app"
+	"housy/handlers"
+	"housy/pkg/metrics"
+
+	"github.com/gorilla/mux"
+)
+
+// checker is implemented by dependencies able to tell whether they're usable.
+type checker interface {
+	Check() error
+}
+
+// HealthRoutes are served at the root, next to /api/v1, where probes and
+// Prometheus expect them.
+func HealthRoutes(r *mux.Router, a *app.App) {
+	checks := map[string]handlers.Check{
+		"database": func(ctx context.Context) error {
+			sqlDB, err := a.DB.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		},
+	}
+	if storage, ok := a.Storage.(checker); ok {
+		checks["storage"] = func(context.Context) error { return storage.Check() }
+	}
+	if mailer, ok := a.Mailer.(checker); ok {
+		checks["mailer"] = func(context.Context) error { return mailer.Check() }
+	}
+	h := handlers.HandlerHealth(checks)
+
+	if sqlDB, err := a.DB.DB(); err == nil {
+		metrics.RegisterDB(sqlDB, a.Config.Database.Driver)
+	}
+
+	r.HandleFunc("/healthz", h.Healthz).Methods("GET")
+	r.HandleFunc("/readyz", h.Readyz).Methods("GET")
+	r.Handle("/metrics", metrics.Handler()).Methods("GET")
+}
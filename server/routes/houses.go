@@ -1,21 +1,26 @@
 package routes
 
 import (
+	"housy/app"
 	"housy/handlers"
 	"housy/pkg/middleware"
-	"housy/pkg/mysql"
 	"housy/repositories"
 
 	"github.com/gorilla/mux"
 )
 
-func HouseRoutes(r *mux.Router) {
-	houseRepository := repositories.RepositoryHouse(mysql.DB)
-	h := handlers.HandlerHouse(houseRepository)
+func HouseRoutes(r *mux.Router, a *app.App) {
+	houseRepository := repositories.RepositoryHouse(a.DB)
+	wishlistRepository := repositories.RepositoryWishlist(a.DB)
+	amenityRepository := repositories.RepositoryAmenity(a.DB)
+	userRepository := repositories.RepositoryUser(a.DB)
+	h := handlers.HandlerHouse(houseRepository, wishlistRepository, amenityRepository, userRepository, a.Storage, a.Search)
+	a.Go(h.SyncSearch)
 
-	r.HandleFunc("/houses", h.FindHouses).Methods("GET")
-	r.HandleFunc("/house/{id}", h.GetHouse).Methods("GET")
-	r.HandleFunc("/house", (middleware.UploadFile(h.CreateHouse, "image"))).Methods("POST")
-	r.HandleFunc("/house/{id}", h.DeleteHouse).Methods("DELETE")
-	r.HandleFunc("/house/{id}", middleware.Auth(middleware.UploadFile(h.UpdateHouse, "image"))).Methods("PATCH")
+	r.HandleFunc("/houses", middleware.OptionalAuth(h.FindHouses, a.Config.SecretKey)).Methods("GET")
+	r.HandleFunc("/house/{id}", middleware.OptionalAuth(h.GetHouse, a.Config.SecretKey)).Methods("GET")
+	r.HandleFunc("/houses/import", middleware.Auth(h.ImportHouses, a.Config.SecretKey)).Methods("POST")
+	r.HandleFunc("/house", middleware.Auth(middleware.UploadFile(h.CreateHouse, "image", a.Storage), a.Config.SecretKey)).Methods("POST")
+	r.HandleFunc("/house/{id}", middleware.Auth(h.DeleteHouse, a.Config.SecretKey)).Methods("DELETE")
+	r.HandleFunc("/house/{id}", middleware.Auth(middleware.UploadFile(h.UpdateHouse, "image", a.Storage), a.Config.SecretKey)).Methods("PATCH")
 }
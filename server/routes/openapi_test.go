@@ -0,0 +1,33 @@
+package routes_test
+
+import (
+	"housy/openapi"
+	"housy/routes"
+	"net/http"
+	"testing"
+)
+
+func TestOpenAPISpecMatchesRoutes(t *testing.T) {
+	router := routes.Router(newTestApp(t))
+
+	drift, err := openapi.Check(router, openapi.Spec())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range drift {
+		t.Error(line)
+	}
+}
+
+func TestOpenAPICheckFindsUndocumentedRoutes(t *testing.T) {
+	router := routes.Router(newTestApp(t))
+	router.HandleFunc(openapi.BasePath+"/undocumented", func(w http.ResponseWriter, r *http.Request) {}).Methods("GET")
+
+	drift, err := openapi.Check(router, openapi.Spec())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drift) != 1 || drift[0] != "missing from the spec: GET /undocumented" {
+		t.Fatalf("drift = %q, want the undocumented route", drift)
+	}
+}
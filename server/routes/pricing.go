@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"housy/app"
+	"housy/handlers"
+	"housy/pkg/middleware"
+	"housy/repositories"
+
+	"github.com/gorilla/mux"
+)
+
+func PricingRoutes(r *mux.Router, a *app.App) {
+	pricingRepository := repositories.RepositoryPricing(a.DB)
+	houseRepository := repositories.RepositoryHouse(a.DB)
+	promoRepository := repositories.RepositoryPromo(a.DB)
+	h := handlers.HandlerPricing(pricingRepository, houseRepository, promoRepository, a.Config.FeesConfig())
+
+	r.HandleFunc("/house/{id}/pricing", h.GetPricing).Methods("GET")
+	r.HandleFunc("/house/{id}/pricing", middleware.Auth(h.UpdatePricing, a.Config.SecretKey)).Methods("PUT")
+	r.HandleFunc("/house/{id}/quote", middleware.OptionalAuth(h.Quote, a.Config.SecretKey)).Methods("GET")
+	r.HandleFunc("/house/{id}/calendar", h.Calendar).Methods("GET")
+}
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"housy/app"
+	"housy/handlers"
+	"housy/pkg/middleware"
+	"housy/repositories"
+
+	"github.com/gorilla/mux"
+)
+
+func PromoRoutes(r *mux.Router, a *app.App) {
+	promoRepository := repositories.RepositoryPromo(a.DB)
+	houseRepository := repositories.RepositoryHouse(a.DB)
+	userRepository := repositories.RepositoryUser(a.DB)
+	h := handlers.HandlerPromo(promoRepository, houseRepository, userRepository)
+
+	r.HandleFunc("/admin/promos", middleware.Auth(h.FindPromoCodes, a.Config.SecretKey)).Methods("GET")
+	r.HandleFunc("/admin/promo", middleware.Auth(h.CreatePromoCode, a.Config.SecretKey)).Methods("POST")
+	r.HandleFunc("/admin/promo/{id}", middleware.Auth(h.UpdatePromoCode, a.Config.SecretKey)).Methods("PATCH")
+	r.HandleFunc("/admin/promo/{id}", middleware.Auth(h.DeletePromoCode, a.Config.SecretKey)).Methods("DELETE")
+}
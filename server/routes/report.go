@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"housy/app"
+	"housy/handlers"
+	"housy/pkg/middleware"
+	"housy/repositories"
+
+	"github.com/gorilla/mux"
+)
+
+func ReportRoutes(r *mux.Router, a *app.App) {
+	reportRepository := repositories.RepositoryReport(a.DB)
+	userRepository := repositories.RepositoryUser(a.DB)
+	h := handlers.HandlerReport(reportRepository, userRepository)
+
+	r.HandleFunc("/reports/revenue", middleware.Auth(h.Revenue, a.Config.SecretKey)).Methods("GET")
+	r.HandleFunc("/reports/occupancy", middleware.Auth(h.Occupancy, a.Config.SecretKey)).Methods("GET")
+	r.HandleFunc("/reports/length-of-stay", middleware.Auth(h.LengthOfStay, a.Config.SecretKey)).Methods("GET")
+	r.HandleFunc("/reports/conversion", middleware.Auth(h.Conversion, a.Config.SecretKey)).Methods("GET")
+	r.HandleFunc("/reports/cancellations", middleware.Auth(h.Cancellations, a.Config.SecretKey)).Methods("GET")
+}
@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"housy/app"
+	"housy/handlers"
+	"housy/pkg/middleware"
+	"housy/repositories"
+
+	"github.com/gorilla/mux"
+)
+
+func ReviewRoutes(r *mux.Router, a *app.App) {
+	reviewRepository := repositories.RepositoryReview(a.DB)
+	transactionRepository := repositories.RepositoryTransaction(a.DB)
+	houseRepository := repositories.RepositoryHouse(a.DB)
+	userRepository := repositories.RepositoryUser(a.DB)
+	unitOfWork := repositories.NewUnitOfWork(a.DB)
+	h := handlers.HandlerReview(reviewRepository, transactionRepository, houseRepository, userRepository, unitOfWork)
+
+	r.HandleFunc("/house/{id}/reviews", h.FindHouseReviews).Methods("GET")
+	r.HandleFunc("/transaction/{id}/review", middleware.Auth(h.CreateReview, a.Config.SecretKey)).Methods("POST")
+	r.HandleFunc("/review/{id}/reply", middleware.Auth(h.ReplyReview, a.Config.SecretKey)).Methods("POST")
+
+	r.HandleFunc("/admin/reviews/hidden", middleware.Auth(h.FindHiddenReviews, a.Config.SecretKey)).Methods("GET")
+	r.HandleFunc("/admin/review/{id}/hide", middleware.Auth(h.HideReview, a.Config.SecretKey)).Methods("POST")
+	r.HandleFunc("/admin/review/{id}/unhide", middleware.Auth(h.UnhideReview, a.Config.SecretKey)).Methods("POST")
+}
@@ -1,12 +1,72 @@
 package routes
 
 import (
+	"housy/app"
+	"housy/handlers"
+	"housy/pkg/metrics"
+	"housy/pkg/middleware"
+	"log/slog"
+	"net/http"
+
+	gorillahandlers "github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
 )
 
-func RouteInit(r *mux.Router) {
-	UserRoutes(r)
-	AuthRoutes(r)
-	HouseRoutes(r)
-	TransactionRoutes(r)
-}
\ No newline at end of file
+func RouteInit(r *mux.Router, a *app.App) {
+	UserRoutes(r, a)
+	AuthRoutes(r, a)
+	HouseRoutes(r, a)
+	AmenityRoutes(r, a)
+	PricingRoutes(r, a)
+	PromoRoutes(r, a)
+	TransactionRoutes(r, a)
+	ReportRoutes(r, a)
+	ExportRoutes(r, a)
+	ReviewRoutes(r, a)
+	WishlistRoutes(r, a)
+	AdminRoutes(r, a)
+	DocsRoutes(r, a)
+}
+
+// Router registers every route of the app, Handler adds the middlewares
+// wrapping it.
+func Router(a *app.App) *mux.Router {
+	r := mux.NewRouter()
+	r.Use(otelmux.Middleware(a.Config.Tracing.ServiceName), metrics.Instrument)
+
+	r.NotFoundHandler = http.HandlerFunc(handlers.NotFound)
+	r.MethodNotAllowedHandler = http.HandlerFunc(handlers.MethodNotAllowed)
+
+	HealthRoutes(r, a)
+	api := r.PathPrefix("/api/v1").Subrouter()
+	api.NotFoundHandler = r.NotFoundHandler
+	api.MethodNotAllowedHandler = r.MethodNotAllowedHandler
+	RouteInit(api, a)
+	r.PathPrefix("/uploads").Handler(http.StripPrefix("/uploads/", http.FileServer(http.Dir(a.Config.Storage.Dir))))
+
+	return r
+}
+
+// Handler builds the whole HTTP API of the app, ready for http.ListenAndServe
+// or httptest.NewServer.
+func Handler(a *app.App) http.Handler {
+	r := Router(a)
+
+	// Setup allowed Header, Method, and Origin for CORS on this below code ...
+	var AllowedHeaders = gorillahandlers.AllowedHeaders([]string{"X-Requested-With", "Content-Type", "Authorization", middleware.RequestIDHeader})
+	var AllowedMethods = gorillahandlers.AllowedMethods([]string{"GET", "POST", "PUT", "HEAD", "OPTIONS", "PATCH", "DELETE"})
+	var AllowedOrigins = gorillahandlers.AllowedOrigins(a.Config.CORSOrigins)
+
+	var ExposedHeaders = gorillahandlers.ExposedHeaders([]string{middleware.RequestIDHeader, handlers.TotalCountHeader})
+
+	logger := a.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	var handler http.Handler = gorillahandlers.CORS(AllowedHeaders, AllowedMethods, AllowedOrigins, ExposedHeaders)(r)
+	handler = middleware.AccessLog(logger)(handler)
+
+	return middleware.RequestID(handler)
+}
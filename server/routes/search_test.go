@@ -0,0 +1,79 @@
+package routes_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"housy/client"
+	housesdto "housy/dto/house"
+	"housy/models"
+	"housy/routes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSearchFollowsHouseDeleteAndRestore(t *testing.T) {
+	a := newTestApp(t)
+	server := httptest.NewServer(routes.Handler(a))
+	defer server.Close()
+	ctx := context.Background()
+
+	owner := signUp(t, server.URL, "owner", "owner")
+	signUp(t, server.URL, "admin", "tenant")
+	if err := a.DB.Model(&models.User{}).Where("username = ?", "admin").Update("list_as_role", "admin").Error; err != nil {
+		t.Fatal(err)
+	}
+	admin := client.New(server.URL, client.WithCredentials("admin", "password", "admin"))
+
+	house, err := owner.CreateHouse(ctx, housesdto.HouseRequest{
+		Name:      "Villa Lembang",
+		CityName:  "Bandung",
+		Address:   "Jl. Raya Lembang",
+		Price:     100,
+		TypeRent:  "day",
+		Amenities: []string{"wifi"},
+		Bedroom:   1,
+		Bathroom:  1,
+	}, client.Image{Name: "villa.png", Content: bytes.NewReader([]byte("\x89PNG\r\n\x1a\n"))})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	search := func() []models.House {
+		t.Helper()
+		houses, err := owner.FindHouses(ctx, client.HouseFilter{Query: "lembang"}).All()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return houses
+	}
+
+	if houses := search(); len(houses) != 1 || houses[0].ID != house.ID {
+		t.Fatalf("search found %v, want the new house", houses)
+	}
+
+	if _, err := owner.DeleteHouse(ctx, house.ID); err != nil {
+		t.Fatal(err)
+	}
+	if houses := search(); len(houses) != 0 {
+		t.Fatalf("search found %v, want the deleted house gone", houses)
+	}
+
+	if _, err := admin.CheckAuth(ctx); err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/api/v1/admin/house/%d/restore", server.URL, house.ID), nil)
+	req.Header.Set("Authorization", "Bearer "+admin.Token())
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("restoring the house answered %d", resp.StatusCode)
+	}
+	if houses := search(); len(houses) != 1 || houses[0].ID != house.ID {
+		t.Fatalf("search found %v, want the restored house", houses)
+	}
+}
@@ -1,21 +1,33 @@
 package routes
 
 import (
+	"housy/app"
 	"housy/handlers"
-	"housy/pkg/mysql"
+	"housy/pkg/metrics"
+	"housy/pkg/middleware"
 	"housy/repositories"
 
 	"github.com/gorilla/mux"
 )
 
-func TransactionRoutes(r *mux.Router) {
-	transactionRepository := repositories.RepositoryTransaction(mysql.DB)
-	h := handlers.HandlerTransaction(transactionRepository)
+func TransactionRoutes(r *mux.Router, a *app.App) {
+	transactionRepository := repositories.RepositoryTransaction(a.DB)
+	invoiceRepository := repositories.RepositoryInvoice(a.DB)
+	userRepository := repositories.RepositoryUser(a.DB)
+	houseRepository := repositories.RepositoryHouse(a.DB)
+	pricingRepository := repositories.RepositoryPricing(a.DB)
+	promoRepository := repositories.RepositoryPromo(a.DB)
+	outboxRepository := repositories.RepositoryOutbox(a.DB)
+	unitOfWork := repositories.NewUnitOfWork(a.DB)
+	h := handlers.HandlerTransaction(transactionRepository, invoiceRepository, userRepository, houseRepository, pricingRepository, promoRepository, outboxRepository, unitOfWork, a.Mailer, a.Payment, a.Config.Payment.ServerKey, a.Storage, a.Config.FeesConfig())
+	a.Go(h.SendMails)
+	metrics.RegisterQueue("transaction_mails", h.OutboxDepth)
 
-	r.HandleFunc("/transactions", h.FindTransaction).Methods("GET")
-	r.HandleFunc("/transaction/{id}", h.GetTransaction).Methods("GET")
-	r.HandleFunc("/transaction", h.CreateTransaction).Methods("POST")
-	r.HandleFunc("/transaction/{id}", h.DeleteTransaction).Methods("DELETE")
+	r.HandleFunc("/transactions", middleware.Auth(h.FindTransaction, a.Config.SecretKey)).Methods("GET")
+	r.HandleFunc("/transaction/{id}", middleware.Auth(h.GetTransaction, a.Config.SecretKey)).Methods("GET")
+	r.HandleFunc("/transaction", middleware.Auth(h.CreateTransaction, a.Config.SecretKey)).Methods("POST")
+	r.HandleFunc("/transaction/{id}", middleware.Auth(h.DeleteTransaction, a.Config.SecretKey)).Methods("DELETE")
+	r.HandleFunc("/transaction/{id}/invoice.pdf", middleware.Auth(h.GetInvoice, a.Config.SecretKey)).Methods("GET")
 
 	r.HandleFunc("/notification", h.Notification).Methods("POST")
 }
@@ -1,19 +1,20 @@
 package routes
 
 import (
+	"housy/app"
 	"housy/handlers"
-	"housy/pkg/mysql"
+	"housy/pkg/middleware"
 	"housy/repositories"
 
 	"github.com/gorilla/mux"
 )
 
-func UserRoutes(r *mux.Router) {
-	userRepository := repositories.RepositoryUser(mysql.DB)
+func UserRoutes(r *mux.Router, a *app.App) {
+	userRepository := repositories.RepositoryUser(a.DB)
 	h := handlers.HandlerUser(userRepository)
 
 	r.HandleFunc("/users", h.FindUsers).Methods("GET")
 	r.HandleFunc("/user/{id}", h.GetUser).Methods("GET")
-	r.HandleFunc("/user/{id}", h.UpdateUser).Methods("PATCH")
-	r.HandleFunc("/user/{id}", h.DeleteUser).Methods("DELETE")
+	r.HandleFunc("/user/{id}", middleware.Auth(h.UpdateUser, a.Config.SecretKey)).Methods("PATCH")
+	r.HandleFunc("/user/{id}", middleware.Auth(h.DeleteUser, a.Config.SecretKey)).Methods("DELETE")
 }
@@ -0,0 +1,81 @@
+package routes_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"housy/client"
+	authdto "housy/dto/auth"
+	usersdto "housy/dto/users"
+	"housy/routes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUsersCannotMakeThemselvesAdmins(t *testing.T) {
+	server := httptest.NewServer(routes.Handler(newTestApp(t)))
+	defer server.Close()
+	ctx := context.Background()
+
+	_, err := client.New(server.URL).SignUp(ctx, authdto.SignUpRequest{
+		Fullname:   "mallory",
+		Username:   "mallory",
+		Email:      "mallory@example.com",
+		Password:   "password",
+		ListAsRole: "admin",
+		Phone:      "0812",
+		Address:    "Jakarta",
+	})
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("signing up as an admin: got %v, want a 422", err)
+	}
+
+	tenant := signUp(t, server.URL, "tenant", "tenant")
+	other := signUp(t, server.URL, "other", "tenant")
+	me, err := tenant.CheckAuth(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	them, err := other.CheckAuth(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the role in the body is ignored
+	body := strings.NewReader(`{"fullname":"Tenant","listAsRole":"admin"}`)
+	req, _ := http.NewRequest(http.MethodPatch, fmt.Sprintf("%s/api/v1/user/%d", server.URL, me.ID), body)
+	req.Header.Set("Authorization", "Bearer "+tenant.Token())
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("updating yourself answered %d", resp.StatusCode)
+	}
+	user, err := tenant.GetUser(ctx, me.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.ListAsRole != "tenant" || user.Fullname != "Tenant" {
+		t.Fatalf("after the update the user is %q with the role %q, want Tenant and tenant", user.Fullname, user.ListAsRole)
+	}
+
+	// the password is kept when the update leaves it out
+	if _, err := client.New(server.URL, client.WithCredentials("tenant", "password", "tenant")).CheckAuth(ctx); err != nil {
+		t.Fatalf("signing in after the update: %v", err)
+	}
+
+	if _, err := tenant.UpdateUser(ctx, them.ID, usersdto.RequestUser{Fullname: "Hacked"}); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Fatalf("updating another user: got %v, want a 403", err)
+	}
+	if _, err := tenant.DeleteUser(ctx, them.ID); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Fatalf("deleting another user: got %v, want a 403", err)
+	}
+	if _, err := client.New(server.URL).DeleteUser(ctx, them.ID); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("deleting a user anonymously: got %v, want a 401", err)
+	}
+}
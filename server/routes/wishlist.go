@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"housy/app"
+	"housy/handlers"
+	"housy/pkg/middleware"
+	"housy/repositories"
+
+	"github.com/gorilla/mux"
+)
+
+func WishlistRoutes(r *mux.Router, a *app.App) {
+	wishlistRepository := repositories.RepositoryWishlist(a.DB)
+	houseRepository := repositories.RepositoryHouse(a.DB)
+	h := handlers.HandlerWishlist(wishlistRepository, houseRepository, a.Storage)
+
+	r.HandleFunc("/me/wishlists", middleware.Auth(h.FindMyWishlists, a.Config.SecretKey)).Methods("GET")
+	r.HandleFunc("/wishlist", middleware.Auth(h.CreateWishlist, a.Config.SecretKey)).Methods("POST")
+	r.HandleFunc("/wishlist/{id}", middleware.Auth(h.UpdateWishlist, a.Config.SecretKey)).Methods("PATCH")
+	r.HandleFunc("/wishlist/{id}", middleware.Auth(h.DeleteWishlist, a.Config.SecretKey)).Methods("DELETE")
+	r.HandleFunc("/wishlist/{id}/house/{houseId}", middleware.Auth(h.AddHouse, a.Config.SecretKey)).Methods("PUT")
+	r.HandleFunc("/wishlist/{id}/house/{houseId}", middleware.Auth(h.RemoveHouse, a.Config.SecretKey)).Methods("DELETE")
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"housy/app"
+	"housy/pkg/tlscert"
+	"housy/routes"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// certCheckInterval is how often the TLS files are checked for renewal.
+const certCheckInterval = time.Minute
+
+// serve runs the HTTP API until SIGINT or SIGTERM, then stops accepting
+// connections, lets in-flight requests and background workers finish
+// within the shutdown timeout and closes the database. SIGHUP reloads the
+// TLS certificate.
+func serve(a *app.App) error {
+	cfg := a.Config
+
+	server := &http.Server{
+		Addr:              cfg.Address(),
+		Handler:           routes.Handler(a),
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	var certs *tlscert.Reloader
+	if cfg.TLS() {
+		var err error
+		certs, err = tlscert.NewReloader(cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile)
+		if err != nil {
+			return err
+		}
+		server.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: certs.GetCertificate,
+		}
+		a.Go(func(ctx context.Context) {
+			certs.Watch(ctx, certCheckInterval)
+		})
+		signal.Notify(signals, syscall.SIGHUP)
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		if certs != nil {
+			a.Logger.Info("server running", "address", "https://"+cfg.Address())
+			errs <- server.ListenAndServeTLS("", "")
+		} else {
+			a.Logger.Info("server running", "address", "http://"+cfg.Address())
+			errs <- server.ListenAndServe()
+		}
+	}()
+
+	for {
+		select {
+		case err := <-errs:
+			return err
+		case sig := <-signals:
+			if sig == syscall.SIGHUP {
+				if err := certs.Reload(); err != nil {
+					a.Logger.Error("tls certificate reload failed", "error", err)
+				} else {
+					a.Logger.Info("tls certificate reloaded")
+				}
+				continue
+			}
+
+			a.Logger.Info("shutting down", "signal", sig.String())
+			return shutdown(a, server)
+		}
+	}
+}
+
+func shutdown(a *app.App, server *http.Server) error {
+	ctx, cancel := context.WithTimeout(context.Background(), a.Config.Server.ShutdownTimeout)
+	defer cancel()
+
+	err := server.Shutdown(ctx)
+	if stopErr := a.StopWorkers(ctx); stopErr != nil && err == nil {
+		err = fmt.Errorf("stopping background workers: %w", stopErr)
+	}
+	if closeErr := a.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	a.Logger.Info("server stopped")
+	return nil
+}